@@ -0,0 +1,237 @@
+// Package agent menjalankan loop function calling multi-langkah: kirim riwayat
+// ke model, jalankan semua function call yang diminta lewat tools.Registry,
+// kirim hasilnya balik, dan ulangi sampai model menjawab dengan teks tanpa
+// function call.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	genai "google.golang.org/genai"
+	"proxy/tools"
+)
+
+// Nilai default yang dipakai ketika field Config bernilai nol.
+const (
+	DefaultMaxSteps    = 5
+	DefaultCallTimeout = 30 * time.Second
+	DefaultTimeout     = 2 * time.Minute
+)
+
+// Generator adalah bagian dari genai.Models yang dipakai agent, sehingga
+// client.Models bisa diganti dengan implementasi lain.
+type Generator interface {
+	GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error)
+}
+
+// Config mengatur batas-batas loop agent.
+type Config struct {
+	// Model yang dipanggil, misal "gemini-2.0-flash-001".
+	Model string
+	// GenerateConfig dipakai untuk setiap panggilan model. Field Tools diisi
+	// otomatis dari registry jika kosong.
+	GenerateConfig *genai.GenerateContentConfig
+	// MaxSteps adalah jumlah maksimal panggilan model dalam satu Run.
+	MaxSteps int
+	// CallTimeout membatasi satu panggilan model maupun satu giliran eksekusi tool.
+	CallTimeout time.Duration
+	// Timeout adalah batas waktu keseluruhan satu Run.
+	Timeout time.Duration
+	// TokenBudget membatasi total token (TotalTokenCount) yang boleh dipakai;
+	// 0 berarti tidak dibatasi.
+	TokenBudget int32
+	// Workers adalah jumlah tool yang boleh berjalan bersamaan.
+	Workers int
+}
+
+// StopReason menjelaskan kenapa loop berhenti.
+type StopReason string
+
+const (
+	// StopFinal: model menjawab dengan teks tanpa function call.
+	StopFinal StopReason = "final"
+	// StopMaxSteps: batas MaxSteps tercapai saat model masih meminta function call.
+	StopMaxSteps StopReason = "max_steps"
+	// StopTokenBudget: total token sudah melewati TokenBudget.
+	StopTokenBudget StopReason = "token_budget"
+	// StopNoContent: model tidak mengembalikan kandidat (misal diblokir safety filter).
+	StopNoContent StopReason = "no_content"
+	// StopDeadline: Timeout atau CallTimeout terlewati.
+	StopDeadline StopReason = "deadline"
+)
+
+// CallRecord mencatat satu function call beserta hasilnya.
+type CallRecord struct {
+	Step     int
+	Call     *genai.FunctionCall
+	Response *genai.FunctionResponse
+}
+
+// Usage adalah total pemakaian token dari semua panggilan model.
+type Usage struct {
+	PromptTokens     int32
+	CandidatesTokens int32
+	TotalTokens      int32
+}
+
+func (u *Usage) add(m *genai.GenerateContentResponseUsageMetadata) {
+	if m == nil {
+		return
+	}
+	u.PromptTokens += m.PromptTokenCount
+	u.CandidatesTokens += m.CandidatesTokenCount
+	u.TotalTokens += m.TotalTokenCount
+}
+
+// Result adalah hasil terstruktur dari Run.
+type Result struct {
+	// Text adalah jawaban akhir model (kosong jika loop berhenti sebelum model menjawab).
+	Text string
+	// Calls berisi semua function call yang dijalankan, berurutan.
+	Calls []CallRecord
+	// Usage adalah total pemakaian token.
+	Usage Usage
+	// Steps adalah jumlah panggilan model yang dilakukan.
+	Steps int
+	// StopReason menjelaskan kenapa loop berhenti.
+	StopReason StopReason
+	// FinishReason dari kandidat terakhir, jika ada.
+	FinishReason genai.FinishReason
+	// Contents adalah riwayat percakapan lengkap termasuk giliran function call.
+	Contents []*genai.Content
+}
+
+// Agent menjalankan loop function calling dengan tool dari sebuah registry.
+type Agent struct {
+	gen      Generator
+	registry *tools.Registry
+	cfg      Config
+}
+
+// New membuat Agent. Field Config yang bernilai nol diisi dengan nilai default.
+func New(gen Generator, registry *tools.Registry, cfg Config) *Agent {
+	if cfg.MaxSteps <= 0 {
+		cfg.MaxSteps = DefaultMaxSteps
+	}
+	if cfg.CallTimeout <= 0 {
+		cfg.CallTimeout = DefaultCallTimeout
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = tools.DefaultWorkers
+	}
+
+	genCfg := &genai.GenerateContentConfig{}
+	if cfg.GenerateConfig != nil {
+		copied := *cfg.GenerateConfig
+		genCfg = &copied
+	}
+	if len(genCfg.Tools) == 0 {
+		genCfg.Tools = registry.Tools()
+	}
+	cfg.GenerateConfig = genCfg
+
+	return &Agent{gen: gen, registry: registry, cfg: cfg}
+}
+
+// Run menjalankan loop untuk riwayat contents (biasanya satu giliran user).
+// Loop berhenti secara alami ketika model mengembalikan teks tanpa function
+// call, atau ketika salah satu batas di Config tercapai. Result selalu
+// dikembalikan, termasuk ketika error terjadi, supaya pemanggil tetap bisa
+// melihat call dan pemakaian token sejauh itu.
+func (a *Agent) Run(ctx context.Context, contents []*genai.Content) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.Timeout)
+	defer cancel()
+
+	res := &Result{Contents: append([]*genai.Content(nil), contents...)}
+
+	for res.Steps < a.cfg.MaxSteps {
+		res.Steps++
+		log.Printf("Agent langkah #%d", res.Steps)
+
+		resp, err := a.generate(ctx, res.Contents)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				res.StopReason = StopDeadline
+			}
+			return res, fmt.Errorf("failed to generate content (langkah #%d): %w", res.Steps, err)
+		}
+		res.Usage.add(resp.UsageMetadata)
+
+		if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+			log.Println("Model did not return any content or function call.")
+			if len(resp.Candidates) > 0 {
+				res.FinishReason = resp.Candidates[0].FinishReason
+				log.Printf("Finish Reason: %s", res.FinishReason)
+			}
+			res.StopReason = StopNoContent
+			return res, nil
+		}
+
+		candidate := resp.Candidates[0]
+		res.FinishReason = candidate.FinishReason
+		res.Contents = append(res.Contents, candidate.Content)
+
+		var calls []*genai.FunctionCall
+		var text strings.Builder
+		for _, p := range candidate.Content.Parts {
+			if p.FunctionCall != nil {
+				calls = append(calls, p.FunctionCall)
+				log.Printf("%q with args: %v", p.FunctionCall.Name, p.FunctionCall.Args)
+			} else if p.Text != "" {
+				text.WriteString(p.Text)
+			}
+		}
+
+		if len(calls) == 0 {
+			res.Text = text.String()
+			res.StopReason = StopFinal
+			return res, nil
+		}
+
+		responses := a.callTools(ctx, calls)
+		parts := make([]*genai.Part, 0, len(responses))
+		for i, r := range responses {
+			res.Calls = append(res.Calls, CallRecord{Step: res.Steps, Call: calls[i], Response: r})
+			parts = append(parts, &genai.Part{FunctionResponse: r})
+		}
+		res.Contents = append(res.Contents, &genai.Content{
+			Role:  "function", // Role must be "function" for FunctionResponse
+			Parts: parts,
+		})
+
+		if err := ctx.Err(); err != nil {
+			res.StopReason = StopDeadline
+			return res, fmt.Errorf("agent berhenti setelah langkah #%d: %w", res.Steps, err)
+		}
+		if a.cfg.TokenBudget > 0 && res.Usage.TotalTokens >= a.cfg.TokenBudget {
+			log.Printf("Token budget habis: %d/%d", res.Usage.TotalTokens, a.cfg.TokenBudget)
+			res.StopReason = StopTokenBudget
+			return res, nil
+		}
+	}
+
+	res.StopReason = StopMaxSteps
+	return res, nil
+}
+
+func (a *Agent) generate(ctx context.Context, contents []*genai.Content) (*genai.GenerateContentResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.CallTimeout)
+	defer cancel()
+	return a.gen.GenerateContent(ctx, a.cfg.Model, contents, a.cfg.GenerateConfig)
+}
+
+// callTools menjalankan semua call dengan batas CallTimeout. Handler yang
+// melewati batas menghasilkan error response, bukan menggagalkan Run.
+func (a *Agent) callTools(ctx context.Context, calls []*genai.FunctionCall) []*genai.FunctionResponse {
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.CallTimeout)
+	defer cancel()
+	return a.registry.CallAll(ctx, calls, a.cfg.Workers)
+}
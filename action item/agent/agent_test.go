@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	genai "google.golang.org/genai"
+	"proxy/tools"
+)
+
+// stubGenerator mengembalikan respons berurutan untuk setiap panggilan. Respons
+// nil menunggu sampai ctx selesai, meniru model yang tidak menjawab.
+type stubGenerator struct {
+	mu        sync.Mutex
+	responses []*genai.GenerateContentResponse
+	calls     int
+}
+
+func (g *stubGenerator) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	g.mu.Lock()
+	var resp *genai.GenerateContentResponse
+	if g.calls < len(g.responses) {
+		resp = g.responses[g.calls]
+	}
+	g.calls++
+	g.mu.Unlock()
+	if resp == nil {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return resp, nil
+}
+
+// usage adalah pemakaian token setiap respons stub.
+var usage = &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5, TotalTokenCount: 15}
+
+func callResponse(name string) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Role: "model", Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{Name: name}}}},
+			FinishReason: genai.FinishReasonStop,
+		}},
+		UsageMetadata: usage,
+	}
+}
+
+func textResponse(text string) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Role: "model", Parts: []*genai.Part{{Text: text}}},
+			FinishReason: genai.FinishReasonStop,
+		}},
+		UsageMetadata: usage,
+	}
+}
+
+type pingArgs struct{}
+
+func TestRun(t *testing.T) {
+	blocked := &genai.GenerateContentResponse{
+		Candidates:    []*genai.Candidate{{FinishReason: genai.FinishReasonSafety}},
+		UsageMetadata: usage,
+	}
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		responses []*genai.GenerateContentResponse
+		want      StopReason
+		wantErr   error
+		text      string
+		steps     int
+		// calls adalah jumlah function call yang dijalankan.
+		calls int
+	}{
+		{
+			name:      "final",
+			responses: []*genai.GenerateContentResponse{callResponse("ping"), callResponse("ping"), textResponse("selesai")},
+			want:      StopFinal,
+			text:      "selesai",
+			steps:     3,
+			calls:     2,
+		},
+		{
+			name:      "max_steps",
+			cfg:       Config{MaxSteps: 2},
+			responses: []*genai.GenerateContentResponse{callResponse("ping"), callResponse("ping"), textResponse("tidak sampai")},
+			want:      StopMaxSteps,
+			steps:     2,
+			calls:     2,
+		},
+		{
+			name:      "token_budget",
+			cfg:       Config{TokenBudget: 30},
+			responses: []*genai.GenerateContentResponse{callResponse("ping"), callResponse("ping"), textResponse("tidak sampai")},
+			want:      StopTokenBudget,
+			steps:     2,
+			calls:     2,
+		},
+		{
+			name:      "no_content",
+			responses: []*genai.GenerateContentResponse{callResponse("ping"), blocked},
+			want:      StopNoContent,
+			steps:     2,
+			calls:     1,
+		},
+		{
+			name:      "deadline",
+			cfg:       Config{Timeout: 50 * time.Millisecond},
+			responses: []*genai.GenerateContentResponse{callResponse("ping"), nil},
+			want:      StopDeadline,
+			wantErr:   context.DeadlineExceeded,
+			steps:     2,
+			calls:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := tools.NewRegistry()
+			pings := 0
+			tools.Register(registry, &genai.FunctionDeclaration{Name: "ping"},
+				func(context.Context, pingArgs) (map[string]any, error) {
+					pings++
+					return map[string]any{"pong": pings}, nil
+				})
+			gen := &stubGenerator{responses: tt.responses}
+
+			question := []*genai.Content{genai.NewContentFromText("halo", genai.RoleUser)}
+			res, err := New(gen, registry, tt.cfg).Run(context.Background(), question)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Run error = %v, ingin %v", err, tt.wantErr)
+			}
+			if res == nil {
+				t.Fatal("Result nil")
+			}
+			if res.StopReason != tt.want || res.Text != tt.text || res.Steps != tt.steps {
+				t.Errorf("Result = %s %q setelah %d langkah, ingin %s %q setelah %d langkah",
+					res.StopReason, res.Text, res.Steps, tt.want, tt.text, tt.steps)
+			}
+
+			if len(res.Calls) != tt.calls || pings != tt.calls {
+				t.Fatalf("Calls = %d, handler dipanggil %d kali, ingin %d", len(res.Calls), pings, tt.calls)
+			}
+			for i, c := range res.Calls {
+				if c.Step != i+1 || c.Call.Name != "ping" || c.Response.Name != "ping" || c.Response.Response["pong"] != i+1 {
+					t.Errorf("Calls[%d] = langkah %d %s -> %v", i, c.Step, c.Call.Name, c.Response.Response)
+				}
+			}
+
+			// Langkah yang gagal karena deadline tidak punya UsageMetadata
+			answered := int32(min(tt.steps, len(tt.responses)))
+			if tt.wantErr != nil {
+				answered--
+			}
+			want := Usage{PromptTokens: 10 * answered, CandidatesTokens: 5 * answered, TotalTokens: 15 * answered}
+			if res.Usage != want {
+				t.Errorf("Usage = %+v, ingin %+v", res.Usage, want)
+			}
+
+			// Riwayat: pertanyaan, lalu satu giliran model dan satu giliran
+			// function untuk setiap langkah dengan function call
+			wantContents := 1 + 2*tt.calls
+			if tt.want == StopFinal {
+				wantContents++
+			}
+			if len(res.Contents) != wantContents {
+				t.Errorf("Contents berisi %d giliran, ingin %d", len(res.Contents), wantContents)
+			}
+		})
+	}
+}
+
+func TestRunToolsFromRegistry(t *testing.T) {
+	registry := tools.NewRegistry()
+	tools.Register(registry, &genai.FunctionDeclaration{Name: "ping"},
+		func(context.Context, pingArgs) (map[string]any, error) { return nil, nil })
+
+	var got *genai.GenerateContentConfig
+	gen := generatorFunc(func(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+		got = config
+		return textResponse("ok"), nil
+	})
+	cfg := Config{Model: "model-uji", GenerateConfig: &genai.GenerateContentConfig{Temperature: genai.Ptr(float32(0))}}
+	if _, err := New(gen, registry, cfg).Run(context.Background(), nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got == cfg.GenerateConfig {
+		t.Error("GenerateConfig milik pemanggil ikut diubah")
+	}
+	if len(got.Tools) != 1 || got.Tools[0].FunctionDeclarations[0].Name != "ping" || *got.Temperature != 0 {
+		t.Errorf("GenerateConfig = %+v, ingin Tools dari registry", got)
+	}
+}
+
+// generatorFunc mengubah fungsi menjadi Generator.
+type generatorFunc func(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error)
+
+func (f generatorFunc) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	return f(ctx, model, contents, config)
+}
@@ -0,0 +1,195 @@
+// Package answer mendefinisikan format jawaban akhir Travel Buddy (response,
+// transcript, locations, weather) sebagai tipe Go. Schema yang sama dipakai
+// untuk ResponseSchema di jalur REST (lihat Structured) dan untuk
+// memvalidasi teks dari Live API. Jawaban yang tidak valid diperbaiki sekali
+// oleh model sebelum jatuh ke teks biasa.
+package answer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"proxy/agent"
+	"proxy/schema"
+	"proxy/tools"
+)
+
+// Location adalah satu rekomendasi tempat dalam jawaban.
+type Location struct {
+	Name            string `json:"name" required:"true" description:"Location name."`
+	Rating          string `json:"rating" description:"Location rating on Google Maps."`
+	TotalUserRating string `json:"total_user_rating" description:"Number of reviews on Google Maps."`
+	Address         string `json:"address" description:"Location address on Google Maps."`
+	Distance        string `json:"distance" description:"Distance from user (km)."`
+	Review          string `json:"review" description:"One review from Google Maps, quoted from getPlaceDetails."`
+	PPReviewer      string `json:"pp_reviewer" description:"Profile picture URL of the reviewer (author_photo_uri from getPlaceDetails), if available."`
+	GmapLink        string `json:"gmap_link" description:"Google Maps link to the location."`
+}
+
+// Weather adalah ringkasan cuaca dalam jawaban.
+type Weather struct {
+	Current     string `json:"current" description:"Current weather conditions."`
+	Temperature string `json:"temperature" description:"Temperature in Celsius."`
+	Humidity    string `json:"humidity" description:"Humidity percentage."`
+	Wind        string `json:"wind" description:"Wind speed and direction."`
+	Description string `json:"description" description:"Weather description."`
+}
+
+// Envelope adalah jawaban akhir model.
+type Envelope struct {
+	Response   string     `json:"response" required:"true" description:"Your response text."`
+	Transcript string     `json:"transcript,omitempty" description:"Audio transcription from the video (if available)."`
+	Locations  []Location `json:"locations,omitempty" description:"Suggested locations, if any."`
+	Weather    *Weather   `json:"weather,omitempty" description:"Weather information, if the user asked about it."`
+}
+
+// Outcome menjelaskan bagaimana Envelope didapat.
+type Outcome string
+
+const (
+	// OutcomeStructured berarti jawaban dihasilkan dengan ResponseSchema
+	// Envelope oleh Structured.
+	OutcomeStructured Outcome = "structured"
+	// OutcomeValid berarti jawaban pertama sudah sesuai schema.
+	OutcomeValid Outcome = "valid"
+	// OutcomeRepaired berarti jawaban sesuai schema setelah satu perbaikan.
+	OutcomeRepaired Outcome = "repaired"
+	// OutcomeFallback berarti jawaban tetap tidak valid dan dikirim sebagai
+	// teks biasa di Response.
+	OutcomeFallback Outcome = "fallback"
+)
+
+var envelopeSchema = func() *genai.Schema {
+	s, err := schema.Of(reflect.TypeFor[Envelope]())
+	if err != nil {
+		panic(fmt.Sprintf("answer: %v", err))
+	}
+	return s
+}()
+
+// Schema mengembalikan genai.Schema untuk Envelope.
+func Schema() *genai.Schema {
+	return envelopeSchema
+}
+
+// Parse mem-parse teks jawaban model menjadi Envelope. Blok kode markdown
+// (```json ... ```) diabaikan. Field yang tidak sesuai schema dilaporkan
+// sebagai *tools.ValidationError.
+func Parse(text string) (*Envelope, error) {
+	raw := map[string]any{}
+	if err := json.Unmarshal([]byte(stripCodeFence(text)), &raw); err != nil {
+		return nil, fmt.Errorf("jawaban bukan JSON object: %w", err)
+	}
+	args, fieldErrs := tools.Validate(envelopeSchema, raw)
+	if len(fieldErrs) > 0 {
+		return nil, &tools.ValidationError{Function: "answer", Fields: fieldErrs}
+	}
+	env, err := tools.Decode[Envelope](args)
+	if err != nil {
+		return nil, err
+	}
+	return &env, nil
+}
+
+// Fallback membungkus teks yang tidak bisa di-parse sebagai Response.
+func Fallback(text string) *Envelope {
+	return &Envelope{Response: strings.TrimSpace(stripCodeFence(text))}
+}
+
+// RepairPrompt adalah pesan yang meminta model memperbaiki jawaban yang gagal
+// divalidasi dengan cause.
+func RepairPrompt(cause error) string {
+	return fmt.Sprintf(
+		"Your previous answer is not valid for the required JSON format (%v). Reply again with only a JSON object with the fields response (required), transcript, locations and weather, keeping the same content.",
+		cause,
+	)
+}
+
+// Structured meminta jawaban akhir tanpa tools dengan ResponseMIMEType
+// application/json dan ResponseSchema Envelope. Gemini 2.0 menolak function
+// calling bersama output JSON terkontrol, jadi loop agent berjalan dengan
+// tools dan tanpa schema, lalu panggilan ini menghasilkan jawaban akhirnya di
+// bawah schema. contents dan config seperti pada Resolve; giliran model
+// terakhir (jawaban text) dibuang sehingga model menjawab ulang dari hasil
+// tool. Jika panggilan gagal atau hasilnya tetap tidak valid, errornya
+// dicatat ke log dan text diproses dengan Resolve.
+func Structured(ctx context.Context, gen agent.Generator, model string, config *genai.GenerateContentConfig, contents []*genai.Content, text string) (*Envelope, Outcome) {
+	history := contents
+	if n := len(history); n > 0 && history[n-1].Role == genai.RoleModel {
+		history = history[:n-1]
+	}
+	resp, err := gen.GenerateContent(ctx, model, history, schemaConfig(config))
+	if err != nil {
+		log.Printf("Jawaban terstruktur gagal diminta: %v", err)
+		return Resolve(ctx, gen, model, config, contents, text)
+	}
+	env, err := Parse(resp.Text())
+	if err != nil {
+		log.Printf("Jawaban terstruktur tidak valid: %v", err)
+		return Resolve(ctx, gen, model, config, contents, text)
+	}
+	return env, OutcomeStructured
+}
+
+// Resolve mengubah text (jawaban akhir loop agent) menjadi Envelope. Jika
+// tidak valid, model diminta memperbaiki sekali lewat gen dengan
+// ResponseMIMEType application/json dan ResponseSchema; jika masih gagal, text
+// dikembalikan sebagai teks biasa. contents adalah riwayat percakapan sampai
+// jawaban text dan config adalah config yang menghasilkannya, sehingga
+// perbaikan tetap memakai SystemInstruction yang sama. Error permintaan
+// perbaikan dan error validasi jawaban perbaikan dicatat ke log.
+func Resolve(ctx context.Context, gen agent.Generator, model string, config *genai.GenerateContentConfig, contents []*genai.Content, text string) (*Envelope, Outcome) {
+	env, err := Parse(text)
+	if err == nil {
+		return env, OutcomeValid
+	}
+
+	repairContents := append(append([]*genai.Content(nil), contents...),
+		genai.NewContentFromText(RepairPrompt(err), genai.RoleUser))
+	resp, err := gen.GenerateContent(ctx, model, repairContents, schemaConfig(config))
+	if err != nil {
+		log.Printf("Perbaikan jawaban gagal dikirim: %v", err)
+		return Fallback(text), OutcomeFallback
+	}
+	env, err = Parse(resp.Text())
+	if err != nil {
+		log.Printf("Jawaban perbaikan tetap tidak valid: %v", err)
+		return Fallback(text), OutcomeFallback
+	}
+	return env, OutcomeRepaired
+}
+
+// schemaConfig menyalin config untuk Structured dan permintaan perbaikan:
+// tools dibuang karena Gemini 2.0 menolak function calling bersama
+// ResponseMIMEType application/json, lalu ResponseSchema Envelope dipasang.
+func schemaConfig(config *genai.GenerateContentConfig) *genai.GenerateContentConfig {
+	repair := &genai.GenerateContentConfig{}
+	if config != nil {
+		*repair = *config
+	}
+	repair.Tools, repair.ToolConfig = nil, nil
+	repair.ResponseMIMEType = "application/json"
+	repair.ResponseSchema = envelopeSchema
+	repair.Temperature = genai.Ptr(float32(0.0))
+	return repair
+}
+
+// stripCodeFence membuang pembungkus ```json ... ``` yang sering ditambahkan
+// model di sekitar JSON.
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+		text = text[nl+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
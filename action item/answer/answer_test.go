@@ -0,0 +1,188 @@
+package answer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"proxy/fakes"
+)
+
+// fakeGenerator menjawab setiap GenerateContent dengan text dan mencatat
+// config yang diterimanya.
+type fakeGenerator struct {
+	text    string
+	configs []*genai.GenerateContentConfig
+}
+
+func (g *fakeGenerator) GenerateContent(_ context.Context, _ string, _ []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	g.configs = append(g.configs, config)
+	return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		Content: genai.NewContentFromText(g.text, genai.RoleModel),
+	}}}, nil
+}
+
+func TestResolve(t *testing.T) {
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText("Kamu Travel Buddy.", "system"),
+		Tools:             []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "getCurrentWeather"}}}},
+	}
+	tests := []struct {
+		name    string
+		text    string
+		repair  string
+		want    string
+		outcome Outcome
+	}{
+		{name: "valid", text: `{"response": "Cerah."}`, want: "Cerah.", outcome: OutcomeValid},
+		{name: "diperbaiki", text: "Cerah.", repair: `{"response": "Cerah!"}`, want: "Cerah!", outcome: OutcomeRepaired},
+		{name: "fallback", text: "Cerah.", repair: "masih bukan JSON", want: "Cerah.", outcome: OutcomeFallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &fakeGenerator{text: tt.repair}
+			env, outcome := Resolve(context.Background(), gen, "model", config, nil, tt.text)
+			if env.Response != tt.want || outcome != tt.outcome {
+				t.Fatalf("Resolve = %q, %s; ingin %q, %s", env.Response, outcome, tt.want, tt.outcome)
+			}
+			if tt.outcome == OutcomeValid {
+				if len(gen.configs) != 0 {
+					t.Errorf("jawaban valid tetap meminta perbaikan")
+				}
+				return
+			}
+			if len(gen.configs) != 1 {
+				t.Fatalf("perbaikan diminta %d kali, ingin 1", len(gen.configs))
+			}
+			repair := gen.configs[0]
+			if repair.SystemInstruction != config.SystemInstruction {
+				t.Errorf("SystemInstruction tidak ikut dikirim: %+v", repair.SystemInstruction)
+			}
+			if repair.Tools != nil || repair.ResponseMIMEType != "application/json" || repair.ResponseSchema != Schema() {
+				t.Errorf("config perbaikan = %+v", repair)
+			}
+			if config.Tools == nil || config.ResponseMIMEType != "" {
+				t.Errorf("config asli ikut berubah: %+v", config)
+			}
+		})
+	}
+}
+
+func TestResolveRepairLogged(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	tests := []struct {
+		name   string
+		repair *genai.GenerateContentResponse
+		fail   bool
+		want   string
+	}{
+		{name: "permintaan gagal", fail: true, want: "Perbaikan jawaban gagal dikirim"},
+		{name: "tetap tidak valid", repair: fakes.TextResponse(`{"transcript": "x"}`), want: "Jawaban perbaikan tetap tidak valid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			api := fakes.NewServer()
+			defer api.Close()
+			if tt.repair != nil {
+				api.QueueGenerateContent(tt.repair)
+			}
+			if tt.fail {
+				api.Fail("/v1/projects/"+fakes.Project+"/locations/"+fakes.Location+"/publishers/google/models/model:generateContent", http.StatusInternalServerError)
+			}
+			client, err := genai.NewClient(context.Background(), api.GenAIConfig())
+			if err != nil {
+				t.Fatalf("genai.NewClient: %v", err)
+			}
+
+			env, outcome := Resolve(context.Background(), client.Models, "model", nil, nil, "Cerah.")
+			if env.Response != "Cerah." || outcome != OutcomeFallback {
+				t.Fatalf("Resolve = %q, %s; ingin fallback", env.Response, outcome)
+			}
+			if len(api.Requests()) != 1 {
+				t.Errorf("request = %d, ingin 1 permintaan perbaikan", len(api.Requests()))
+			}
+			if !strings.Contains(logs.String(), tt.want) {
+				t.Errorf("log = %q, ingin memuat %q", logs.String(), tt.want)
+			}
+		})
+	}
+}
+
+// seqGenerator menjawab GenerateContent ke-i dengan replies[i] dan mencatat
+// contents serta config setiap panggilan. Reply dengan err gagal.
+type seqGenerator struct {
+	replies  []reply
+	contents [][]*genai.Content
+	configs  []*genai.GenerateContentConfig
+}
+
+type reply struct {
+	text string
+	err  error
+}
+
+func (g *seqGenerator) GenerateContent(_ context.Context, _ string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	i := len(g.configs)
+	g.contents = append(g.contents, contents)
+	g.configs = append(g.configs, config)
+	if i >= len(g.replies) || g.replies[i].err != nil {
+		return nil, errors.New("model tidak tersedia")
+	}
+	return &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{
+		Content: genai.NewContentFromText(g.replies[i].text, genai.RoleModel),
+	}}}, nil
+}
+
+func TestStructured(t *testing.T) {
+	config := &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText("Kamu Travel Buddy.", "system"),
+		Tools:             []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "getCurrentWeather"}}}},
+	}
+	tests := []struct {
+		name    string
+		text    string
+		replies []reply
+		want    string
+		outcome Outcome
+	}{
+		{name: "terstruktur", text: "Cerah.", replies: []reply{{text: `{"response": "Cerah!"}`}}, want: "Cerah!", outcome: OutcomeStructured},
+		{name: "tidak valid, teks valid", text: `{"response": "Cerah."}`, replies: []reply{{text: "bukan json"}}, want: "Cerah.", outcome: OutcomeValid},
+		{name: "gagal lalu diperbaiki", text: "Cerah.", replies: []reply{{err: errors.New("500")}, {text: `{"response": "Cerah!"}`}}, want: "Cerah!", outcome: OutcomeRepaired},
+		{name: "gagal lalu fallback", text: "Cerah.", replies: []reply{{err: errors.New("500")}}, want: "Cerah.", outcome: OutcomeFallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents := []*genai.Content{
+				genai.NewContentFromText("cuaca?", genai.RoleUser),
+				genai.NewContentFromText(tt.text, genai.RoleModel),
+			}
+			gen := &seqGenerator{replies: tt.replies}
+			env, outcome := Structured(context.Background(), gen, "model", config, contents, tt.text)
+			if env.Response != tt.want || outcome != tt.outcome {
+				t.Fatalf("Structured = %q, %s; ingin %q, %s", env.Response, outcome, tt.want, tt.outcome)
+			}
+
+			// Panggilan pertama menjawab ulang tanpa jawaban teks model
+			if got := gen.contents[0]; len(got) != 1 || got[0].Role != genai.RoleUser {
+				t.Errorf("contents panggilan terstruktur = %d giliran, ingin hanya pertanyaan", len(got))
+			}
+			first := gen.configs[0]
+			if first.Tools != nil || first.ResponseMIMEType != "application/json" || first.ResponseSchema != Schema() {
+				t.Errorf("config terstruktur = %+v", first)
+			}
+			if first.SystemInstruction != config.SystemInstruction {
+				t.Errorf("SystemInstruction tidak ikut dikirim: %+v", first.SystemInstruction)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+// Package audio mengubah rekaman suara dari browser menjadi PCM yang diminta
+// Live API: 16-bit little-endian, mono, 16 kHz, dipotong menjadi frame
+// berdurasi tetap. Pipeline menerima WebM/Opus dan Ogg/Opus (MediaRecorder),
+// WAV, dan PCM mentah (misal dari AudioWorklet), mendeteksi formatnya dari
+// header data lalu dari mime type, kemudian mendecode, me-resample dan
+// mendownmix sebelum memotong frame. Jumlah sampel masuk dan keluar dicatat di
+// Stats.
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Format keluaran Pipeline.
+const (
+	SampleRate = 16000
+	// MimeType adalah mime type frame keluaran untuk realtimeInput Live API.
+	MimeType = "audio/pcm;rate=16000"
+)
+
+// DefaultFrameDuration adalah durasi frame jika Config.FrameDuration kosong.
+const DefaultFrameDuration = 100 * time.Millisecond
+
+var (
+	// ErrUnsupportedFormat berarti container atau codec audio tidak didukung,
+	// misal MP4/AAC dari Safari.
+	ErrUnsupportedFormat = errors.New("format audio tidak didukung")
+	// ErrInvalidAudio berarti data audio rusak atau tidak sesuai formatnya.
+	ErrInvalidAudio = errors.New("data audio tidak valid")
+)
+
+// Config mengatur Pipeline.
+type Config struct {
+	// FrameDuration adalah durasi satu frame keluaran; default
+	// DefaultFrameDuration.
+	FrameDuration time.Duration
+}
+
+// Frame adalah potongan PCM 16-bit little-endian mono 16 kHz.
+type Frame struct {
+	Data []byte
+	// Offset adalah posisi sampel pertama frame sejak awal rekaman.
+	Offset int64
+}
+
+// Samples mengembalikan jumlah sampel dalam frame.
+func (f Frame) Samples() int { return len(f.Data) / 2 }
+
+// Stats mencatat satu rekaman sejak awal stream.
+type Stats struct {
+	// Format adalah format yang terdeteksi, misal "webm/opus" atau "wav".
+	Format string
+	// SourceRate dan SourceChannels adalah format PCM hasil decode sebelum
+	// resample dan downmix.
+	SourceRate     int
+	SourceChannels int
+	// InputSamples adalah jumlah sampel per channel hasil decode, pada
+	// SourceRate.
+	InputSamples int64
+	// OutputSamples adalah jumlah sampel 16 kHz yang sudah keluar dalam Frame.
+	OutputSamples int64
+	Frames        int
+}
+
+// Duration mengembalikan durasi audio yang sudah keluar dalam Frame.
+func (s Stats) Duration() time.Duration {
+	return time.Duration(s.OutputSamples) * time.Second / SampleRate
+}
+
+// decoder mengubah byte satu stream audio menjadi sampel float32 interleaved
+// dalam rentang [-1, 1]. write boleh menerima data yang terpotong di mana saja;
+// data yang belum lengkap disimpan sampai write berikutnya.
+type decoder interface {
+	write(data []byte) ([]float32, error)
+	// format mengembalikan sample rate dan jumlah channel hasil write, atau 0
+	// jika header belum lengkap.
+	format() (rate, channels int)
+	name() string
+}
+
+// Pipeline mengubah satu rekaman (rangkaian Write sampai Flush) menjadi Frame.
+// Pipeline tidak aman dipakai dari beberapa goroutine.
+type Pipeline struct {
+	frameSamples int
+
+	// head adalah awal rekaman yang belum cukup untuk mendeteksi format.
+	head      []byte
+	dec       decoder
+	resampler *resampler
+	pending   []int16
+	stats     Stats
+}
+
+// NewPipeline membuat Pipeline dengan cfg.
+func NewPipeline(cfg Config) *Pipeline {
+	d := cfg.FrameDuration
+	if d <= 0 {
+		d = DefaultFrameDuration
+	}
+	frameSamples := int(int64(d) * SampleRate / int64(time.Second))
+	if frameSamples < 1 {
+		frameSamples = 1
+	}
+	return &Pipeline{frameSamples: frameSamples}
+}
+
+// Write menambahkan potongan rekaman. Format dideteksi pada Write pertama
+// setelah NewPipeline, Flush atau Reset: dari header data (WebM, Ogg, WAV),
+// lalu dari mimeType ("audio/pcm;rate=48000;channels=2", "audio/l16", ...).
+// Data tanpa header container dianggap PCM mentah, 16 kHz mono jika mimeType
+// tidak menyebut rate dan channels; jika mimeType menyebut container (misal
+// "audio/webm") Write mengembalikan ErrInvalidAudio. Header boleh terpotong
+// di beberapa Write. Write mengembalikan Frame yang sudah penuh; sisanya
+// keluar di Write berikutnya atau Flush. Setelah error, panggil Reset sebelum
+// rekaman berikutnya.
+func (p *Pipeline) Write(data []byte, mimeType string) ([]Frame, error) {
+	if p.dec == nil {
+		data = append(p.head, data...)
+		if headerPrefix(data) {
+			p.head = data
+			return nil, nil
+		}
+		p.head = nil
+		dec, err := newDecoder(data, mimeType)
+		if err != nil {
+			return nil, err
+		}
+		p.dec = dec
+	}
+	samples, err := p.dec.write(data)
+	if err != nil {
+		return nil, err
+	}
+	// Codec WebM baru diketahui setelah Tracks terbaca
+	p.stats.Format = p.dec.name()
+	return p.process(samples), nil
+}
+
+// Flush mengeluarkan sisa rekaman, termasuk frame terakhir yang lebih pendek
+// dari FrameDuration, beserta Stats seluruh rekaman, lalu menyiapkan Pipeline
+// untuk rekaman berikutnya.
+func (p *Pipeline) Flush() ([]Frame, Stats) {
+	var frames []Frame
+	if p.resampler != nil {
+		p.appendPCM(p.resampler.flush())
+	}
+	frames = p.frames(frames)
+	if len(p.pending) > 0 {
+		frames = append(frames, p.frame(p.pending))
+		p.pending = p.pending[:0]
+	}
+	stats := p.stats
+	p.Reset()
+	return frames, stats
+}
+
+// Reset membuang rekaman yang sedang berjalan tanpa mengeluarkan sisanya.
+func (p *Pipeline) Reset() {
+	p.head = nil
+	p.dec = nil
+	p.resampler = nil
+	p.pending = p.pending[:0]
+	p.stats = Stats{}
+}
+
+// Stats mengembalikan statistik rekaman yang sedang berjalan.
+func (p *Pipeline) Stats() Stats {
+	return p.stats
+}
+
+func (p *Pipeline) process(samples []float32) []Frame {
+	if len(samples) == 0 {
+		return nil
+	}
+	rate, channels := p.dec.format()
+	if p.resampler != nil && p.resampler.inRate != rate {
+		// Format berubah di tengah stream (misal header WebM baru)
+		p.appendPCM(p.resampler.flush())
+		p.resampler = nil
+	}
+	if p.resampler == nil {
+		p.resampler = newResampler(rate, SampleRate)
+	}
+	p.stats.SourceRate, p.stats.SourceChannels = rate, channels
+
+	mono := downmix(samples, channels)
+	p.stats.InputSamples += int64(len(mono))
+	p.appendPCM(p.resampler.process(mono))
+	return p.frames(nil)
+}
+
+// appendPCM mengubah sampel float32 menjadi int16 dan menambahkannya ke
+// antrean frame.
+func (p *Pipeline) appendPCM(samples []float32) {
+	for _, s := range samples {
+		p.pending = append(p.pending, toInt16(s))
+	}
+}
+
+// frames memotong antrean menjadi frame penuh.
+func (p *Pipeline) frames(frames []Frame) []Frame {
+	n := 0
+	for len(p.pending)-n >= p.frameSamples {
+		frames = append(frames, p.frame(p.pending[n:n+p.frameSamples]))
+		n += p.frameSamples
+	}
+	p.pending = append(p.pending[:0], p.pending[n:]...)
+	return frames
+}
+
+func (p *Pipeline) frame(samples []int16) Frame {
+	data := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(s))
+	}
+	f := Frame{Data: data, Offset: p.stats.OutputSamples}
+	p.stats.OutputSamples += int64(len(samples))
+	p.stats.Frames++
+	return f
+}
+
+// headerPrefix mengembalikan true jika data terlalu pendek untuk mendeteksi
+// format tetapi masih bisa menjadi awal header WebM, Ogg atau WAV.
+func headerPrefix(data []byte) bool {
+	if len(data) >= 12 {
+		return false
+	}
+	for _, magic := range [][]byte{ebmlMagic, oggMagic, riffMagic} {
+		n := min(len(data), len(magic))
+		if bytes.Equal(data[:n], magic[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// newDecoder memilih decoder dari header data, lalu dari mimeType.
+func newDecoder(data []byte, mimeType string) (decoder, error) {
+	switch {
+	case bytes.HasPrefix(data, ebmlMagic):
+		return newWebM(), nil
+	case bytes.HasPrefix(data, oggMagic):
+		return newOgg(), nil
+	case len(data) >= 12 && bytes.HasPrefix(data, riffMagic) && bytes.Equal(data[8:12], waveMagic):
+		return newWAV(), nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(mimeType)
+	if err != nil && mimeType != "" {
+		return nil, fmt.Errorf("%w: mime type %q: %v", ErrUnsupportedFormat, mimeType, err)
+	}
+	switch mediaType {
+	case "audio/pcm", "audio/l16", "audio/raw", "":
+		return newPCM(mediaType, params)
+	case "audio/webm", "audio/ogg", "audio/wav", "audio/x-wav", "audio/wave":
+		// Rekaman container harus diawali header-nya; menebak PCM mentah
+		// hanya menghasilkan derau
+		return nil, fmt.Errorf("%w: data %s tanpa header container", ErrInvalidAudio, mediaType)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, mediaType)
+}
+
+// downmix merata-ratakan channel sampel interleaved menjadi mono.
+func downmix(samples []float32, channels int) []float32 {
+	if channels <= 1 {
+		return samples
+	}
+	mono := make([]float32, len(samples)/channels)
+	for i := range mono {
+		var sum float32
+		for _, s := range samples[i*channels : (i+1)*channels] {
+			sum += s
+		}
+		mono[i] = sum / float32(channels)
+	}
+	return mono
+}
+
+func toInt16(s float32) int16 {
+	v := math.Round(float64(s) * 32767)
+	return int16(max(-32768, min(32767, v)))
+}
+
+// formatName menggabungkan container dan codec, misal "webm/opus".
+func formatName(container, codec string) string {
+	return container + "/" + strings.ToLower(codec)
+}
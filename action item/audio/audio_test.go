@@ -0,0 +1,292 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPipelineDetectFormat(t *testing.T) {
+	// 160 sampel PCM 16-bit tanpa header, 10 ms pada 16 kHz
+	pcm := make([]byte, 320)
+	wav := append([]byte("RIFF\x00\x00\x00\x00WAVE"), pcm...)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+		format   string
+		wantErr  error
+	}{
+		{name: "pcm default", data: pcm, format: "pcm"},
+		{name: "pcm dengan rate", data: pcm, mimeType: "audio/pcm;rate=16000", format: "pcm"},
+		{name: "header wav", data: wav, mimeType: "audio/webm", format: "wav"},
+		{name: "webm tanpa header", data: pcm, mimeType: "audio/webm", wantErr: ErrInvalidAudio},
+		{name: "ogg tanpa header", data: pcm, mimeType: "audio/ogg;codecs=opus", wantErr: ErrInvalidAudio},
+		{name: "wav tanpa header", data: pcm, mimeType: "audio/x-wav", wantErr: ErrInvalidAudio},
+		{name: "mp4", data: pcm, mimeType: "audio/mp4", wantErr: ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPipeline(Config{})
+			_, err := p.Write(tt.data, tt.mimeType)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, ingin %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if got := p.Stats().Format; !strings.HasPrefix(got, tt.format) {
+				t.Errorf("format = %q, ingin %q", got, tt.format)
+			}
+		})
+	}
+}
+
+// wavFile membuat WAV PCM 16-bit dari sampel interleaved.
+func wavFile(rate, channels int, samples []int16) []byte {
+	var b bytes.Buffer
+	data := 2 * len(samples)
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+data))
+	b.WriteString("WAVEfmt ")
+	for _, v := range []any{
+		uint32(16), uint16(wavFormatPCM), uint16(channels), uint32(rate),
+		uint32(rate * channels * 2), uint16(channels * 2), uint16(16),
+	} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(data))
+	binary.Write(&b, binary.LittleEndian, samples)
+	return b.Bytes()
+}
+
+// sine membuat n sampel sinus freq Hz pada rate dengan amplitudo amp,
+// diulang untuk setiap channel.
+func sine(n, rate, channels int, freq, amp float64) []int16 {
+	out := make([]int16, 0, n*channels)
+	for i := range n {
+		v := int16(amp * 32767 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+		for range channels {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// writeChunks menulis data ke p dalam potongan sebesar size byte (0 berarti
+// sekaligus), lalu Flush.
+func writeChunks(t *testing.T, p *Pipeline, data []byte, mimeType string, size int) ([]Frame, Stats) {
+	t.Helper()
+	if size <= 0 {
+		size = len(data)
+	}
+	var frames []Frame
+	for len(data) > 0 {
+		n := min(size, len(data))
+		out, err := p.Write(data[:n], mimeType)
+		if err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		frames = append(frames, out...)
+		data = data[n:]
+	}
+	rest, stats := p.Flush()
+	return append(frames, rest...), stats
+}
+
+func TestPipelineDecode(t *testing.T) {
+	ogg, err := os.ReadFile("testdata/speech.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	webm, err := os.ReadFile("testdata/speech.webm")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		mimeType string
+		format   string
+		// duration adalah durasi rekaman; jumlah sampel keluaran harus
+		// mendekati duration*SampleRate.
+		duration time.Duration
+		// rate dan channels adalah format hasil decode sebelum resample.
+		rate, channels int
+	}{
+		// Fixture Opus berisi 50 paket SILK 20 ms, lihat testdata/README.md
+		{name: "ogg opus", data: ogg, mimeType: "audio/ogg;codecs=opus", format: "ogg/opus", duration: time.Second, rate: SampleRate, channels: 1},
+		{name: "webm opus", data: webm, mimeType: "audio/webm;codecs=opus", format: "webm/opus", duration: time.Second, rate: SampleRate, channels: 1},
+		{name: "wav 48 kHz stereo", data: wavFile(48000, 2, sine(48000, 48000, 2, 440, 0.5)), mimeType: "audio/wav", format: "wav", duration: time.Second, rate: 48000, channels: 2},
+		{name: "wav 16 kHz", data: wavFile(SampleRate, 1, sine(8000, SampleRate, 1, 440, 0.5)), format: "wav", duration: 500 * time.Millisecond, rate: SampleRate, channels: 1},
+		{name: "pcm 44.1 kHz", data: wavFile(44100, 1, sine(44100, 44100, 1, 440, 0.5))[44:], mimeType: "audio/pcm;rate=44100", format: "pcm", duration: time.Second, rate: 44100, channels: 1},
+	}
+
+	const frameDuration = 100 * time.Millisecond
+	frameSamples := int(frameDuration * SampleRate / time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want []byte
+			var wantStats Stats
+			// Potongan yang memecah header, paket dan sampel di tengah harus
+			// menghasilkan frame yang sama dengan satu Write
+			for _, size := range []int{0, 1, 7, 100, 333} {
+				p := NewPipeline(Config{FrameDuration: frameDuration})
+				frames, stats := writeChunks(t, p, tt.data, tt.mimeType, size)
+
+				var all []byte
+				var offset int64
+				for i, f := range frames {
+					if f.Offset != offset {
+						t.Fatalf("potongan %d: frame %d offset %d, ingin %d", size, i, f.Offset, offset)
+					}
+					if i < len(frames)-1 && f.Samples() != frameSamples {
+						t.Fatalf("potongan %d: frame %d berisi %d sampel, ingin %d", size, i, f.Samples(), frameSamples)
+					}
+					if f.Samples() == 0 || f.Samples() > frameSamples {
+						t.Fatalf("potongan %d: frame terakhir berisi %d sampel", size, f.Samples())
+					}
+					offset += int64(f.Samples())
+					all = append(all, f.Data...)
+				}
+
+				if stats.Format != tt.format || stats.SourceRate != tt.rate || stats.SourceChannels != tt.channels {
+					t.Errorf("potongan %d: stats = %+v, ingin %s %d Hz %d channel", size, stats, tt.format, tt.rate, tt.channels)
+				}
+				if stats.OutputSamples != offset || stats.Frames != len(frames) {
+					t.Errorf("potongan %d: stats = %+v, ingin %d sampel dalam %d frame", size, stats, offset, len(frames))
+				}
+				// Opus membuang pre-skip (6.5 ms) di awal
+				wantSamples := int64(tt.duration * SampleRate / time.Second)
+				if d := offset - wantSamples; d > 0 || d < -int64(SampleRate/100) {
+					t.Errorf("potongan %d: %d sampel keluaran, ingin sekitar %d", size, offset, wantSamples)
+				}
+				wantIn := offset * int64(tt.rate) / SampleRate
+				if d := stats.InputSamples - wantIn; d < -1 || d > int64(tt.rate/SampleRate)+1 {
+					t.Errorf("potongan %d: %d sampel masukan untuk %d sampel keluaran", size, stats.InputSamples, offset)
+				}
+
+				if size == 0 {
+					want, wantStats = all, stats
+					continue
+				}
+				if stats != wantStats {
+					t.Errorf("potongan %d: stats = %+v, ingin %+v", size, stats, wantStats)
+				}
+				if !bytes.Equal(all, want) {
+					t.Errorf("potongan %d: PCM berbeda dari satu Write", size)
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineReuse(t *testing.T) {
+	p := NewPipeline(Config{})
+	pcm := wavFile(SampleRate, 1, sine(2000, SampleRate, 1, 440, 0.5))[44:]
+
+	frames, stats := writeChunks(t, p, pcm, "", 0)
+	if stats.OutputSamples != 2000 || len(frames) != 2 || frames[1].Samples() != 400 {
+		t.Fatalf("rekaman pertama: %d frame, stats %+v", len(frames), stats)
+	}
+
+	// Rekaman berikutnya setelah Flush mulai dari offset 0 dengan format baru
+	frames, stats = writeChunks(t, p, wavFile(48000, 2, sine(4800, 48000, 2, 440, 0.5)), "", 0)
+	if stats.Format != "wav" || stats.InputSamples != 4800 || stats.OutputSamples != 1600 {
+		t.Errorf("rekaman kedua: stats %+v", stats)
+	}
+	if len(frames) != 1 || frames[0].Offset != 0 {
+		t.Errorf("rekaman kedua: frame %+v", frames)
+	}
+}
+
+func TestResampler(t *testing.T) {
+	tests := []struct {
+		inRate, outRate int
+		in              int
+	}{
+		{48000, 16000, 48000},
+		{44100, 16000, 44100},
+		{8000, 16000, 8000},
+		{48000, 16000, 1001},
+		{22050, 16000, 10},
+		{16000, 16000, 1600},
+	}
+	for _, tt := range tests {
+		r := newResampler(tt.inRate, tt.outRate)
+		input := make([]float32, tt.in)
+		for i := range input {
+			input[i] = float32(math.Sin(2 * math.Pi * 440 * float64(i) / float64(tt.inRate)))
+		}
+
+		var out []float32
+		for i := 0; i < len(input); i += 97 {
+			out = append(out, r.process(input[i:min(i+97, len(input))])...)
+		}
+		processed := len(out)
+		out = append(out, r.flush()...)
+
+		// Keluaran total ceil(in*outRate/inRate); flush hanya mengeluarkan
+		// sampel yang menunggu filter di akhir stream
+		want := (tt.in*tt.outRate + tt.inRate - 1) / tt.inRate
+		if len(out) != want {
+			t.Errorf("%d -> %d Hz: %d sampel, ingin %d", tt.inRate, tt.outRate, len(out), want)
+		}
+		maxFlush := r.halfWidth*tt.outRate/tt.inRate + 1
+		if tt.inRate == tt.outRate {
+			maxFlush = 0
+		}
+		if flushed := len(out) - processed; flushed > maxFlush {
+			t.Errorf("%d -> %d Hz: flush mengeluarkan %d sampel, ingin paling banyak %d", tt.inRate, tt.outRate, flushed, maxFlush)
+		}
+
+		// Sinus 440 Hz di passband harus keluar dengan amplitudo yang sama
+		if tt.in < tt.inRate/10 {
+			continue
+		}
+		var peak float64
+		for _, s := range out[len(out)/4 : len(out)*3/4] {
+			peak = math.Max(peak, math.Abs(float64(s)))
+		}
+		if math.Abs(peak-1) > 0.02 {
+			t.Errorf("%d -> %d Hz: amplitudo %.3f, ingin sekitar 1", tt.inRate, tt.outRate, peak)
+		}
+	}
+}
+
+func TestDownmix(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []float32
+		channels int
+		want     []float32
+	}{
+		{name: "mono", samples: []float32{0.1, -0.2}, channels: 1, want: []float32{0.1, -0.2}},
+		{name: "stereo", samples: []float32{1, 0, 0.5, -0.5, -1, -1}, channels: 2, want: []float32{0.5, 0, -1}},
+		{name: "tiga channel", samples: []float32{0.3, 0.3, 0.3, 0.9, 0, 0}, channels: 3, want: []float32{0.3, 0.3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := downmix(tt.samples, tt.channels)
+			if len(got) != len(tt.want) {
+				t.Fatalf("downmix = %v, ingin %v", got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(float64(got[i]-tt.want[i])) > 1e-6 {
+					t.Fatalf("downmix = %v, ingin %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
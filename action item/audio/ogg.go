@@ -0,0 +1,102 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+var oggMagic = []byte("OggS")
+
+// oggHeaderSize adalah ukuran header page Ogg sebelum tabel segmen.
+const oggHeaderSize = 27
+
+// oggDecoder membaca Ogg/Opus (RFC 7845) secara bertahap. Hanya logical
+// stream pertama yang didecode.
+type oggDecoder struct {
+	buf    []byte
+	serial uint32
+	// packets adalah jumlah paket lengkap stream sejauh ini; paket 0
+	// OpusHead, paket 1 OpusTags, sisanya audio.
+	packets int
+	// packet adalah paket yang berlanjut ke page berikutnya.
+	packet []byte
+	opus   *opusDecoder
+}
+
+func newOgg() *oggDecoder { return &oggDecoder{} }
+
+func (d *oggDecoder) write(data []byte) ([]float32, error) {
+	d.buf = append(d.buf, data...)
+	var samples []float32
+	for len(d.buf) >= oggHeaderSize {
+		if !bytes.HasPrefix(d.buf, oggMagic) {
+			return nil, fmt.Errorf("%w: page Ogg tidak valid", ErrInvalidAudio)
+		}
+		nsegs := int(d.buf[26])
+		if len(d.buf) < oggHeaderSize+nsegs {
+			break
+		}
+		lacing := d.buf[oggHeaderSize : oggHeaderSize+nsegs]
+		size := 0
+		for _, l := range lacing {
+			size += int(l)
+		}
+		if len(d.buf) < oggHeaderSize+nsegs+size {
+			break
+		}
+		serial := binary.LittleEndian.Uint32(d.buf[14:18])
+		body := d.buf[oggHeaderSize+nsegs : oggHeaderSize+nsegs+size]
+		d.buf = d.buf[oggHeaderSize+nsegs+size:]
+		if d.packets == 0 && d.packet == nil {
+			d.serial = serial
+		} else if serial != d.serial {
+			continue
+		}
+
+		for _, l := range lacing {
+			d.packet = append(d.packet, body[:l]...)
+			body = body[l:]
+			if l == 255 {
+				continue
+			}
+			var err error
+			samples, err = d.handle(samples, d.packet)
+			if err != nil {
+				return nil, err
+			}
+			d.packet = d.packet[:0]
+		}
+	}
+	return samples, nil
+}
+
+// handle memproses satu paket lengkap.
+func (d *oggDecoder) handle(samples []float32, packet []byte) ([]float32, error) {
+	d.packets++
+	switch d.packets {
+	case 1:
+		if !bytes.HasPrefix(packet, opusHeadMagic) {
+			return nil, fmt.Errorf("%w: codec Ogg selain Opus", ErrUnsupportedFormat)
+		}
+		head, err := parseOpusHead(packet)
+		if err != nil {
+			return nil, err
+		}
+		d.opus, err = newOpus(head)
+		return samples, err
+	case 2:
+		// OpusTags
+		return samples, nil
+	}
+	return d.opus.decode(samples, packet)
+}
+
+func (d *oggDecoder) format() (int, int) {
+	if d.opus == nil {
+		return 0, 0
+	}
+	return SampleRate, 1
+}
+
+func (d *oggDecoder) name() string { return formatName("ogg", "opus") }
@@ -0,0 +1,86 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/pion/opus"
+)
+
+// opusMaxSamples adalah jumlah sampel terbanyak satu paket Opus (120 ms) pada
+// SampleRate.
+const opusMaxSamples = 120 * SampleRate / 1000
+
+var opusHeadMagic = []byte("OpusHead")
+
+// opusHead adalah header identifikasi Opus (RFC 7845 bagian 5.1), dipakai Ogg
+// dan CodecPrivate WebM.
+type opusHead struct {
+	channels int
+	// preSkip adalah jumlah sampel awal yang dibuang, pada 48 kHz.
+	preSkip int
+	// gain adalah output gain dalam dB Q7.8.
+	gain int16
+}
+
+func parseOpusHead(b []byte) (opusHead, error) {
+	if len(b) < 19 || !bytes.HasPrefix(b, opusHeadMagic) {
+		return opusHead{}, fmt.Errorf("%w: OpusHead tidak valid", ErrInvalidAudio)
+	}
+	h := opusHead{
+		channels: int(b[9]),
+		preSkip:  int(binary.LittleEndian.Uint16(b[10:12])),
+		gain:     int16(binary.LittleEndian.Uint16(b[16:18])),
+	}
+	// Mapping family 1 hanya didukung sampai stereo; di atas itu paketnya
+	// multistream
+	if family := b[18]; family > 1 || h.channels < 1 || h.channels > 2 {
+		return opusHead{}, fmt.Errorf("%w: Opus %d channel mapping family %d", ErrUnsupportedFormat, h.channels, family)
+	}
+	return h, nil
+}
+
+// opusDecoder mendecode paket Opus langsung ke SampleRate mono; downmix
+// dilakukan pion/opus.
+type opusDecoder struct {
+	dec  opus.Decoder
+	out  []float32
+	skip int
+	gain float32
+}
+
+func newOpus(head opusHead) (*opusDecoder, error) {
+	dec, err := opus.NewDecoderWithOutput(SampleRate, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create opus decoder: %w", err)
+	}
+	return &opusDecoder{
+		dec:  dec,
+		out:  make([]float32, opusMaxSamples),
+		skip: head.preSkip * SampleRate / 48000,
+		gain: float32(math.Pow(10, float64(head.gain)/(20*256))),
+	}, nil
+}
+
+// decode mendecode satu paket dan menambahkan hasilnya ke samples.
+func (d *opusDecoder) decode(samples []float32, packet []byte) ([]float32, error) {
+	if len(packet) == 0 {
+		return samples, nil
+	}
+	n, err := d.dec.DecodeToFloat32(packet, d.out)
+	if err != nil {
+		return nil, fmt.Errorf("%w: paket opus: %v", ErrInvalidAudio, err)
+	}
+	out := d.out[:n]
+	if d.skip > 0 {
+		skip := min(d.skip, n)
+		out = out[skip:]
+		d.skip -= skip
+	}
+	for _, s := range out {
+		samples = append(samples, s*d.gain)
+	}
+	return samples, nil
+}
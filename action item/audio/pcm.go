@@ -0,0 +1,123 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Batas sample rate masukan. Rate yang terlalu kecil membuat resampler
+// menghasilkan keluaran berlipat-lipat dari masukan.
+const (
+	minSampleRate = 8000
+	maxSampleRate = 384000
+)
+
+// sampleFormat adalah tata letak sampel PCM interleaved.
+type sampleFormat struct {
+	rate      int
+	channels  int
+	bits      int // 8, 16, 24, 32 atau 64
+	float     bool
+	bigEndian bool
+}
+
+func (f sampleFormat) frameSize() int { return f.channels * f.bits / 8 }
+
+func (f sampleFormat) validate() error {
+	if f.rate < minSampleRate || f.rate > maxSampleRate {
+		return fmt.Errorf("%w: sample rate %d", ErrUnsupportedFormat, f.rate)
+	}
+	if f.channels <= 0 || f.channels > 8 {
+		return fmt.Errorf("%w: %d channel", ErrUnsupportedFormat, f.channels)
+	}
+	switch {
+	case f.float && (f.bits == 32 || f.bits == 64):
+	case !f.float && (f.bits == 8 || f.bits == 16 || f.bits == 24 || f.bits == 32):
+	default:
+		return fmt.Errorf("%w: sampel %d-bit float=%v", ErrUnsupportedFormat, f.bits, f.float)
+	}
+	return nil
+}
+
+// decode mengubah frame lengkap di awal data menjadi sampel float32 dan
+// mengembalikan sisa byte yang belum membentuk satu frame.
+func (f sampleFormat) decode(data []byte) (samples []float32, rest []byte) {
+	size := f.bits / 8
+	n := len(data) / f.frameSize() * f.channels
+	samples = make([]float32, n)
+	var order binary.ByteOrder = binary.LittleEndian
+	if f.bigEndian {
+		order = binary.BigEndian
+	}
+	for i := range samples {
+		b := data[i*size : (i+1)*size]
+		switch {
+		case f.float && f.bits == 32:
+			samples[i] = math.Float32frombits(order.Uint32(b))
+		case f.float:
+			samples[i] = float32(math.Float64frombits(order.Uint64(b)))
+		case f.bits == 8:
+			// PCM 8-bit (WAV) unsigned dengan titik nol 128
+			samples[i] = float32(int(b[0])-128) / 128
+		case f.bits == 16:
+			samples[i] = float32(int16(order.Uint16(b))) / 32768
+		case f.bits == 24:
+			var v int32
+			if f.bigEndian {
+				v = int32(b[0])<<24 | int32(b[1])<<16 | int32(b[2])<<8
+			} else {
+				v = int32(b[2])<<24 | int32(b[1])<<16 | int32(b[0])<<8
+			}
+			samples[i] = float32(v>>8) / (1 << 23)
+		default:
+			samples[i] = float32(float64(int32(order.Uint32(b))) / (1 << 31))
+		}
+	}
+	return samples, data[n*size:]
+}
+
+// pcmDecoder membaca PCM mentah tanpa header.
+type pcmDecoder struct {
+	sampleFormat
+	rest []byte
+}
+
+// newPCM membuat decoder PCM mentah dari parameter mime type: rate (default
+// SampleRate) dan channels (default 1). "audio/l16" big-endian sesuai RFC
+// 2586; selain itu 16-bit little-endian seperti "audio/pcm" Live API.
+func newPCM(mediaType string, params map[string]string) (*pcmDecoder, error) {
+	f := sampleFormat{rate: SampleRate, channels: 1, bits: 16, bigEndian: mediaType == "audio/l16"}
+	if v, ok := params["rate"]; ok {
+		rate, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: rate %q", ErrUnsupportedFormat, v)
+		}
+		f.rate = rate
+	}
+	if v, ok := params["channels"]; ok {
+		channels, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: channels %q", ErrUnsupportedFormat, v)
+		}
+		f.channels = channels
+	}
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	return &pcmDecoder{sampleFormat: f}, nil
+}
+
+func (d *pcmDecoder) write(data []byte) ([]float32, error) {
+	if len(d.rest) > 0 {
+		data = append(d.rest, data...)
+	}
+	samples, rest := d.decode(data)
+	d.rest = append([]byte(nil), rest...)
+	return samples, nil
+}
+
+func (d *pcmDecoder) format() (int, int) { return d.rate, d.channels }
+
+func (d *pcmDecoder) name() string { return "pcm" }
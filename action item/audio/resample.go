@@ -0,0 +1,159 @@
+package audio
+
+import "math"
+
+// Parameter filter resampler.
+const (
+	// resampleZeroCrossings adalah jumlah zero crossing sinc di tiap sisi.
+	resampleZeroCrossings = 8
+	// resampleRolloff menurunkan cutoff sedikit di bawah Nyquist agar transisi
+	// filter tidak menimbulkan aliasing.
+	resampleRolloff = 0.92
+	// resampleMaxPhases membatasi ukuran tabel bobot; rasio yang lebih rumit
+	// menghitung bobot per sampel.
+	resampleMaxPhases = 4096
+)
+
+// resampler mengubah sample rate mono dengan filter windowed-sinc (Blackman).
+// Sampel keluaran ke-n berada tepat di posisi masukan n*inRate/outRate,
+// sehingga tidak ada drift pada rekaman panjang.
+type resampler struct {
+	inRate, outRate int
+	// up dan down adalah outRate dan inRate dibagi FPB keduanya.
+	up, down  int64
+	cutoff    float64
+	halfWidth int
+	// phases[p] adalah bobot untuk posisi pecahan p/up, atau nil jika up
+	// terlalu besar.
+	phases [][]float32
+
+	// buf menyimpan masukan mulai dari indeks base.
+	buf  []float32
+	base int64
+	in   int64
+	out  int64
+}
+
+func newResampler(inRate, outRate int) *resampler {
+	g := gcd(inRate, outRate)
+	r := &resampler{
+		inRate:  inRate,
+		outRate: outRate,
+		up:      int64(outRate / g),
+		down:    int64(inRate / g),
+	}
+	if inRate == outRate {
+		return r
+	}
+	// Cutoff dalam siklus per sampel masukan; saat downsampling di bawah
+	// Nyquist keluaran
+	r.cutoff = 0.5 * math.Min(1, float64(outRate)/float64(inRate)) * resampleRolloff
+	r.halfWidth = int(math.Ceil(resampleZeroCrossings / (2 * r.cutoff)))
+	if r.up <= resampleMaxPhases {
+		r.phases = make([][]float32, r.up)
+		for p := range r.phases {
+			r.phases[p] = r.weights(int64(p))
+		}
+	}
+	return r
+}
+
+// process menambahkan masukan dan mengembalikan keluaran yang sudah bisa
+// dihitung.
+func (r *resampler) process(samples []float32) []float32 {
+	if r.inRate == r.outRate {
+		r.in += int64(len(samples))
+		r.out = r.in
+		return samples
+	}
+	r.buf = append(r.buf, samples...)
+	r.in += int64(len(samples))
+	var out []float32
+	for {
+		i := r.out * r.down / r.up
+		if i+int64(r.halfWidth) >= r.in {
+			break
+		}
+		out = append(out, r.sample(i, r.out*r.down%r.up))
+		r.out++
+	}
+	// Buang masukan yang tidak lagi dibutuhkan sampel berikutnya
+	if keep := r.out*r.down/r.up - int64(r.halfWidth) + 1; keep > r.base {
+		drop := min(keep-r.base, int64(len(r.buf)))
+		r.buf = append(r.buf[:0], r.buf[drop:]...)
+		r.base += drop
+	}
+	return out
+}
+
+// flush mengeluarkan sisa keluaran dengan menganggap masukan setelah akhir
+// stream bernilai nol, sehingga total keluaran ceil(in*outRate/inRate).
+func (r *resampler) flush() []float32 {
+	if r.inRate == r.outRate {
+		return nil
+	}
+	total := (r.in*r.up + r.down - 1) / r.down
+	var out []float32
+	for ; r.out < total; r.out++ {
+		out = append(out, r.sample(r.out*r.down/r.up, r.out*r.down%r.up))
+	}
+	return out
+}
+
+// sample menghitung satu keluaran di posisi masukan i + phase/up.
+func (r *resampler) sample(i, phase int64) float32 {
+	var w []float32
+	if r.phases != nil {
+		w = r.phases[phase]
+	} else {
+		w = r.weights(phase)
+	}
+	var sum float32
+	start := i - int64(r.halfWidth) + 1
+	for k, weight := range w {
+		j := start + int64(k)
+		if j < r.base || j >= r.in {
+			// Sebelum awal atau setelah akhir stream
+			continue
+		}
+		sum += r.buf[j-r.base] * weight
+	}
+	return sum
+}
+
+// weights menghitung bobot filter untuk masukan i-halfWidth+1 ... i+halfWidth
+// di posisi pecahan phase/up, dinormalisasi agar berjumlah 1.
+func (r *resampler) weights(phase int64) []float32 {
+	frac := float64(phase) / float64(r.up)
+	w := make([]float64, 2*r.halfWidth)
+	var sum float64
+	for k := range w {
+		d := float64(k-r.halfWidth+1) - frac
+		x := d / float64(r.halfWidth)
+		if math.Abs(x) >= 1 {
+			continue
+		}
+		window := 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+		w[k] = 2 * r.cutoff * sinc(2*r.cutoff*d) * window
+		sum += w[k]
+	}
+	out := make([]float32, len(w))
+	for k := range w {
+		out[k] = float32(w[k] / sum)
+	}
+	return out
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
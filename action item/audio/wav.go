@@ -0,0 +1,158 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var (
+	riffMagic = []byte("RIFF")
+	waveMagic = []byte("WAVE")
+)
+
+// Format tag chunk "fmt " WAV.
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// wavDecoder membaca WAV (RIFF) secara bertahap. Ukuran chunk "data" 0 atau
+// 0xFFFFFFFF, seperti pada WAV yang di-stream, berarti data sampai akhir
+// stream.
+type wavDecoder struct {
+	buf []byte
+	// header true setelah header RIFF dibaca.
+	header bool
+	// skip adalah sisa byte chunk yang dilewati.
+	skip int64
+	// fmt diisi dari chunk "fmt ".
+	fmt *sampleFormat
+	// data true di dalam chunk "data"; remaining adalah sisa byte-nya, -1
+	// jika sampai akhir stream.
+	data      bool
+	remaining int64
+}
+
+func newWAV() *wavDecoder { return &wavDecoder{} }
+
+func (d *wavDecoder) write(data []byte) ([]float32, error) {
+	d.buf = append(d.buf, data...)
+	var samples []float32
+	for {
+		if d.skip > 0 {
+			n := min(d.skip, int64(len(d.buf)))
+			d.buf = d.buf[n:]
+			d.skip -= n
+			if d.skip > 0 {
+				break
+			}
+		}
+		if d.data {
+			chunk := d.buf
+			if d.remaining >= 0 && int64(len(chunk)) > d.remaining {
+				chunk = chunk[:d.remaining]
+			}
+			s, rest := d.fmt.decode(chunk)
+			samples = append(samples, s...)
+			used := int64(len(chunk) - len(rest))
+			d.buf = d.buf[used:]
+			if d.remaining < 0 {
+				break
+			}
+			d.remaining -= used
+			if d.remaining >= int64(d.fmt.frameSize()) {
+				break
+			}
+			// Chunk data selesai; sisa byte yang tidak membentuk frame dan
+			// padding dilewati bersama chunk berikutnya
+			d.data = false
+			d.skip = d.remaining
+			continue
+		}
+		if !d.header {
+			if len(d.buf) < 12 {
+				break
+			}
+			if string(d.buf[:4]) != string(riffMagic) || string(d.buf[8:12]) != string(waveMagic) {
+				return nil, fmt.Errorf("%w: header WAV tidak valid", ErrInvalidAudio)
+			}
+			d.buf = d.buf[12:]
+			d.header = true
+			continue
+		}
+		if len(d.buf) < 8 {
+			break
+		}
+		id := string(d.buf[:4])
+		size := int64(binary.LittleEndian.Uint32(d.buf[4:8]))
+		switch id {
+		case "fmt ":
+			if len(d.buf) < 8+int(size) {
+				// Tunggu chunk fmt lengkap; ukurannya kecil
+				if size > 1<<16 {
+					return nil, fmt.Errorf("%w: chunk fmt %d byte", ErrInvalidAudio, size)
+				}
+				return samples, nil
+			}
+			f, err := parseWAVFormat(d.buf[8 : 8+size])
+			if err != nil {
+				return nil, err
+			}
+			d.fmt = f
+			d.buf = d.buf[8+size:]
+			d.skip = size % 2
+		case "data":
+			if d.fmt == nil {
+				return nil, fmt.Errorf("%w: chunk data sebelum chunk fmt", ErrInvalidAudio)
+			}
+			d.buf = d.buf[8:]
+			d.data = true
+			d.remaining = size + size%2
+			if size == 0 || size == 0xFFFFFFFF {
+				d.remaining = -1
+			}
+		default:
+			d.buf = d.buf[8:]
+			d.skip = size + size%2
+		}
+	}
+	return samples, nil
+}
+
+func (d *wavDecoder) format() (int, int) {
+	if d.fmt == nil {
+		return 0, 0
+	}
+	return d.fmt.rate, d.fmt.channels
+}
+
+func (d *wavDecoder) name() string { return "wav" }
+
+// parseWAVFormat membaca isi chunk "fmt ".
+func parseWAVFormat(b []byte) (*sampleFormat, error) {
+	if len(b) < 16 {
+		return nil, fmt.Errorf("%w: chunk fmt %d byte", ErrInvalidAudio, len(b))
+	}
+	tag := binary.LittleEndian.Uint16(b[0:2])
+	if tag == wavFormatExtensible && len(b) >= 26 {
+		// Dua byte pertama GUID subformat adalah format tag sebenarnya
+		tag = binary.LittleEndian.Uint16(b[24:26])
+	}
+	f := &sampleFormat{
+		channels: int(binary.LittleEndian.Uint16(b[2:4])),
+		rate:     int(binary.LittleEndian.Uint32(b[4:8])),
+		bits:     int(binary.LittleEndian.Uint16(b[14:16])),
+	}
+	switch tag {
+	case wavFormatPCM:
+	case wavFormatFloat:
+		f.float = true
+	default:
+		return nil, fmt.Errorf("%w: WAV format tag 0x%04x", ErrUnsupportedFormat, tag)
+	}
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
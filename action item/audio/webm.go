@@ -0,0 +1,321 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+var ebmlMagic = []byte{0x1A, 0x45, 0xDF, 0xA3}
+
+// ID elemen EBML/Matroska yang dibaca webmDecoder.
+const (
+	ebmlHeaderID   = 0x1A45DFA3
+	segmentID      = 0x18538067
+	clusterID      = 0x1F43B675
+	tracksID       = 0x1654AE6B
+	trackEntryID   = 0xAE
+	trackNumberID  = 0xD7
+	codecIDID      = 0x86
+	codecPrivateID = 0x63A2
+	audioID        = 0xE1
+	blockGroupID   = 0xA0
+	blockID        = 0xA1
+	simpleBlockID  = 0xA3
+)
+
+// webmMaxElement membatasi ukuran elemen yang dibaca utuh, misal SimpleBlock.
+const webmMaxElement = 16 << 20
+
+// errIncomplete berarti data belum cukup untuk membaca elemen berikutnya.
+var errIncomplete = errors.New("incomplete")
+
+// webmTrack adalah satu TrackEntry.
+type webmTrack struct {
+	number  uint64
+	codec   string
+	private []byte
+}
+
+// webmDecoder membaca WebM (Matroska) dari MediaRecorder secara bertahap dan
+// mendecode track Opus pertama. Elemen master yang relevan (Segment, Cluster,
+// Tracks, ...) dimasuki tanpa melacak ukurannya, sehingga ukuran "unknown"
+// yang ditulis MediaRecorder untuk Segment dan Cluster tidak masalah; elemen
+// lain dilewati.
+type webmDecoder struct {
+	buf    []byte
+	skip   uint64
+	tracks []*webmTrack
+	// track adalah nomor track Opus yang didecode, 0 jika belum dipilih.
+	track uint64
+	opus  *opusDecoder
+}
+
+func newWebM() *webmDecoder { return &webmDecoder{} }
+
+func (d *webmDecoder) write(data []byte) ([]float32, error) {
+	d.buf = append(d.buf, data...)
+	var samples []float32
+	for {
+		if d.skip > 0 {
+			n := min(d.skip, uint64(len(d.buf)))
+			d.buf = d.buf[n:]
+			d.skip -= n
+			if d.skip > 0 {
+				break
+			}
+		}
+		id, idLen, err := readElementID(d.buf)
+		if err == errIncomplete {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		size, sizeLen, err := readVint(d.buf[idLen:])
+		if err == errIncomplete {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		header := idLen + sizeLen
+		unknown := size == 1<<(7*sizeLen)-1
+
+		switch id {
+		case segmentID, clusterID, tracksID, audioID, blockGroupID:
+			d.buf = d.buf[header:]
+			continue
+		case trackEntryID:
+			d.tracks = append(d.tracks, &webmTrack{})
+			d.buf = d.buf[header:]
+			continue
+		case trackNumberID, codecIDID, codecPrivateID, blockID, simpleBlockID:
+		case ebmlHeaderID:
+			// Stream baru dimulai; track dari stream sebelumnya tidak berlaku
+			d.tracks, d.track, d.opus = nil, 0, nil
+			fallthrough
+		default:
+			if unknown {
+				return nil, fmt.Errorf("%w: elemen WebM 0x%X tanpa ukuran", ErrInvalidAudio, id)
+			}
+			d.buf = d.buf[header:]
+			d.skip = size
+			continue
+		}
+
+		if unknown || size > webmMaxElement {
+			return nil, fmt.Errorf("%w: ukuran elemen WebM 0x%X tidak valid", ErrInvalidAudio, id)
+		}
+		if uint64(len(d.buf)-header) < size {
+			break
+		}
+		payload := d.buf[header : header+int(size)]
+		d.buf = d.buf[header+int(size):]
+		if samples, err = d.element(samples, id, payload); err != nil {
+			return nil, err
+		}
+	}
+	return samples, nil
+}
+
+// element memproses satu elemen leaf yang sudah lengkap.
+func (d *webmDecoder) element(samples []float32, id uint32, payload []byte) ([]float32, error) {
+	if id == blockID || id == simpleBlockID {
+		return d.block(samples, payload)
+	}
+	if len(d.tracks) == 0 {
+		return samples, nil
+	}
+	t := d.tracks[len(d.tracks)-1]
+	switch id {
+	case trackNumberID:
+		for _, b := range payload {
+			t.number = t.number<<8 | uint64(b)
+		}
+	case codecIDID:
+		t.codec = strings.TrimRight(string(payload), "\x00")
+	case codecPrivateID:
+		t.private = append([]byte(nil), payload...)
+	}
+	return samples, nil
+}
+
+// block mendecode SimpleBlock atau Block milik track Opus.
+func (d *webmDecoder) block(samples []float32, b []byte) ([]float32, error) {
+	number, n, err := readVint(b)
+	if err != nil || len(b) < n+3 {
+		return nil, fmt.Errorf("%w: block WebM terlalu pendek", ErrInvalidAudio)
+	}
+	if d.track == 0 {
+		if err := d.selectTrack(); err != nil {
+			return nil, err
+		}
+	}
+	if number != d.track {
+		return samples, nil
+	}
+	flags := b[n+2]
+	frames, err := unlace(b[n+3:], flags>>1&3)
+	if err != nil {
+		return nil, err
+	}
+	for _, frame := range frames {
+		if samples, err = d.opus.decode(samples, frame); err != nil {
+			return nil, err
+		}
+	}
+	return samples, nil
+}
+
+// selectTrack memilih track Opus pertama.
+func (d *webmDecoder) selectTrack() error {
+	var codecs []string
+	for _, t := range d.tracks {
+		if t.codec != "A_OPUS" {
+			codecs = append(codecs, t.codec)
+			continue
+		}
+		// CodecPrivate berisi OpusHead; tanpa itu anggap mono tanpa pre-skip
+		head := opusHead{channels: 1}
+		if len(t.private) > 0 {
+			var err error
+			if head, err = parseOpusHead(t.private); err != nil {
+				return err
+			}
+		}
+		dec, err := newOpus(head)
+		if err != nil {
+			return err
+		}
+		d.track, d.opus = t.number, dec
+		return nil
+	}
+	if len(codecs) == 0 {
+		return fmt.Errorf("%w: block WebM sebelum Tracks", ErrInvalidAudio)
+	}
+	return fmt.Errorf("%w: codec WebM %s", ErrUnsupportedFormat, strings.Join(codecs, ", "))
+}
+
+func (d *webmDecoder) format() (int, int) {
+	if d.opus == nil {
+		return 0, 0
+	}
+	return SampleRate, 1
+}
+
+func (d *webmDecoder) name() string {
+	if d.opus == nil {
+		return "webm"
+	}
+	return formatName("webm", "opus")
+}
+
+// unlace memecah isi block menjadi frame sesuai jenis lacing Matroska: 0
+// tanpa lacing, 1 Xiph, 2 ukuran tetap, 3 EBML.
+func unlace(b []byte, lacing byte) ([][]byte, error) {
+	if lacing == 0 {
+		return [][]byte{b}, nil
+	}
+	invalid := fmt.Errorf("%w: lacing block WebM tidak valid", ErrInvalidAudio)
+	if len(b) < 1 {
+		return nil, invalid
+	}
+	count := int(b[0]) + 1
+	b = b[1:]
+	sizes := make([]int, count)
+	switch lacing {
+	case 1:
+		for i := 0; i < count-1; i++ {
+			for {
+				if len(b) == 0 {
+					return nil, invalid
+				}
+				c := b[0]
+				sizes[i] += int(c)
+				b = b[1:]
+				if c < 255 {
+					break
+				}
+			}
+		}
+	case 2:
+		if len(b)%count != 0 {
+			return nil, invalid
+		}
+		for i := range sizes[:count-1] {
+			sizes[i] = len(b) / count
+		}
+	case 3:
+		first, n, err := readVint(b)
+		if err != nil {
+			return nil, invalid
+		}
+		b = b[n:]
+		sizes[0] = int(first)
+		for i := 1; i < count-1; i++ {
+			v, n, err := readVint(b)
+			if err != nil {
+				return nil, invalid
+			}
+			b = b[n:]
+			// Selisih ukuran ditulis signed dengan bias 2^(7n-1)-1
+			sizes[i] = sizes[i-1] + int(int64(v)-(1<<(7*n-1)-1))
+		}
+	}
+	total := 0
+	for _, s := range sizes[:count-1] {
+		if s < 0 || s > len(b) {
+			return nil, invalid
+		}
+		total += s
+	}
+	if total > len(b) {
+		return nil, invalid
+	}
+	sizes[count-1] = len(b) - total
+	frames := make([][]byte, count)
+	for i, s := range sizes {
+		frames[i], b = b[:s], b[s:]
+	}
+	return frames, nil
+}
+
+// readElementID membaca ID elemen EBML beserta bit marker-nya.
+func readElementID(b []byte) (uint32, int, error) {
+	if len(b) == 0 {
+		return 0, 0, errIncomplete
+	}
+	n := bits.LeadingZeros8(b[0]) + 1
+	if n > 4 {
+		return 0, 0, fmt.Errorf("%w: ID elemen WebM tidak valid", ErrInvalidAudio)
+	}
+	if len(b) < n {
+		return 0, 0, errIncomplete
+	}
+	var id uint32
+	for _, c := range b[:n] {
+		id = id<<8 | uint32(c)
+	}
+	return id, n, nil
+}
+
+// readVint membaca bilangan variable-length EBML tanpa bit marker-nya.
+func readVint(b []byte) (uint64, int, error) {
+	if len(b) == 0 {
+		return 0, 0, errIncomplete
+	}
+	n := bits.LeadingZeros8(b[0]) + 1
+	if n > 8 {
+		return 0, 0, fmt.Errorf("%w: vint WebM tidak valid", ErrInvalidAudio)
+	}
+	if len(b) < n {
+		return 0, 0, errIncomplete
+	}
+	v := uint64(b[0]) & (0xFF >> n)
+	for _, c := range b[1:n] {
+		v = v<<8 | uint64(c)
+	}
+	return v, n, nil
+}
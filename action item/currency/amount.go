@@ -0,0 +1,214 @@
+package currency
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// scales adalah kata atau akhiran pengali, misal "ribu" pada "50 ribu" dan
+// "jt" pada "1.5jt". Kata Arab ditulis dengan alif yang sudah dinormalisasi.
+var scales = map[string]int64{
+	"rb": 1e3, "rbu": 1e3, "ribu": 1e3, "k": 1e3, "thousand": 1e3, "الف": 1e3, "الاف": 1e3,
+	"jt": 1e6, "jta": 1e6, "juta": 1e6, "million": 1e6, "mio": 1e6, "مليون": 1e6,
+	"miliar": 1e9, "milyar": 1e9, "billion": 1e9, "bn": 1e9, "مليار": 1e9,
+	"triliun": 1e12, "trillion": 1e12,
+}
+
+// numberWords adalah kata bilangan bahasa Indonesia bernilai tetap.
+var numberWords = map[string]int64{
+	"nol": 0, "satu": 1, "dua": 2, "tiga": 3, "empat": 4, "lima": 5,
+	"enam": 6, "tujuh": 7, "delapan": 8, "sembilan": 9,
+	"sepuluh": 10, "sebelas": 11, "seratus": 100,
+}
+
+// scaledWords adalah bentuk "se-" dari kata pengali, misal "seribu" = 1 ribu.
+var scaledWords = map[string]int64{
+	"seribu": 1e3, "sejuta": 1e6, "semiliar": 1e9, "semilyar": 1e9,
+}
+
+// amountWord mengembalikan true jika word bisa menjadi bagian dari jumlah
+// uang: angka (boleh berakhiran pengali) atau kata bilangan.
+func amountWord(word string) bool {
+	if _, _, ok := splitNumber(word); ok {
+		return true
+	}
+	if _, ok := scales[word]; ok {
+		return true
+	}
+	if _, ok := numberWords[word]; ok {
+		return true
+	}
+	if _, ok := scaledWords[word]; ok {
+		return true
+	}
+	switch word {
+	case "belas", "puluh", "ratus", "setengah":
+		return true
+	}
+	return false
+}
+
+// isNumeral mengembalikan true jika word diawali angka, misal "50" atau
+// "1.5jt", bukan kata bilangan.
+func isNumeral(word string) bool {
+	_, _, ok := splitNumber(word)
+	return ok
+}
+
+// parseAmount menghitung nilai rangkaian kata jumlah uang seperti
+// ["1,5", "juta"], ["lima", "puluh", "ribu"] atau ["5rb"].
+func parseAmount(words []string) (*big.Rat, error) {
+	total, group, unit := new(big.Rat), new(big.Rat), new(big.Rat)
+	applyScale := func(scale int64) {
+		v := new(big.Rat).Add(group, unit)
+		if v.Sign() == 0 {
+			v.SetInt64(1)
+		}
+		total.Add(total, v.Mul(v, big.NewRat(scale, 1)))
+		group.SetInt64(0)
+		unit.SetInt64(0)
+	}
+	for _, word := range words {
+		if digits, suffix, ok := splitNumber(word); ok {
+			n, err := parseDecimal(digits, suffix != "")
+			if err != nil {
+				return nil, err
+			}
+			unit.Add(unit, n)
+			if suffix != "" {
+				applyScale(scales[suffix])
+			}
+			continue
+		}
+		if scale, ok := scales[word]; ok {
+			applyScale(scale)
+			continue
+		}
+		if n, ok := numberWords[word]; ok {
+			if n >= 10 {
+				group.Add(group, big.NewRat(n, 1))
+			} else {
+				unit.Add(unit, big.NewRat(n, 1))
+			}
+			continue
+		}
+		if scale, ok := scaledWords[word]; ok {
+			total.Add(total, new(big.Rat).Add(group, unit))
+			total.Add(total, big.NewRat(scale, 1))
+			group.SetInt64(0)
+			unit.SetInt64(0)
+			continue
+		}
+		switch word {
+		case "belas":
+			group.Add(group, unit.Add(unit, big.NewRat(10, 1)))
+		case "puluh":
+			group.Add(group, unit.Mul(unit, big.NewRat(10, 1)))
+		case "ratus":
+			group.Add(group, unit.Mul(unit, big.NewRat(100, 1)))
+		case "setengah":
+			unit.Add(unit, big.NewRat(1, 2))
+			continue
+		default:
+			return nil, fmt.Errorf("%w: kata %q", ErrInvalidAmount, word)
+		}
+		unit.SetInt64(0)
+	}
+	total.Add(total, group)
+	total.Add(total, unit)
+	return total, nil
+}
+
+// splitNumber memisahkan angka di awal word dari akhiran pengalinya, misal
+// "1.5jt" menjadi "1.5" dan "jt". ok false jika word tidak diawali angka atau
+// akhirannya bukan pengali.
+func splitNumber(word string) (digits, suffix string, ok bool) {
+	end := strings.IndexFunc(word, func(r rune) bool {
+		return !isDigit(r) && r != '.' && r != ','
+	})
+	if end < 0 {
+		end = len(word)
+	}
+	digits, suffix = word[:end], word[end:]
+	if digits == "" || !isDigit([]rune(digits)[0]) {
+		return "", "", false
+	}
+	if _, isScale := scales[suffix]; suffix != "" && !isScale {
+		return "", "", false
+	}
+	return digits, suffix, true
+}
+
+// parseDecimal membaca angka dengan pemisah ribuan atau desimal gaya
+// Indonesia maupun Inggris: "50.000" dan "1,000" adalah ribuan, "1,5" dan
+// "12.50" desimal. Jika kedua pemisah muncul, yang terakhir adalah desimal.
+// Satu pemisah yang diikuti tepat tiga digit dianggap pemisah ribuan, kecuali
+// angka memakai akhiran pengali (scaled) seperti "1.500jt".
+func parseDecimal(digits string, scaled bool) (*big.Rat, error) {
+	digits = strings.Trim(digits, ".,")
+	lastDot, lastComma := strings.LastIndex(digits, "."), strings.LastIndex(digits, ",")
+	decimalSep := ""
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimalSep = "."
+		if lastComma > lastDot {
+			decimalSep = ","
+		}
+	case lastDot >= 0 || lastComma >= 0:
+		sep := "."
+		if lastComma >= 0 {
+			sep = ","
+		}
+		parts := strings.Split(digits, sep)
+		if len(parts) == 2 && (scaled || len(parts[1]) != 3 || parts[0] == "0") {
+			decimalSep = sep
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range digits {
+		switch {
+		case string(r) == decimalSep:
+			b.WriteByte('.')
+		case r == '.' || r == ',':
+			// pemisah ribuan
+		default:
+			b.WriteRune('0' + r - digitZero(r))
+		}
+	}
+	n, ok := new(big.Rat).SetString(b.String())
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, digits)
+	}
+	return n, nil
+}
+
+// isDigit mengembalikan true untuk angka Latin, Arab (٠-٩) dan Persia (۰-۹).
+func isDigit(r rune) bool {
+	return digitZero(r) != 0
+}
+
+// digitZero mengembalikan rune nol dari blok digit r, atau 0 jika r bukan
+// angka.
+func digitZero(r rune) rune {
+	switch {
+	case r >= '٠' && r <= '٩':
+		return '٠'
+	case r >= '۰' && r <= '۹':
+		return '۰'
+	case r >= '0' && r <= '9':
+		return '0'
+	}
+	return 0
+}
+
+// formatAmount menulis n sebagai angka desimal tanpa nol di belakang koma,
+// misal "1500000" atau "12.5".
+func formatAmount(n *big.Rat) string {
+	if n.IsInt() {
+		return n.RatString()
+	}
+	s := strings.TrimRight(n.FloatString(8), "0")
+	return strings.TrimSuffix(s, ".")
+}
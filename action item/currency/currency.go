@@ -0,0 +1,366 @@
+// Package currency mem-parse pertanyaan konversi mata uang dalam bahasa
+// alami (Indonesia, Inggris dan Arab) menjadi Query bertipe, misal "berapa
+// rupiah 50 euro", "convert 1.5jt IDR to SAR" atau "كم 100 دولار بالريال".
+// Jumlah boleh memakai pemisah ribuan, akhiran (rb, jt, k), kata bilangan
+// ("lima puluh ribu") dan simbol (Rp, $, €, ﷼); mata uang boleh berupa kode
+// ISO 4217, nama resmi atau nama umumnya. Parse sengaja ketat: hanya query
+// dengan tepat satu jumlah dan dua mata uang yang diterima, sehingga hasilnya
+// aman dipakai sebagai jalan pintas sebelum bertanya ke model.
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error yang dibungkus oleh Parse; gunakan errors.Is untuk membedakannya.
+var (
+	ErrNoCurrency        = errors.New("mata uang tidak ditemukan")
+	ErrUnknownCurrency   = errors.New("kode mata uang tidak dikenal")
+	ErrNoTargetCurrency  = errors.New("mata uang tujuan tidak ditemukan")
+	ErrSameCurrency      = errors.New("mata uang asal dan tujuan sama")
+	ErrTooManyCurrencies = errors.New("lebih dari dua mata uang")
+	ErrNoAmount          = errors.New("jumlah uang tidak ditemukan")
+	ErrMultipleAmounts   = errors.New("lebih dari satu jumlah uang")
+	ErrInvalidAmount     = errors.New("jumlah uang tidak valid")
+)
+
+// Query adalah pertanyaan konversi hasil Parse.
+type Query struct {
+	// Amount adalah angka desimal tanpa pemisah ribuan, misal "1500000" atau
+	// "12.5", siap dipakai providers.Convert.
+	Amount string `json:"amount"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// Name mengembalikan nama resmi ISO 4217 untuk code, atau "" jika tidak
+// dikenal.
+func Name(code string) string {
+	return names[strings.ToUpper(code)]
+}
+
+// targetMarkers adalah kata sebelum mata uang tujuan, misal "ke" pada "50
+// euro ke rupiah".
+var targetMarkers = map[string]bool{
+	"ke": true, "jadi": true, "menjadi": true, "dalam": true, "kedalam": true,
+	"to": true, "into": true, "in": true,
+	"الى": true, "ل": true,
+}
+
+// index memetakan nama resmi (huruf kecil) dan alias ke kode mata uang.
+var index = func() map[string]string {
+	m := make(map[string]string, len(names)+len(aliases))
+	for code, name := range names {
+		if name = normalize(name); !commonWords[name] {
+			m[name] = code
+		}
+	}
+	for alias, code := range aliases {
+		m[normalize(alias)] = code
+	}
+	return m
+}()
+
+// maxPhraseWords adalah jumlah kata terpanjang nama mata uang di index.
+const maxPhraseWords = 4
+
+// token adalah satu kata query.
+type token struct {
+	raw  string // teks asli, untuk mengenali kode huruf besar
+	word string // huruf kecil dengan alif dinormalisasi
+	stem string // word tanpa awalan Arab "ال", "بال" atau "لل"
+	// toTarget true jika kata diawali "بال" atau "لل" ("ke/dalam"), misal
+	// "بالريال".
+	toTarget bool
+}
+
+// mention adalah mata uang yang disebut pada token[start:end].
+type mention struct {
+	code       string
+	start, end int
+	toTarget   bool
+}
+
+// span adalah rangkaian token jumlah uang token[start:end].
+type span struct {
+	start, end int
+}
+
+// Parse mem-parse text menjadi Query. Error membungkus salah satu Err* di
+// atas beserta detailnya, misal "mata uang tujuan tidak ditemukan: hanya
+// ada EUR".
+func Parse(text string) (*Query, error) {
+	tokens := tokenize(text)
+	mentions, unknown := findMentions(tokens)
+
+	var codes []string
+	for _, m := range mentions {
+		if !contains(codes, m.code) {
+			codes = append(codes, m.code)
+		}
+	}
+	switch {
+	case len(codes) == 0 && unknown != "":
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, unknown)
+	case len(codes) == 0:
+		return nil, ErrNoCurrency
+	case len(codes) == 1 && len(mentions) > 1:
+		return nil, fmt.Errorf("%w: %s", ErrSameCurrency, codes[0])
+	case len(codes) == 1 && unknown != "":
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, unknown)
+	case len(codes) == 1:
+		return nil, fmt.Errorf("%w: hanya ada %s", ErrNoTargetCurrency, codes[0])
+	case len(codes) > 2:
+		return nil, fmt.Errorf("%w: %s", ErrTooManyCurrencies, strings.Join(codes, ", "))
+	}
+
+	// Jumlah harus menempel pada mata uang asal, misal "50 euro" atau "$50"
+	var amount *span
+	var source *mention
+	for _, s := range findSpans(tokens, mentions) {
+		m := adjacentMention(s, mentions)
+		if m == nil {
+			continue
+		}
+		if amount != nil {
+			return nil, fmt.Errorf("%w: %q dan %q", ErrMultipleAmounts,
+				joinRaw(tokens[amount.start:amount.end]), joinRaw(tokens[s.start:s.end]))
+		}
+		amount, source = &s, m
+	}
+	if amount == nil {
+		return nil, fmt.Errorf("%w untuk %s", ErrNoAmount, strings.Join(codes, " atau "))
+	}
+
+	words := make([]string, 0, amount.end-amount.start)
+	for _, t := range tokens[amount.start:amount.end] {
+		words = append(words, t.word)
+	}
+	value, err := parseAmount(words)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{Amount: formatAmount(value), From: source.code, To: codes[0]}
+	if q.To == q.From {
+		q.To = codes[1]
+	}
+	return q, nil
+}
+
+// findMentions mencari semua mata uang di tokens, mengutamakan nama
+// terpanjang ("dolar singapura" sebelum "dolar"). Sebutan berurutan untuk
+// mata uang yang sama seperti "riyal SAR" digabung. unknown adalah kode
+// huruf besar pertama yang tidak dikenal, misal "XYZ".
+func findMentions(tokens []token) (mentions []mention, unknown string) {
+	for i := 0; i < len(tokens); {
+		code, n := lookupPhrase(tokens[i:])
+		if n == 0 {
+			if unknown == "" && looksLikeCode(tokens[i].raw) {
+				unknown = tokens[i].raw
+			}
+			i++
+			continue
+		}
+		toTarget := tokens[i].toTarget || (i > 0 && targetMarkers[tokens[i-1].word])
+		if last := len(mentions) - 1; last >= 0 && mentions[last].code == code && mentions[last].end == i {
+			mentions[last].end = i + n
+		} else {
+			mentions = append(mentions, mention{code: code, start: i, end: i + n, toTarget: toTarget})
+		}
+		i += n
+	}
+	return mentions, unknown
+}
+
+// lookupPhrase mencocokkan awal tokens dengan nama mata uang terpanjang dan
+// mengembalikan kodenya beserta jumlah token yang dipakai, atau n 0.
+func lookupPhrase(tokens []token) (code string, n int) {
+	for n = min(maxPhraseWords, len(tokens)); n > 0; n-- {
+		stems := make([]string, n)
+		for i := range stems {
+			stems[i] = tokens[i].stem
+		}
+		phrase := strings.Join(stems, " ")
+		if code, ok := index[phrase]; ok {
+			return code, n
+		}
+		// Bentuk jamak bahasa Inggris: "dollars", "us dollars", "euros"
+		if singular, ok := strings.CutSuffix(phrase, "s"); ok {
+			if code, ok := index[singular]; ok {
+				return code, n
+			}
+		}
+	}
+	t := tokens[0]
+	if code, ok := symbols[t.word]; ok {
+		return code, 1
+	}
+	upper := strings.ToUpper(t.word)
+	if _, ok := names[upper]; ok && (t.raw == upper || !commonWords[t.word]) {
+		return upper, 1
+	}
+	return "", 0
+}
+
+// findSpans mengelompokkan token jumlah uang yang berurutan. Angka yang
+// langsung diikuti angka lain ("50 100") dipisah menjadi dua rangkaian, dan
+// rangkaian yang hanya berisi kata pengali ("ribu") dibuang. Token yang
+// sudah menjadi bagian nama mata uang dilewati.
+func findSpans(tokens []token, mentions []mention) []span {
+	inMention := make([]bool, len(tokens))
+	for _, m := range mentions {
+		for i := m.start; i < m.end; i++ {
+			inMention[i] = true
+		}
+	}
+
+	var spans []span
+	open := false
+	for i, t := range tokens {
+		if inMention[i] || !amountWord(t.word) {
+			open = false
+			continue
+		}
+		if open && isNumeral(t.word) && !isScaleWord(tokens[i-1].word) {
+			open = false
+		}
+		if !open {
+			spans = append(spans, span{start: i})
+			open = true
+		}
+		spans[len(spans)-1].end = i + 1
+	}
+
+	valid := spans[:0]
+	for _, s := range spans {
+		for _, t := range tokens[s.start:s.end] {
+			if _, isScale := scales[t.word]; !isScale {
+				valid = append(valid, s)
+				break
+			}
+		}
+	}
+	return valid
+}
+
+// isScaleWord mengembalikan true jika word adalah kata pengali atau angka
+// berakhiran pengali, sehingga angka berikutnya masih bagian jumlah yang sama
+// ("1 juta 500 ribu").
+func isScaleWord(word string) bool {
+	if _, ok := scales[word]; ok {
+		return true
+	}
+	_, suffix, ok := splitNumber(word)
+	return ok && suffix != ""
+}
+
+// adjacentMention mengembalikan mata uang yang menempel pada s, tepat sebelum
+// ("$ 50", "USD 50") atau sesudahnya ("50 euro"). Jika keduanya ada, yang
+// bukan mata uang tujuan diutamakan, lalu yang sesudah seperti pada "berapa
+// rupiah 50 euro".
+func adjacentMention(s span, mentions []mention) *mention {
+	var before, after *mention
+	for i := range mentions {
+		switch {
+		case mentions[i].end == s.start:
+			before = &mentions[i]
+		case mentions[i].start == s.end:
+			after = &mentions[i]
+		}
+	}
+	switch {
+	case before != nil && after != nil && after.toTarget && !before.toTarget:
+		return before
+	case after != nil:
+		return after
+	}
+	return before
+}
+
+// tokenize memecah text menjadi token, membuang tanda baca di ujung kata dan
+// memisahkan simbol atau kode yang menempel pada angka ("Rp50.000", "50€",
+// "100usd").
+func tokenize(text string) []token {
+	var tokens []token
+	for _, field := range strings.Fields(text) {
+		field = strings.Trim(field, `?!,.;:()"'؟،`)
+		for _, raw := range splitAttached(field) {
+			word := normalize(raw)
+			t := token{raw: raw, word: word, stem: word}
+			switch {
+			case strings.HasPrefix(word, "بال") && len([]rune(word)) > 4:
+				t.stem, t.toTarget = strings.TrimPrefix(word, "بال"), true
+			case strings.HasPrefix(word, "لل") && len([]rune(word)) > 3:
+				t.stem, t.toTarget = strings.TrimPrefix(word, "لل"), true
+			case strings.HasPrefix(word, "ال") && len([]rune(word)) > 3:
+				t.stem = strings.TrimPrefix(word, "ال")
+			}
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// splitAttached memisahkan angka dari simbol atau kode mata uang yang
+// menempel di depan atau belakangnya. Akhiran pengali ("5rb", "1.5jt") tidak
+// dipisah.
+func splitAttached(field string) []string {
+	start := strings.IndexFunc(field, isDigit)
+	if start < 0 {
+		return []string{field}
+	}
+	if start > 0 {
+		prefix := field[:start]
+		if _, n := lookupPhrase([]token{{raw: prefix, word: normalize(prefix), stem: normalize(prefix)}}); n == 0 {
+			return []string{field}
+		}
+		return append([]string{prefix}, splitAttached(field[start:])...)
+	}
+	end := strings.IndexFunc(field, func(r rune) bool {
+		return !isDigit(r) && r != '.' && r != ','
+	})
+	if end < 0 || isNumeral(normalize(field)) {
+		return []string{field}
+	}
+	return []string{field[:end], field[end:]}
+}
+
+// normalize mengubah s ke huruf kecil, menyeragamkan alif Arab (أ, إ, آ
+// menjadi ا) dan pemisah angka Arab (٫ dan ٬).
+func normalize(s string) string {
+	return strings.NewReplacer("أ", "ا", "إ", "ا", "آ", "ا", "٫", ".", "٬", ",").Replace(strings.ToLower(s))
+}
+
+// looksLikeCode mengembalikan true jika raw berbentuk kode ISO 4217, tiga
+// huruf besar.
+func looksLikeCode(raw string) bool {
+	if len(raw) != 3 {
+		return false
+	}
+	for _, r := range raw {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func joinRaw(tokens []token) string {
+	raw := make([]string, len(tokens))
+	for i, t := range tokens {
+		raw[i] = t.raw
+	}
+	return strings.Join(raw, " ")
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want Query
+	}{
+		// Bahasa Indonesia
+		{"berapa rupiah 50 euro", Query{Amount: "50", From: "EUR", To: "IDR"}},
+		{"50 euro ke rupiah", Query{Amount: "50", From: "EUR", To: "IDR"}},
+		{"5rb rupiah ke USD", Query{Amount: "5000", From: "IDR", To: "USD"}},
+		{"Rp 50.000 berapa dolar?", Query{Amount: "50000", From: "IDR", To: "USD"}},
+		{"Rp50.000 ke ringgit", Query{Amount: "50000", From: "IDR", To: "MYR"}},
+		{"1 juta 500 ribu rupiah jadi yen", Query{Amount: "1500000", From: "IDR", To: "JPY"}},
+		{"lima puluh ribu rupiah ke dolar singapura", Query{Amount: "50000", From: "IDR", To: "SGD"}},
+		{"seratus dua puluh lima dolar ke rupiah", Query{Amount: "125", From: "USD", To: "IDR"}},
+		{"setengah juta rupiah dalam euro", Query{Amount: "500000", From: "IDR", To: "EUR"}},
+
+		// Bahasa Inggris dan kode ISO 4217
+		{"convert 1.5jt IDR to SAR", Query{Amount: "1500000", From: "IDR", To: "SAR"}},
+		{"1.5jt IDR to SAR", Query{Amount: "1500000", From: "IDR", To: "SAR"}},
+		{"12,345 yen to USD", Query{Amount: "12345", From: "JPY", To: "USD"}},
+		{"how much is $12.50 in euros", Query{Amount: "12.5", From: "USD", To: "EUR"}},
+		{"100usd to idr", Query{Amount: "100", From: "USD", To: "IDR"}},
+		{"50€ in pounds", Query{Amount: "50", From: "EUR", To: "GBP"}},
+
+		// Bahasa Arab dan ﷼
+		{"كم 100 دولار بالريال", Query{Amount: "100", From: "USD", To: "SAR"}},
+		{"200 ريال الى روبية", Query{Amount: "200", From: "SAR", To: "IDR"}},
+		{"﷼500 ke rupiah", Query{Amount: "500", From: "SAR", To: "IDR"}},
+		{"٣٠٠ يورو بالدرهم", Query{Amount: "300", From: "EUR", To: "AED"}},
+		{"1٫5 مليون روبية بالريال", Query{Amount: "1500000", From: "IDR", To: "SAR"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.text, err)
+			}
+			if *got != tt.want {
+				t.Errorf("Parse(%q) = %+v, ingin %+v", tt.text, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		text string
+		want error
+	}{
+		{"5rb", ErrNoCurrency},
+		{"12,345", ErrNoCurrency},
+		{"berapa kurs euro ke rupiah hari ini", ErrNoAmount},
+		{"50 XYZ ke rupiah", ErrUnknownCurrency},
+		{"50 XYZ ke ABC", ErrUnknownCurrency},
+		{"50 euro", ErrNoTargetCurrency},
+		{"50 USD ke dolar", ErrSameCurrency},
+		{"50 euro ke rupiah atau yen", ErrTooManyCurrencies},
+		{"50 euro tambah 20 euro ke rupiah", ErrMultipleAmounts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q) = %+v, %v, ingin error %v", tt.text, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package currency
+
+// names adalah kode mata uang ISO 4217 yang masih berlaku beserta nama
+// resminya. Kode dana, logam mulia dan kode uji (XAU, XTS, dst.) tidak
+// dimasukkan.
+var names = map[string]string{
+	"AED": "UAE Dirham",
+	"AFN": "Afghani",
+	"ALL": "Lek",
+	"AMD": "Armenian Dram",
+	"ANG": "Netherlands Antillean Guilder",
+	"AOA": "Kwanza",
+	"ARS": "Argentine Peso",
+	"AUD": "Australian Dollar",
+	"AWG": "Aruban Florin",
+	"AZN": "Azerbaijan Manat",
+	"BAM": "Convertible Mark",
+	"BBD": "Barbados Dollar",
+	"BDT": "Taka",
+	"BGN": "Bulgarian Lev",
+	"BHD": "Bahraini Dinar",
+	"BIF": "Burundi Franc",
+	"BMD": "Bermudian Dollar",
+	"BND": "Brunei Dollar",
+	"BOB": "Boliviano",
+	"BRL": "Brazilian Real",
+	"BSD": "Bahamian Dollar",
+	"BTN": "Ngultrum",
+	"BWP": "Pula",
+	"BYN": "Belarusian Ruble",
+	"BZD": "Belize Dollar",
+	"CAD": "Canadian Dollar",
+	"CDF": "Congolese Franc",
+	"CHF": "Swiss Franc",
+	"CLP": "Chilean Peso",
+	"CNY": "Yuan Renminbi",
+	"COP": "Colombian Peso",
+	"CRC": "Costa Rican Colon",
+	"CUP": "Cuban Peso",
+	"CVE": "Cabo Verde Escudo",
+	"CZK": "Czech Koruna",
+	"DJF": "Djibouti Franc",
+	"DKK": "Danish Krone",
+	"DOP": "Dominican Peso",
+	"DZD": "Algerian Dinar",
+	"EGP": "Egyptian Pound",
+	"ERN": "Nakfa",
+	"ETB": "Ethiopian Birr",
+	"EUR": "Euro",
+	"FJD": "Fiji Dollar",
+	"FKP": "Falkland Islands Pound",
+	"GBP": "Pound Sterling",
+	"GEL": "Lari",
+	"GHS": "Ghana Cedi",
+	"GIP": "Gibraltar Pound",
+	"GMD": "Dalasi",
+	"GNF": "Guinean Franc",
+	"GTQ": "Quetzal",
+	"GYD": "Guyana Dollar",
+	"HKD": "Hong Kong Dollar",
+	"HNL": "Lempira",
+	"HTG": "Gourde",
+	"HUF": "Forint",
+	"IDR": "Rupiah",
+	"ILS": "New Israeli Sheqel",
+	"INR": "Indian Rupee",
+	"IQD": "Iraqi Dinar",
+	"IRR": "Iranian Rial",
+	"ISK": "Iceland Krona",
+	"JMD": "Jamaican Dollar",
+	"JOD": "Jordanian Dinar",
+	"JPY": "Yen",
+	"KES": "Kenyan Shilling",
+	"KGS": "Som",
+	"KHR": "Riel",
+	"KMF": "Comorian Franc",
+	"KPW": "North Korean Won",
+	"KRW": "Won",
+	"KWD": "Kuwaiti Dinar",
+	"KYD": "Cayman Islands Dollar",
+	"KZT": "Tenge",
+	"LAK": "Lao Kip",
+	"LBP": "Lebanese Pound",
+	"LKR": "Sri Lanka Rupee",
+	"LRD": "Liberian Dollar",
+	"LSL": "Loti",
+	"LYD": "Libyan Dinar",
+	"MAD": "Moroccan Dirham",
+	"MDL": "Moldovan Leu",
+	"MGA": "Malagasy Ariary",
+	"MKD": "Denar",
+	"MMK": "Kyat",
+	"MNT": "Tugrik",
+	"MOP": "Pataca",
+	"MRU": "Ouguiya",
+	"MUR": "Mauritius Rupee",
+	"MVR": "Rufiyaa",
+	"MWK": "Malawi Kwacha",
+	"MXN": "Mexican Peso",
+	"MYR": "Malaysian Ringgit",
+	"MZN": "Mozambique Metical",
+	"NAD": "Namibia Dollar",
+	"NGN": "Naira",
+	"NIO": "Cordoba Oro",
+	"NOK": "Norwegian Krone",
+	"NPR": "Nepalese Rupee",
+	"NZD": "New Zealand Dollar",
+	"OMR": "Rial Omani",
+	"PAB": "Balboa",
+	"PEN": "Sol",
+	"PGK": "Kina",
+	"PHP": "Philippine Peso",
+	"PKR": "Pakistan Rupee",
+	"PLN": "Zloty",
+	"PYG": "Guarani",
+	"QAR": "Qatari Rial",
+	"RON": "Romanian Leu",
+	"RSD": "Serbian Dinar",
+	"RUB": "Russian Ruble",
+	"RWF": "Rwanda Franc",
+	"SAR": "Saudi Riyal",
+	"SBD": "Solomon Islands Dollar",
+	"SCR": "Seychelles Rupee",
+	"SDG": "Sudanese Pound",
+	"SEK": "Swedish Krona",
+	"SGD": "Singapore Dollar",
+	"SHP": "Saint Helena Pound",
+	"SLE": "Leone",
+	"SOS": "Somali Shilling",
+	"SRD": "Surinam Dollar",
+	"SSP": "South Sudanese Pound",
+	"STN": "Dobra",
+	"SVC": "El Salvador Colon",
+	"SYP": "Syrian Pound",
+	"SZL": "Lilangeni",
+	"THB": "Baht",
+	"TJS": "Somoni",
+	"TMT": "Turkmenistan New Manat",
+	"TND": "Tunisian Dinar",
+	"TOP": "Pa'anga",
+	"TRY": "Turkish Lira",
+	"TTD": "Trinidad and Tobago Dollar",
+	"TWD": "New Taiwan Dollar",
+	"TZS": "Tanzanian Shilling",
+	"UAH": "Hryvnia",
+	"UGX": "Uganda Shilling",
+	"USD": "US Dollar",
+	"UYU": "Peso Uruguayo",
+	"UZS": "Uzbekistan Sum",
+	"VES": "Bolivar Soberano",
+	"VND": "Dong",
+	"VUV": "Vatu",
+	"WST": "Tala",
+	"XAF": "CFA Franc BEAC",
+	"XCD": "East Caribbean Dollar",
+	"XCG": "Caribbean Guilder",
+	"XOF": "CFA Franc BCEAO",
+	"XPF": "CFP Franc",
+	"YER": "Yemeni Rial",
+	"ZAR": "Rand",
+	"ZMW": "Zambian Kwacha",
+	"ZWG": "Zimbabwe Gold",
+}
+
+// aliases adalah nama umum mata uang dalam bahasa Indonesia, Inggris dan
+// Arab, ditambah singkatan dan simbol, di luar nama resmi di names. Kata
+// Arab ditulis tanpa awalan "ال" karena awalan itu dibuang saat tokenisasi.
+// Nama yang dipakai banyak negara (dolar, peso, dinar, ...) diarahkan ke
+// mata uang yang paling sering ditanyakan wisatawan.
+var aliases = map[string]string{
+	// Indonesia
+	"rupiah": "IDR", "rp": "IDR",
+	"dolar": "USD", "dolar as": "USD", "dolar amerika": "USD",
+	"dolar singapura": "SGD", "dolar australia": "AUD", "dolar hongkong": "HKD",
+	"dolar hong kong": "HKD", "dolar taiwan": "TWD", "dolar kanada": "CAD",
+	"dolar selandia baru": "NZD", "dolar brunei": "BND",
+	"riyal": "SAR", "riyal saudi": "SAR", "real saudi": "SAR", "rial saudi": "SAR",
+	"riyal qatar": "QAR", "riyal oman": "OMR",
+	"ringgit": "MYR", "ringgit malaysia": "MYR", "rm": "MYR",
+	"yen jepang": "JPY", "won korea": "KRW", "baht thailand": "THB", "bath": "THB",
+	"yuan": "CNY", "renminbi": "CNY", "rmb": "CNY",
+	"peso": "PHP", "peso filipina": "PHP", "dong vietnam": "VND",
+	"dirham": "AED", "dirham uea": "AED", "dirham uni emirat arab": "AED",
+	"dinar kuwait": "KWD", "dinar yordania": "JOD", "dinar bahrain": "BHD",
+	"pound": "GBP", "poundsterling": "GBP", "pound sterling": "GBP", "sterling": "GBP",
+	"poundsterling inggris": "GBP", "pound mesir": "EGP",
+	"franc swiss": "CHF", "rupee": "INR", "rupee india": "INR", "rupe": "INR",
+	"lira": "TRY", "lira turki": "TRY", "rubel": "RUB", "rubel rusia": "RUB",
+
+	// Inggris
+	"dollar": "USD", "us dollar": "USD", "american dollar": "USD", "buck": "USD",
+	"singapore dollar": "SGD", "australian dollar": "AUD", "canadian dollar": "CAD",
+	"hong kong dollar": "HKD", "taiwan dollar": "TWD", "new zealand dollar": "NZD",
+	"british pound": "GBP", "quid": "GBP", "saudi rial": "SAR", "qatari riyal": "QAR",
+	"omani rial": "OMR", "uae dirham": "AED", "emirati dirham": "AED",
+	"japanese yen": "JPY", "korean won": "KRW", "south korean won": "KRW",
+	"thai baht": "THB", "chinese yuan": "CNY", "philippine peso": "PHP",
+	"vietnamese dong": "VND", "indonesian rupiah": "IDR", "turkish lira": "TRY",
+	"egyptian pound": "EGP", "russian ruble": "RUB", "ruble": "RUB", "rouble": "RUB",
+	"franc": "CHF",
+
+	// Arab
+	"ريال": "SAR", "ريال سعودي": "SAR", "ر.س": "SAR", "ريالات": "SAR",
+	"ريال قطري": "QAR", "ريال عماني": "OMR",
+	"دولار": "USD", "دولارات": "USD", "دولار امريكي": "USD",
+	"دولار سنغافوري": "SGD", "دولار استرالي": "AUD",
+	"يورو": "EUR", "روبية": "IDR", "روبية اندونيسية": "IDR", "روبيه": "IDR",
+	"روبية هندية": "INR",
+	"درهم":        "AED", "درهم اماراتي": "AED", "د.ا": "AED", "دراهم": "AED",
+	"دينار كويتي": "KWD", "دينار اردني": "JOD", "دينار بحريني": "BHD",
+	"جنيه": "EGP", "جنيه مصري": "EGP", "جنيه استرليني": "GBP",
+	"ين": "JPY", "ين ياباني": "JPY", "ليرة": "TRY", "ليرة تركية": "TRY",
+	"رينغيت": "MYR", "رنجت": "MYR", "يوان": "CNY",
+}
+
+// symbols adalah simbol mata uang yang boleh menempel pada angka, misal
+// "$50", "Rp50.000" atau "50€". Simbol yang dipakai banyak mata uang ($, ¥,
+// ﷼) diarahkan ke USD, JPY dan SAR.
+var symbols = map[string]string{
+	"$": "USD", "us$": "USD", "s$": "SGD", "sg$": "SGD", "a$": "AUD", "au$": "AUD",
+	"hk$": "HKD", "nt$": "TWD", "nz$": "NZD", "c$": "CAD", "r$": "BRL",
+	"rp": "IDR", "rp.": "IDR", "rm": "MYR",
+	"€": "EUR", "£": "GBP", "¥": "JPY", "₩": "KRW", "฿": "THB", "₹": "INR",
+	"₱": "PHP", "₫": "VND", "﷼": "SAR", "₺": "TRY", "₽": "RUB", "₪": "ILS", "₦": "NGN",
+}
+
+// commonWords adalah kode atau nama mata uang yang juga kata biasa dalam
+// bahasa Indonesia atau Inggris ("all", "try", "pula", "dong", ...). Kata ini
+// hanya dianggap mata uang jika ditulis sebagai kode huruf besar atau dalam
+// alias seperti "dong vietnam".
+var commonWords = map[string]bool{
+	"all": true, "try": true, "top": true, "cup": true, "pen": true, "mad": true,
+	"gel": true, "bob": true, "mop": true, "sos": true, "ron": true, "cve": true,
+	"pula": true, "sol": true, "som": true, "kina": true, "tala": true,
+	"rand": true, "lek": true, "cop": true, "kes": true, "dong": true,
+}
@@ -0,0 +1,875 @@
+// Package fakes menyediakan server HTTP lokal yang meniru backend eksternal
+// (cuaca dan prakiraan OpenWeatherMap dan weatherapi.com, Google Places
+// searchText/searchNearby/details, geocoding Google dan OpenWeatherMap,
+// exchangerate.host convert/timeframe, Google Custom Search, Text-to-Speech
+// dan Vertex AI generateContent) dengan response kalengan, serta LiveServer
+// yang meniru WebSocket BidiGenerateContent Vertex AI Live. Dipakai supaya CLI
+// agent dan proxy bisa dijalankan tanpa jaringan dan tanpa API key asli.
+package fakes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/auth"
+	"google.golang.org/genai"
+
+	"proxy/providers"
+)
+
+// Nilai yang dipakai server palsu untuk project, location dan API key.
+const (
+	Project  = "fake-project"
+	Location = "us-central1"
+	APIKey   = "fake-key"
+)
+
+// Timestamp adalah waktu kurs yang dikembalikan endpoint convert palsu.
+var Timestamp = time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// Rates adalah kurs default endpoint convert palsu, dengan key "FROM/TO".
+// Pasangan yang tidak ada dihitung lewat USD jika memungkinkan.
+var Rates = map[string]float64{
+	"USD/IDR": 16500,
+	"EUR/IDR": 18700,
+	"SAR/IDR": 4400,
+	"JPY/IDR": 110,
+	"SGD/IDR": 12700,
+	"MYR/IDR": 3900,
+	"USD/EUR": 0.88,
+	"USD/SAR": 3.75,
+	"USD/JPY": 150,
+	"USD/SGD": 1.3,
+	"USD/MYR": 4.23,
+}
+
+// Request adalah satu request yang diterima server palsu.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   []byte
+}
+
+// Server adalah httptest.Server yang melayani semua backend palsu pada satu
+// base URL. Response generateContent diambil berurutan dari antrean
+// QueueGenerateContent; jika antrean kosong model menjawab "OK".
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses []*genai.GenerateContentResponse
+	failures  map[string]int
+	requests  []Request
+}
+
+// NewServer menjalankan server palsu. Panggil Close setelah selesai.
+func NewServer() *Server {
+	s := &Server{failures: make(map[string]int)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/data/2.5/weather", s.openWeatherMap)
+	mux.HandleFunc("/data/2.5/forecast", s.openWeatherMapForecast)
+	mux.HandleFunc("/v1/forecast.json", s.weatherAPIForecast)
+	mux.HandleFunc("/v1/places:searchText", s.places(true))
+	mux.HandleFunc("/v1/places:searchNearby", s.places(false))
+	mux.HandleFunc("/v1/places/{id}", s.placeDetails)
+	mux.HandleFunc("/maps/api/geocode/json", s.googleGeocoding)
+	mux.HandleFunc("/geo/1.0/direct", s.openWeatherMapGeocoding(false))
+	mux.HandleFunc("/geo/1.0/reverse", s.openWeatherMapGeocoding(true))
+	mux.HandleFunc("/convert", s.convert)
+	mux.HandleFunc("/timeframe", s.timeframe)
+	mux.HandleFunc("/customsearch/v1", s.customSearch)
+	mux.HandleFunc("/v1/text:synthesize", s.textToSpeech)
+	mux.HandleFunc("/", s.generateContent)
+	s.Server = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// QueueGenerateContent menambahkan response yang akan dikembalikan oleh
+// pemanggilan generateContent berikutnya, satu response per pemanggilan.
+func (s *Server) QueueGenerateContent(responses ...*genai.GenerateContentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses = append(s.responses, responses...)
+}
+
+// Fail membuat setiap request ke path dijawab dengan status (misal 500 atau
+// 429). Status 0 menghapus kegagalan tersebut.
+func (s *Server) Fail(path string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status == 0 {
+		delete(s.failures, path)
+		return
+	}
+	s.failures[path] = status
+}
+
+// Requests mengembalikan salinan semua request yang sudah diterima.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ProvidersConfig mengembalikan providers.Config yang mengarahkan semua
+// provider ke server ini.
+func (s *Server) ProvidersConfig() providers.Config {
+	return providers.Config{
+		WeatherBackend:   providers.BackendOpenWeatherMap,
+		PlacesBackend:    providers.BackendGooglePlaces,
+		GeocodingBackend: providers.BackendGoogleGeocoding,
+		FXBackend:        providers.BackendExchangerateHost,
+
+		OpenWeatherMapKey:  APIKey,
+		WeatherAPIKey:      APIKey,
+		GooglePlacesKey:    APIKey,
+		GoogleGeocodingKey: APIKey,
+		ExchangeRateKey:    APIKey,
+
+		OpenWeatherMapURL:  s.URL,
+		WeatherAPIURL:      s.URL,
+		GooglePlacesURL:    s.URL,
+		GoogleGeocodingURL: s.URL,
+		ExchangeRateURL:    s.URL,
+
+		HTTPClient: s.Client(),
+	}
+}
+
+// GenAIConfig mengembalikan genai.ClientConfig untuk backend Vertex AI yang
+// mengarah ke server ini, dengan kredensial statis sehingga tidak ada
+// pencarian Application Default Credentials.
+func (s *Server) GenAIConfig() *genai.ClientConfig {
+	return &genai.ClientConfig{
+		Backend:     genai.BackendVertexAI,
+		Project:     Project,
+		Location:    Location,
+		Credentials: auth.NewCredentials(&auth.CredentialsOptions{TokenProvider: staticToken("fake-token")}),
+		HTTPClient:  s.Client(),
+		HTTPOptions: genai.HTTPOptions{BaseURL: s.URL, APIVersion: "v1"},
+	}
+}
+
+type staticToken string
+
+func (t staticToken) Token(context.Context) (*auth.Token, error) {
+	return &auth.Token{Value: string(t), Type: "Bearer"}, nil
+}
+
+// TextResponse membuat response generateContent berisi jawaban teks.
+func TextResponse(text string) *genai.GenerateContentResponse {
+	return modelResponse(&genai.Part{Text: text})
+}
+
+// FunctionCallResponse membuat response generateContent berisi satu atau
+// lebih function call dalam satu giliran model.
+func FunctionCallResponse(calls ...*genai.FunctionCall) *genai.GenerateContentResponse {
+	parts := make([]*genai.Part, 0, len(calls))
+	for _, call := range calls {
+		parts = append(parts, &genai.Part{FunctionCall: call})
+	}
+	return modelResponse(parts...)
+}
+
+func modelResponse(parts ...*genai.Part) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Role: "model", Parts: parts},
+			FinishReason: genai.FinishReasonStop,
+		}},
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     10,
+			CandidatesTokenCount: 5,
+			TotalTokenCount:      15,
+		},
+	}
+}
+
+// record mencatat setiap request lalu menerapkan kegagalan dari Fail sebelum
+// meneruskan ke handler.
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Body:   body,
+		})
+		status := s.failures[r.URL.Path]
+		s.mu.Unlock()
+
+		if status != 0 {
+			http.Error(w, fmt.Sprintf(`{"error":{"code":%d,"message":"fake failure"}}`, status), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("fakes: gagal menulis response: %v", err)
+	}
+}
+
+func (s *Server) openWeatherMap(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("appid") == "" {
+		http.Error(w, `{"cod":401,"message":"Invalid API key"}`, http.StatusUnauthorized)
+		return
+	}
+	name := "Sleman"
+	if q := r.URL.Query().Get("q"); q != "" {
+		name = q
+	}
+	writeJSON(w, map[string]any{
+		"name":     name,
+		"dt":       Timestamp.Unix(),
+		"timezone": 7 * 60 * 60,
+		"sys": map[string]any{
+			"country": "ID",
+			"sunrise": time.Date(2025, 5, 1, 5, 31, 0, 0, ForecastZone).Unix(),
+			"sunset":  time.Date(2025, 5, 1, 17, 24, 0, 0, ForecastZone).Unix(),
+		},
+		"main": map[string]any{"temp": 27.5, "feels_like": 30.1, "humidity": 78},
+		"wind": map[string]any{"speed": 2.5, "deg": 180},
+		"weather": []map[string]any{
+			{"description": "awan tersebar"},
+		},
+	})
+}
+
+// ForecastZone adalah zona waktu lokasi cuaca palsu (WIB). Prakiraan
+// dimulai pada Timestamp; besoknya (2 Mei) hujan ringan pukul 13.00–17.59.
+var ForecastZone = time.FixedZone("WIB", 7*60*60)
+
+// forecastHour adalah prakiraan palsu h jam setelah Timestamp.
+func forecastHour(h int) (t time.Time, tempC float64, pop float64, condition string) {
+	t = Timestamp.Add(time.Duration(h) * time.Hour).In(ForecastZone)
+	switch hour := t.Hour(); {
+	case t.Day() == 2 && hour >= 13 && hour < 18:
+		return t, 25, 0.8, "hujan ringan"
+	case hour >= 6 && hour < 18:
+		return t, 30, 0.1, "awan tersebar"
+	default:
+		return t, 24, 0, "cerah"
+	}
+}
+
+// openWeatherMapForecast melayani /data/2.5/forecast: entri per 3 jam
+// sebanyak cnt (default 40).
+func (s *Server) openWeatherMapForecast(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("appid") == "" {
+		http.Error(w, `{"cod":401,"message":"Invalid API key"}`, http.StatusUnauthorized)
+		return
+	}
+	name := "Sleman"
+	if q := r.URL.Query().Get("q"); q != "" {
+		name = q
+	}
+	cnt := 40
+	if n, err := strconv.Atoi(r.URL.Query().Get("cnt")); err == nil && n > 0 && n < cnt {
+		cnt = n
+	}
+
+	list := []map[string]any{}
+	for i := 0; i < cnt; i++ {
+		t, temp, pop, condition := forecastHour(i * 3)
+		list = append(list, map[string]any{
+			"dt":      t.Unix(),
+			"main":    map[string]any{"temp": temp},
+			"weather": []map[string]any{{"description": condition}},
+			"wind":    map[string]any{"speed": 2.5, "deg": 180},
+			"pop":     pop,
+		})
+	}
+	writeJSON(w, map[string]any{
+		"list": list,
+		"city": map[string]any{"name": name, "timezone": 7 * 60 * 60},
+	})
+}
+
+// weatherAPIForecast melayani /v1/forecast.json: cuaca saat ini pada
+// Timestamp dan days hari (default 1) prakiraan, masing-masing dengan astro
+// dan 24 entri per jam mulai tengah malam lokal.
+func (s *Server) weatherAPIForecast(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("key") == "" {
+		http.Error(w, `{"error":{"code":1002,"message":"API key is invalid or not provided."}}`, http.StatusUnauthorized)
+		return
+	}
+	name := "Sleman"
+	if q := r.URL.Query().Get("q"); q != "" && !strings.Contains(q, ",") {
+		name = q
+	}
+	days := 1
+	if n, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && n > 0 {
+		days = n
+	}
+
+	// Jam pertama Timestamp adalah 07.00 WIB, jadi hari pertama mulai 7 jam sebelumnya
+	forecastDays := []map[string]any{}
+	for d := 0; d < days; d++ {
+		hours := []map[string]any{}
+		day := map[string]any{"maxtemp_c": 0.0, "mintemp_c": 100.0, "maxwind_kph": 9.0, "daily_chance_of_rain": 0}
+		var date, condition string
+		for h := 0; h < 24; h++ {
+			t, temp, pop, cond := forecastHour(d*24 + h - 7)
+			date = t.Format("2006-01-02")
+			chance := int(pop * 100)
+			hours = append(hours, map[string]any{
+				"time_epoch":     t.Unix(),
+				"time":           t.Format("2006-01-02 15:04"),
+				"temp_c":         temp,
+				"wind_kph":       9.0,
+				"wind_degree":    180,
+				"chance_of_rain": chance,
+				"condition":      map[string]any{"text": cond},
+			})
+			day["maxtemp_c"] = max(day["maxtemp_c"].(float64), temp)
+			day["mintemp_c"] = min(day["mintemp_c"].(float64), temp)
+			if chance >= day["daily_chance_of_rain"].(int) {
+				day["daily_chance_of_rain"] = chance
+				condition = cond
+			}
+		}
+		day["condition"] = map[string]any{"text": condition}
+		forecastDays = append(forecastDays, map[string]any{
+			"date":  date,
+			"day":   day,
+			"astro": map[string]any{"sunrise": "05:31 AM", "sunset": "05:24 PM"},
+			"hour":  hours,
+		})
+	}
+	writeJSON(w, map[string]any{
+		"location": map[string]any{
+			"name":            name,
+			"region":          "Yogyakarta",
+			"country":         "Indonesia",
+			"tz_id":           "Asia/Jakarta",
+			"localtime_epoch": Timestamp.Unix(),
+			"localtime":       Timestamp.In(ForecastZone).Format("2006-01-02 15:04"),
+		},
+		"current": map[string]any{
+			"temp_c":      27.5,
+			"feelslike_c": 30.1,
+			"condition":   map[string]any{"text": "Partly cloudy"},
+			"humidity":    78,
+			"wind_kph":    9.0,
+			"wind_degree": 180,
+		},
+		"forecast": map[string]any{"forecastday": forecastDays},
+	})
+}
+
+// Place adalah satu tempat di data palsu Places API.
+type Place struct {
+	ID              string
+	Name            string
+	Address         string
+	PriceLevel      string
+	Latitude        float64
+	Longitude       float64
+	Rating          float64
+	UserRatingCount int
+	Type            string
+	OpenNow         bool
+	Phone           string
+	Website         string
+	OpeningHours    []string
+	Reviews         []Review
+}
+
+// Review adalah satu ulasan di data palsu Place Details.
+type Review struct {
+	Author   string
+	PhotoURI string
+	Rating   float64
+	Text     string
+}
+
+// Places adalah data tempat yang dikembalikan searchText dan searchNearby.
+var Places = []Place{
+	{
+		ID:              "fake-place-klotok",
+		Name:            "Warung Kopi Klotok",
+		Address:         "Jl. Kaliurang KM 16, Sleman, Yogyakarta",
+		PriceLevel:      "PRICE_LEVEL_INEXPENSIVE",
+		Latitude:        -7.6458,
+		Longitude:       110.4275,
+		Rating:          4.6,
+		UserRatingCount: 25110,
+		Type:            "restaurant",
+		OpenNow:         true,
+		Phone:           "+62 812-2717-2888",
+		OpeningHours: []string{
+			"Monday: 7:00 AM – 10:00 PM",
+			"Tuesday: 7:00 AM – 10:00 PM",
+			"Wednesday: 7:00 AM – 10:00 PM",
+			"Thursday: 7:00 AM – 10:00 PM",
+			"Friday: 7:00 AM – 10:00 PM",
+			"Saturday: 7:00 AM – 10:00 PM",
+			"Sunday: 7:00 AM – 10:00 PM",
+		},
+		Reviews: []Review{
+			{Author: "Budi Santoso", PhotoURI: "https://lh3.googleusercontent.com/a/fake-budi", Rating: 5, Text: "Sayur lodeh dan pisang gorengnya juara, suasananya asri."},
+			{Author: "Rina Wulandari", PhotoURI: "https://lh3.googleusercontent.com/a/fake-rina", Rating: 4, Text: "Antre cukup panjang saat akhir pekan, tapi sepadan."},
+			{Author: "Ahmad Fauzi", Rating: 5, Text: "Kopi klotoknya mantap."},
+			{Author: "Dewi Lestari", PhotoURI: "https://lh3.googleusercontent.com/a/fake-dewi", Rating: 4, Text: "Harga terjangkau, parkir luas."},
+		},
+	},
+	{
+		ID:              "fake-place-mang-engking",
+		Name:            "Mang Engking",
+		Address:         "Jl. Godean, Sleman, Yogyakarta",
+		PriceLevel:      "PRICE_LEVEL_MODERATE",
+		Latitude:        -7.7760,
+		Longitude:       110.3370,
+		Rating:          4.5,
+		UserRatingCount: 9876,
+		Type:            "restaurant",
+		OpenNow:         false,
+	},
+	{
+		ID:              "fake-place-merapi-cafe",
+		Name:            "Merapi View Cafe",
+		Address:         "Jl. Kaliurang KM 22, Sleman, Yogyakarta",
+		PriceLevel:      "PRICE_LEVEL_EXPENSIVE",
+		Latitude:        -7.6000,
+		Longitude:       110.4230,
+		Rating:          4.2,
+		UserRatingCount: 1520,
+		Type:            "cafe",
+		OpenNow:         true,
+		Website:         "https://merapiviewcafe.example",
+		Reviews: []Review{
+			{Author: "Sari Indah", PhotoURI: "https://lh3.googleusercontent.com/a/fake-sari", Rating: 4, Text: "Pemandangan Merapi bagus di pagi hari."},
+		},
+	},
+}
+
+func (p Place) json() map[string]any {
+	return map[string]any{
+		"id":                  p.ID,
+		"displayName":         map[string]any{"text": p.Name},
+		"formattedAddress":    p.Address,
+		"priceLevel":          p.PriceLevel,
+		"location":            map[string]any{"latitude": p.Latitude, "longitude": p.Longitude},
+		"rating":              p.Rating,
+		"userRatingCount":     p.UserRatingCount,
+		"googleMapsUri":       "https://maps.google.com/?q=place_id:" + p.ID,
+		"primaryType":         p.Type,
+		"currentOpeningHours": map[string]any{"openNow": p.OpenNow},
+	}
+}
+
+// detailsJSON adalah json ditambah field Place Details.
+func (p Place) detailsJSON() map[string]any {
+	place := p.json()
+	delete(place, "priceLevel")
+	delete(place, "primaryType")
+	place["currentOpeningHours"] = map[string]any{"openNow": p.OpenNow, "weekdayDescriptions": p.OpeningHours}
+	if p.Phone != "" {
+		place["internationalPhoneNumber"] = p.Phone
+	}
+	if p.Website != "" {
+		place["websiteUri"] = p.Website
+	}
+	reviews := []map[string]any{}
+	for _, r := range p.Reviews {
+		reviews = append(reviews, map[string]any{
+			"rating":                         r.Rating,
+			"text":                           map[string]any{"text": r.Text, "languageCode": "id"},
+			"relativePublishTimeDescription": "a month ago",
+			"authorAttribution": map[string]any{
+				"displayName": r.Author,
+				"uri":         "https://www.google.com/maps/contrib/" + strings.ToLower(strings.ReplaceAll(r.Author, " ", "-")),
+				"photoUri":    r.PhotoURI,
+			},
+		})
+	}
+	place["reviews"] = reviews
+	return place
+}
+
+// placesFilter adalah filter yang dipahami searchText dan searchNearby palsu.
+type placesFilter struct {
+	TextQuery     string   `json:"textQuery"`
+	IncludedType  string   `json:"includedType"`
+	IncludedTypes []string `json:"includedTypes"`
+	MinRating     float64  `json:"minRating"`
+	OpenNow       bool     `json:"openNow"`
+	PriceLevels   []string `json:"priceLevels"`
+}
+
+func (f placesFilter) match(p Place) bool {
+	types := f.IncludedTypes
+	if f.IncludedType != "" {
+		types = append(types, f.IncludedType)
+	}
+	if len(types) > 0 && !contains(types, p.Type) {
+		return false
+	}
+	if p.Rating < f.MinRating || (f.OpenNow && !p.OpenNow) {
+		return false
+	}
+	return len(f.PriceLevels) == 0 || contains(f.PriceLevels, p.PriceLevel)
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// places melayani searchText (textQuery wajib) dan searchNearby.
+func (s *Server) places(requireText bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get("X-Goog-Api-Key") == "" {
+			http.Error(w, `{"error":{"code":403,"message":"API key missing"}}`, http.StatusForbidden)
+			return
+		}
+		var filter placesFilter
+		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil || (requireText && filter.TextQuery == "") {
+			http.Error(w, `{"error":{"code":400,"message":"textQuery is required"}}`, http.StatusBadRequest)
+			return
+		}
+
+		places := []map[string]any{}
+		for _, p := range Places {
+			if filter.match(p) {
+				places = append(places, p.json())
+			}
+		}
+		writeJSON(w, map[string]any{"places": places})
+	}
+}
+
+// placeDetails melayani Place Details GET /v1/places/{id}.
+func (s *Server) placeDetails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Header.Get("X-Goog-Api-Key") == "" {
+		http.Error(w, `{"error":{"code":403,"message":"API key missing"}}`, http.StatusForbidden)
+		return
+	}
+	id := r.PathValue("id")
+	for _, p := range Places {
+		if p.ID == id {
+			writeJSON(w, p.detailsJSON())
+			return
+		}
+	}
+	http.Error(w, `{"error":{"code":404,"message":"place not found"}}`, http.StatusNotFound)
+}
+
+// Geocode adalah satu lokasi di data palsu geocoding.
+type Geocode struct {
+	Name      string
+	Address   string
+	Latitude  float64
+	Longitude float64
+}
+
+// Geocodes adalah lokasi yang dikenali geocoding palsu. Forward geocoding
+// mencocokkan query (tanpa membedakan huruf besar) dengan awal Name; reverse
+// geocoding mengembalikan lokasi terdekat.
+var Geocodes = []Geocode{
+	{Name: "Malioboro", Address: "Jl. Malioboro, Sosromenduran, Gedong Tengen, Kota Yogyakarta, Daerah Istimewa Yogyakarta, Indonesia", Latitude: -7.7926, Longitude: 110.3658},
+	{Name: "Yogyakarta", Address: "Kota Yogyakarta, Daerah Istimewa Yogyakarta, Indonesia", Latitude: -7.7956, Longitude: 110.3695},
+	{Name: "Sleman", Address: "Kabupaten Sleman, Daerah Istimewa Yogyakarta, Indonesia", Latitude: -7.7167, Longitude: 110.3500},
+	{Name: "Getasan", Address: "Getasan, Kabupaten Semarang, Jawa Tengah, Indonesia", Latitude: -7.3833, Longitude: 110.4500},
+}
+
+// findGeocodes mengembalikan Geocodes yang cocok dengan query.
+func findGeocodes(query string) []Geocode {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var found []Geocode
+	for _, g := range Geocodes {
+		if strings.HasPrefix(query, strings.ToLower(g.Name)) {
+			found = append(found, g)
+		}
+	}
+	return found
+}
+
+// nearestGeocode mengembalikan Geocodes yang paling dekat dengan c.
+func nearestGeocode(c providers.Coordinates) Geocode {
+	nearest := Geocodes[0]
+	for _, g := range Geocodes[1:] {
+		if providers.DistanceKm(c, providers.Coordinates{Latitude: g.Latitude, Longitude: g.Longitude}) <
+			providers.DistanceKm(c, providers.Coordinates{Latitude: nearest.Latitude, Longitude: nearest.Longitude}) {
+			nearest = g
+		}
+	}
+	return nearest
+}
+
+// googleGeocoding melayani Google Geocoding API (address= atau latlng=).
+func (s *Server) googleGeocoding(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("key") == "" {
+		writeJSON(w, map[string]any{"status": "REQUEST_DENIED", "error_message": "You must use an API key.", "results": []any{}})
+		return
+	}
+
+	var found []Geocode
+	switch {
+	case query.Get("address") != "":
+		found = findGeocodes(query.Get("address"))
+	case query.Get("latlng") != "":
+		lat, lon, _ := strings.Cut(query.Get("latlng"), ",")
+		c, err := providers.ParseCoordinates(lat, lon)
+		if err != nil {
+			writeJSON(w, map[string]any{"status": "INVALID_REQUEST", "results": []any{}})
+			return
+		}
+		found = []Geocode{nearestGeocode(c)}
+	default:
+		writeJSON(w, map[string]any{"status": "INVALID_REQUEST", "results": []any{}})
+		return
+	}
+
+	results := []map[string]any{}
+	for _, g := range found {
+		results = append(results, map[string]any{
+			"formatted_address": g.Address,
+			"address_components": []map[string]any{
+				{"long_name": g.Name, "short_name": g.Name, "types": []string{"locality", "political"}},
+				{"long_name": "Indonesia", "short_name": "ID", "types": []string{"country", "political"}},
+			},
+			"geometry": map[string]any{"location": map[string]any{"lat": g.Latitude, "lng": g.Longitude}},
+		})
+	}
+	status := "OK"
+	if len(results) == 0 {
+		status = "ZERO_RESULTS"
+	}
+	writeJSON(w, map[string]any{"status": status, "results": results})
+}
+
+// openWeatherMapGeocoding melayani /geo/1.0/direct (q=) dan /geo/1.0/reverse
+// (lat= dan lon=).
+func (s *Server) openWeatherMapGeocoding(reverse bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("appid") == "" {
+			http.Error(w, `{"cod":401,"message":"Invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+
+		found := findGeocodes(query.Get("q"))
+		if reverse {
+			c, err := providers.ParseCoordinates(query.Get("lat"), query.Get("lon"))
+			if err != nil {
+				http.Error(w, `{"cod":"400","message":"wrong latitude"}`, http.StatusBadRequest)
+				return
+			}
+			found = []Geocode{nearestGeocode(c)}
+		}
+
+		results := []map[string]any{}
+		for _, g := range found {
+			results = append(results, map[string]any{
+				"name":    g.Name,
+				"lat":     g.Latitude,
+				"lon":     g.Longitude,
+				"country": "ID",
+			})
+		}
+		writeJSON(w, results)
+	}
+}
+
+// lookupRate mencari kurs from→to di Rates, langsung, terbalik atau lewat USD.
+func lookupRate(from, to string) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	if rate, ok := Rates[from+"/"+to]; ok {
+		return rate, true
+	}
+	if rate, ok := Rates[to+"/"+from]; ok {
+		return 1 / rate, true
+	}
+	fromUSD, ok1 := lookupDirect("USD", from)
+	toUSD, ok2 := lookupDirect("USD", to)
+	if ok1 && ok2 {
+		return toUSD / fromUSD, true
+	}
+	return 0, false
+}
+
+func lookupDirect(from, to string) (float64, bool) {
+	if from == to {
+		return 1, true
+	}
+	rate, ok := Rates[from+"/"+to]
+	return rate, ok
+}
+
+func (s *Server) convert(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("access_key") == "" {
+		writeJSON(w, map[string]any{
+			"success": false,
+			"error":   map[string]any{"type": "missing_access_key", "info": "You have not supplied an API Access Key."},
+		})
+		return
+	}
+	from, to := strings.ToUpper(q.Get("from")), strings.ToUpper(q.Get("to"))
+	rate, ok := lookupRate(from, to)
+	if !ok {
+		writeJSON(w, map[string]any{
+			"success": false,
+			"error":   map[string]any{"type": "invalid_currency_codes", "info": "You have provided one or more invalid Currency Codes."},
+		})
+		return
+	}
+	amount := 1.0
+	if a := q.Get("amount"); a != "" {
+		fmt.Sscanf(a, "%g", &amount)
+	}
+	timestamp := Timestamp
+	resp := map[string]any{
+		"success": true,
+		"query":   map[string]any{"from": from, "to": to, "amount": amount},
+	}
+	if d := q.Get("date"); d != "" {
+		date, err := time.Parse(time.DateOnly, d)
+		if err != nil {
+			writeJSON(w, map[string]any{
+				"success": false,
+				"error":   map[string]any{"type": "invalid_date", "info": "You have entered an invalid date."},
+			})
+			return
+		}
+		rate = HistoricalRate(rate, date)
+		timestamp = date
+		resp["historical"] = true
+		resp["date"] = d
+	}
+	resp["info"] = map[string]any{"timestamp": timestamp.Unix(), "quote": rate}
+	resp["result"] = amount * rate
+	writeJSON(w, resp)
+}
+
+// HistoricalRate adalah kurs palsu pada date untuk kurs dasar rate: rate
+// digeser -3% sampai +3% mengikuti hari dalam minggu (Senin terendah, Kamis
+// sama dengan rate, Minggu tertinggi), jadi riwayat seminggu punya min, max
+// dan rata-rata yang bisa ditebak.
+func HistoricalRate(rate float64, date time.Time) float64 {
+	offset := (int(date.Weekday())+6)%7 - 3 // Senin -3 ... Minggu +3
+	return rate * (1 + float64(offset)/100)
+}
+
+func (s *Server) timeframe(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("access_key") == "" {
+		writeJSON(w, map[string]any{
+			"success": false,
+			"error":   map[string]any{"type": "missing_access_key", "info": "You have not supplied an API Access Key."},
+		})
+		return
+	}
+	source := strings.ToUpper(q.Get("source"))
+	start, err1 := time.Parse(time.DateOnly, q.Get("start_date"))
+	end, err2 := time.Parse(time.DateOnly, q.Get("end_date"))
+	if err1 != nil || err2 != nil || end.Before(start) {
+		writeJSON(w, map[string]any{
+			"success": false,
+			"error":   map[string]any{"type": "invalid_timeframe", "info": "You have specified an invalid time frame."},
+		})
+		return
+	}
+	quotes := map[string]map[string]float64{}
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		day := map[string]float64{}
+		for _, to := range strings.Split(strings.ToUpper(q.Get("currencies")), ",") {
+			if rate, ok := lookupRate(source, to); ok {
+				day[source+to] = HistoricalRate(rate, date)
+			}
+		}
+		quotes[date.Format(time.DateOnly)] = day
+	}
+	writeJSON(w, map[string]any{
+		"success":    true,
+		"timeframe":  true,
+		"start_date": q.Get("start_date"),
+		"end_date":   q.Get("end_date"),
+		"source":     source,
+		"quotes":     quotes,
+	})
+}
+
+func (s *Server) customSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	writeJSON(w, map[string]any{
+		"items": []map[string]any{
+			{
+				"title":   "Hasil pencarian untuk " + q,
+				"link":    "https://example.com/search?q=" + url.QueryEscape(q),
+				"snippet": "Cuplikan hasil pencarian palsu untuk " + q + ".",
+			},
+		},
+	})
+}
+
+func (s *Server) textToSpeech(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"audioContent": base64.StdEncoding.EncodeToString([]byte("fake-mp3")),
+	})
+}
+
+// generateContent melayani .../models/{model}:generateContent, baik path
+// Vertex AI maupun Gemini API.
+func (s *Server) generateContent(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, ":generateContent") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	var resp *genai.GenerateContentResponse
+	if len(s.responses) > 0 {
+		resp, s.responses = s.responses[0], s.responses[1:]
+	}
+	s.mu.Unlock()
+
+	if resp == nil {
+		resp = TextResponse("OK")
+	}
+	writeJSON(w, resp)
+}
@@ -0,0 +1,296 @@
+package fakes
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/genai"
+)
+
+// LivePath adalah path WebSocket BidiGenerateContent yang dilayani LiveServer,
+// sama dengan path Vertex AI Live API.
+const LivePath = "/ws/google.cloud.aiplatform.v1beta1.LlmBidiService/BidiGenerateContent"
+
+// LiveStep adalah satu langkah dalam giliran model yang diputar LiveServer.
+// Buat dengan LiveText, LiveToolCall, LiveRaw atau LiveClose.
+type LiveStep struct {
+	text      string
+	calls     []*genai.FunctionCall
+	raw       string
+	closeCode int
+	closeText string
+}
+
+// LiveText mengirim serverContent.modelTurn berisi satu part teks. Beberapa
+// LiveText berturut-turut meniru jawaban yang di-stream.
+func LiveText(text string) LiveStep {
+	return LiveStep{text: text}
+}
+
+// LiveToolCall mengirim pesan toolCall lalu menunggu toolResponse dari
+// client sebelum lanjut ke langkah berikutnya.
+func LiveToolCall(calls ...*genai.FunctionCall) LiveStep {
+	return LiveStep{calls: calls}
+}
+
+// LiveRaw mengirim frame apa adanya, misalnya JSON rusak atau pesan error.
+func LiveRaw(frame string) LiveStep {
+	return LiveStep{raw: frame}
+}
+
+// LiveClose menutup koneksi dengan close code dan alasan, meniru putusnya
+// koneksi ke Live API di tengah giliran.
+func LiveClose(code int, reason string) LiveStep {
+	return LiveStep{closeCode: code, closeText: reason}
+}
+
+// LiveServer meniru endpoint WebSocket BidiGenerateContent Vertex AI Live.
+// Setiap koneksi harus diawali pesan setup yang dijawab setupComplete. Setiap
+// giliran client (clientContent dengan turnComplete atau realtimeInput
+// endOfStream) memutar giliran berikutnya dari QueueTurn, diakhiri
+// generationComplete dan turnComplete. Jika antrean kosong model menjawab "OK".
+type LiveServer struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	turns         [][]LiveStep
+	setups        [][]byte
+	messages      [][]byte
+	toolResponses []*genai.FunctionResponse
+	connections   int
+	headers       []http.Header
+}
+
+// NewLiveServer menjalankan LiveServer. Panggil Close setelah selesai.
+func NewLiveServer() *LiveServer {
+	s := &LiveServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivePath, s.serve)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// URL mengembalikan URL ws:// untuk LivePath, pengganti SERVICE_URL.
+func (s *LiveServer) URL() string {
+	return "ws" + strings.TrimPrefix(s.Server.URL, "http") + LivePath
+}
+
+// QueueTurn menambahkan satu giliran model yang diputar pada giliran client
+// berikutnya.
+func (s *LiveServer) QueueTurn(steps ...LiveStep) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.turns = append(s.turns, steps)
+}
+
+// Setups mengembalikan semua pesan setup yang diterima, satu per koneksi.
+func (s *LiveServer) Setups() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.setups...)
+}
+
+// Messages mengembalikan semua pesan client setelah setup, termasuk
+// toolResponse.
+func (s *LiveServer) Messages() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.messages...)
+}
+
+// ToolResponses mengembalikan semua functionResponse yang dikirim client.
+func (s *LiveServer) ToolResponses() []*genai.FunctionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*genai.FunctionResponse(nil), s.toolResponses...)
+}
+
+// Connections mengembalikan jumlah koneksi yang pernah dibuka, berguna untuk
+// memeriksa reconnect.
+func (s *LiveServer) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// Headers mengembalikan header handshake setiap koneksi, misalnya untuk
+// memeriksa Authorization.
+func (s *LiveServer) Headers() []http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]http.Header(nil), s.headers...)
+}
+
+// liveClientMessage adalah bagian pesan client yang diperhatikan LiveServer.
+type liveClientMessage struct {
+	Setup         json.RawMessage `json:"setup"`
+	ClientContent *struct {
+		TurnComplete bool `json:"turnComplete"`
+	} `json:"clientContent"`
+	ClientContentSnake *struct {
+		TurnComplete bool `json:"turn_complete"`
+	} `json:"client_content"`
+	RealtimeInput *struct {
+		EndOfStream bool `json:"endOfStream"`
+	} `json:"realtimeInput"`
+	ToolResponse *struct {
+		FunctionResponses []*genai.FunctionResponse `json:"functionResponses"`
+	} `json:"toolResponse"`
+}
+
+// endsTurn melaporkan apakah pesan menutup giliran client.
+func (m *liveClientMessage) endsTurn() bool {
+	switch {
+	case m.ClientContent != nil:
+		return m.ClientContent.TurnComplete
+	case m.ClientContentSnake != nil:
+		return m.ClientContentSnake.TurnComplete
+	case m.RealtimeInput != nil:
+		return m.RealtimeInput.EndOfStream
+	}
+	return false
+}
+
+var liveUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func (s *LiveServer) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("fakes: gagal upgrade WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.connections++
+	s.headers = append(s.headers, r.Header.Clone())
+	s.mu.Unlock()
+
+	// Pesan pertama wajib setup
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return
+	}
+	var first liveClientMessage
+	if err := json.Unmarshal(data, &first); err != nil || first.Setup == nil {
+		conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "setup must be the first message"))
+		return
+	}
+	s.mu.Lock()
+	s.setups = append(s.setups, data)
+	s.mu.Unlock()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"setupComplete":{}}`)); err != nil {
+		return
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.messages = append(s.messages, data)
+		s.mu.Unlock()
+
+		var msg liveClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			conn.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseUnsupportedData, "invalid JSON"))
+			return
+		}
+		if msg.ToolResponse != nil {
+			// toolResponse di luar LiveToolCall diabaikan
+			s.recordToolResponse(msg.ToolResponse.FunctionResponses)
+			continue
+		}
+		if !msg.endsTurn() {
+			continue
+		}
+		if !s.playTurn(conn) {
+			return
+		}
+	}
+}
+
+func (s *LiveServer) recordToolResponse(responses []*genai.FunctionResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.toolResponses = append(s.toolResponses, responses...)
+}
+
+// playTurn memutar giliran berikutnya dari antrean. Hasil false berarti
+// koneksi sudah ditutup.
+func (s *LiveServer) playTurn(conn *websocket.Conn) bool {
+	s.mu.Lock()
+	steps := []LiveStep{LiveText("OK")}
+	if len(s.turns) > 0 {
+		steps, s.turns = s.turns[0], s.turns[1:]
+	}
+	s.mu.Unlock()
+
+	for _, step := range steps {
+		switch {
+		case step.closeCode != 0:
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(step.closeCode, step.closeText))
+			return false
+		case step.raw != "":
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(step.raw)); err != nil {
+				return false
+			}
+		case len(step.calls) > 0:
+			if err := conn.WriteJSON(map[string]any{
+				"toolCall": map[string]any{"functionCalls": step.calls},
+			}); err != nil {
+				return false
+			}
+			if !s.awaitToolResponse(conn) {
+				return false
+			}
+		default:
+			if err := conn.WriteJSON(map[string]any{
+				"serverContent": map[string]any{
+					"modelTurn": &genai.Content{Role: "model", Parts: []*genai.Part{{Text: step.text}}},
+				},
+			}); err != nil {
+				return false
+			}
+		}
+	}
+
+	for _, frame := range []string{
+		`{"serverContent":{"generationComplete":true}}`,
+		`{"serverContent":{"turnComplete":true}}`,
+	} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// awaitToolResponse membaca pesan client sampai toolResponse diterima.
+func (s *LiveServer) awaitToolResponse(conn *websocket.Conn) bool {
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return false
+		}
+		s.mu.Lock()
+		s.messages = append(s.messages, data)
+		s.mu.Unlock()
+
+		var msg liveClientMessage
+		if err := json.Unmarshal(data, &msg); err == nil && msg.ToolResponse != nil {
+			s.recordToolResponse(msg.ToolResponse.FunctionResponses)
+			return true
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package intent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	genai "google.golang.org/genai"
+)
+
+// Rule mengenali intent dari kata kunci. Rule cocok jika teks memuat salah
+// satu Keywords, salah satu Require (jika diisi), dan tidak satu pun
+// Exclude. Kata kunci dicocokkan pada teks huruf kecil yang tanda bacanya
+// diganti spasi dan diapit spasi, jadi " di " hanya cocok dengan kata "di".
+type Rule struct {
+	Intent   string
+	Keywords []string
+	Require  []string
+	Exclude  []string
+	// Vocabulary, jika diisi, membuat rule hanya cocok jika setiap kata teks
+	// ada di Keywords, Require atau Vocabulary. Kata lain, misal nama kota,
+	// membuat rule tidak cocok.
+	Vocabulary []string
+	Confidence float64
+}
+
+func (rule Rule) match(text string) bool {
+	return containsAny(text, rule.Keywords) &&
+		(len(rule.Require) == 0 || containsAny(text, rule.Require)) &&
+		!containsAny(text, rule.Exclude) &&
+		(len(rule.Vocabulary) == 0 || rule.onlyVocabulary(text))
+}
+
+// onlyVocabulary melaporkan apakah text tinggal spasi setelah semua frasa
+// Keywords, Require dan Vocabulary dibuang. Frasa dibuang sebagai kata utuh,
+// yang terpanjang lebih dulu supaya "hari ini" tidak terpotong oleh "ini".
+func (rule Rule) onlyVocabulary(text string) bool {
+	var phrases []string
+	for _, list := range [][]string{rule.Keywords, rule.Require, rule.Vocabulary} {
+		for _, phrase := range list {
+			if phrase = strings.TrimSpace(phrase); phrase != "" {
+				phrases = append(phrases, phrase)
+			}
+		}
+	}
+	sort.Slice(phrases, func(i, j int) bool { return len(phrases[i]) > len(phrases[j]) })
+	for _, phrase := range phrases {
+		for strings.Contains(text, " "+phrase+" ") {
+			text = strings.ReplaceAll(text, " "+phrase+" ", " ")
+		}
+	}
+	return strings.TrimSpace(text) == ""
+}
+
+// Rules adalah Classifier berbasis Rule. Untuk setiap intent dipakai
+// confidence tertinggi dari rule yang cocok.
+type Rules struct {
+	Label string
+	Rules []Rule
+}
+
+func (c *Rules) Name() string { return c.Label }
+
+func (c *Rules) Classify(_ context.Context, text string) ([]Match, error) {
+	text = normalizeText(text)
+	var matches []Match
+	seen := make(map[string]int)
+	for _, rule := range c.Rules {
+		if !rule.match(text) {
+			continue
+		}
+		if i, ok := seen[rule.Intent]; ok {
+			matches[i].Confidence = math.Max(matches[i].Confidence, rule.Confidence)
+			continue
+		}
+		seen[rule.Intent] = len(matches)
+		matches = append(matches, Match{Intent: rule.Intent, Confidence: rule.Confidence})
+	}
+	return matches, nil
+}
+
+// Extractor adalah Classifier yang mengekstrak data terstruktur dari teks,
+// misal currency.Parse. Extract mengembalikan ok false jika teks tidak
+// cocok; data yang diekstrak disimpan di Match.Data.
+type Extractor struct {
+	Label      string
+	Intent     string
+	Confidence float64
+	Extract    func(text string) (data any, ok bool)
+}
+
+func (c *Extractor) Name() string { return c.Label }
+
+func (c *Extractor) Classify(_ context.Context, text string) ([]Match, error) {
+	data, ok := c.Extract(text)
+	if !ok {
+		return nil, nil
+	}
+	return []Match{{Intent: c.Intent, Confidence: c.Confidence, Data: data}}, nil
+}
+
+// Embedder mengubah teks menjadi vektor embedding, satu per teks.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Embedding adalah Classifier yang membandingkan embedding teks dengan contoh
+// kalimat per intent. Confidence adalah cosine similarity tertinggi dengan
+// contoh intent tersebut. Embedding contoh dihitung sekali saat Classify
+// pertama; jika gagal, dicoba lagi pada Classify berikutnya.
+type Embedding struct {
+	Label    string
+	Embedder Embedder
+	// Examples adalah contoh kalimat per intent.
+	Examples map[string][]string
+
+	mu       sync.Mutex
+	intents  []string
+	examples [][]float32
+}
+
+func (c *Embedding) Name() string { return c.Label }
+
+func (c *Embedding) Classify(ctx context.Context, text string) ([]Match, error) {
+	intents, examples, err := c.exampleVectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := c.Embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder mengembalikan %d vektor untuk 1 teks", len(vectors))
+	}
+
+	var matches []Match
+	seen := make(map[string]int)
+	for i, example := range examples {
+		similarity := cosine(vectors[0], example)
+		if j, ok := seen[intents[i]]; ok {
+			matches[j].Confidence = math.Max(matches[j].Confidence, similarity)
+			continue
+		}
+		seen[intents[i]] = len(matches)
+		matches = append(matches, Match{Intent: intents[i], Confidence: similarity})
+	}
+	return matches, nil
+}
+
+func (c *Embedding) exampleVectors(ctx context.Context) ([]string, [][]float32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.examples != nil {
+		return c.intents, c.examples, nil
+	}
+	var intents, texts []string
+	for intent, examples := range c.Examples {
+		for _, example := range examples {
+			intents = append(intents, intent)
+			texts = append(texts, example)
+		}
+	}
+	vectors, err := c.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error embedding contoh intent: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, nil, fmt.Errorf("embedder mengembalikan %d vektor untuk %d contoh", len(vectors), len(texts))
+	}
+	c.intents, c.examples = intents, vectors
+	return intents, vectors, nil
+}
+
+// ModelsEmbedder adalah Embedder lewat genai.Models.EmbedContent, misal
+// dengan model "text-embedding-005".
+type ModelsEmbedder struct {
+	Models *genai.Models
+	Model  string
+}
+
+func (e *ModelsEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	contents := make([]*genai.Content, len(texts))
+	for i, text := range texts {
+		contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+	}
+	resp, err := e.Models.EmbedContent(ctx, e.Model, contents, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed content: %w", err)
+	}
+	vectors := make([][]float32, len(resp.Embeddings))
+	for i, embedding := range resp.Embeddings {
+		vectors[i] = embedding.Values
+	}
+	return vectors, nil
+}
+
+// cosine menghitung cosine similarity a dan b, atau 0 jika panjangnya beda
+// atau salah satunya nol.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// normalizeText mengubah text ke huruf kecil, mengganti tanda baca dengan
+// spasi dan mengapitnya dengan spasi untuk pencocokan Rule.
+func normalizeText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) && r != '\'' {
+			return ' '
+		}
+		return unicode.ToLower(r)
+	}, text)
+	return " " + strings.Join(strings.Fields(text), " ") + " "
+}
+
+func containsAny(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+// Package intent mengklasifikasikan pesan teks pengguna sebelum dikirim ke
+// model, supaya pertanyaan sederhana bisa dijawab tanpa round trip LLM.
+// Beberapa Classifier (aturan kata kunci, extractor seperti currency.Parse,
+// dan kemiripan embedding opsional) memberi Match dengan confidence; Router
+// memilih Match terbaik dari classifier pertama yang lolos threshold lalu menerapkan
+// Policy intent tersebut: jawab langsung, ambil data tool lebih dulu untuk
+// dilampirkan ke giliran model, minta lokasi, atau serahkan ke model. Setiap
+// keputusan dicatat ke log supaya threshold bisa disetel.
+package intent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Action adalah keputusan Router untuk satu pesan.
+type Action string
+
+const (
+	// ActionDefer: pesan diteruskan ke model apa adanya.
+	ActionDefer Action = "defer"
+	// ActionAnswer: pesan dijawab langsung dengan Decision.Answer tanpa model.
+	ActionAnswer Action = "answer"
+	// ActionPrefetch: data tool di Decision.Prefetch dilampirkan ke pesan yang
+	// dikirim ke model, sehingga model tidak perlu memanggil tool itu lagi.
+	ActionPrefetch Action = "prefetch"
+	// ActionNeedLocation: intent membutuhkan lokasi pengguna yang belum ada.
+	ActionNeedLocation Action = "need_location"
+)
+
+// DefaultTimeout membatasi satu pemanggilan Policy.Answer atau
+// Policy.Prefetch jika Router.Timeout kosong.
+const DefaultTimeout = 10 * time.Second
+
+// Match adalah satu tebakan intent dari classifier.
+type Match struct {
+	Intent     string
+	Confidence float64 // 0 sampai 1
+	// Classifier adalah nama classifier yang menghasilkan Match, diisi oleh
+	// Router.
+	Classifier string
+	// Data adalah hasil ekstraksi classifier, misal *currency.Query.
+	Data any
+}
+
+// Classifier menebak intent dari teks pesan. Classify mengembalikan semua
+// intent yang dikenali beserta confidence-nya, atau nil jika tidak ada.
+type Classifier interface {
+	Name() string
+	Classify(ctx context.Context, text string) ([]Match, error)
+}
+
+// Request adalah pesan yang dirutekan.
+type Request struct {
+	Text string
+	// HasLocation true jika lokasi pengguna sudah diketahui.
+	HasLocation bool
+}
+
+// Answer adalah jawaban langsung untuk klien, dikirim sebagai
+// {"status": Status, "data": Data}.
+type Answer struct {
+	Status string
+	Data   any
+}
+
+// Prefetch adalah hasil tool yang dilampirkan ke giliran model.
+type Prefetch struct {
+	Tool string
+	Data any
+}
+
+// Policy menentukan apa yang dilakukan untuk satu intent. Answer dicoba lebih
+// dulu jika confidence >= AnswerAt, lalu Prefetch jika confidence >=
+// PrefetchAt; jika keduanya tidak berlaku atau gagal, pesan diserahkan ke
+// model.
+type Policy struct {
+	AnswerAt   float64
+	Answer     func(ctx context.Context, m Match) (*Answer, error)
+	PrefetchAt float64
+	Prefetch   func(ctx context.Context, m Match) (*Prefetch, error)
+	// NeedsLocation membuat Router mengembalikan ActionNeedLocation jika
+	// lokasi pengguna belum ada, apa pun confidence-nya.
+	NeedsLocation bool
+}
+
+// Decision adalah hasil Route.
+type Decision struct {
+	Action Action
+	// Match adalah intent terpilih, atau nil jika tidak ada yang lolos.
+	Match    *Match
+	Answer   *Answer
+	Prefetch *Prefetch
+	// Reason menjelaskan keputusan untuk log.
+	Reason string
+}
+
+type entry struct {
+	classifier Classifier
+	threshold  float64
+}
+
+// Router menjalankan classifier dan menerapkan Policy per intent. Buat dengan
+// NewRouter, daftarkan classifier dengan Use dan policy dengan Handle sebelum
+// Route dipanggil.
+type Router struct {
+	// Timeout membatasi satu Answer atau Prefetch; default DefaultTimeout.
+	Timeout time.Duration
+
+	classifiers []entry
+	policies    map[string]Policy
+}
+
+// NewRouter membuat Router kosong.
+func NewRouter() *Router {
+	return &Router{policies: make(map[string]Policy)}
+}
+
+// Use mendaftarkan c. Match dari c dengan confidence di bawah threshold
+// diabaikan. Classifier dijalankan berurutan sesuai pendaftaran dan Route
+// berhenti pada classifier pertama yang punya Match lolos threshold, jadi
+// daftarkan classifier yang paling tepat dan murah lebih dulu (misal
+// extractor, lalu rule, lalu embedding).
+func (r *Router) Use(c Classifier, threshold float64) {
+	r.classifiers = append(r.classifiers, entry{classifier: c, threshold: threshold})
+}
+
+// Handle memasang policy untuk intent. Intent tanpa policy selalu diserahkan
+// ke model.
+func (r *Router) Handle(intent string, policy Policy) {
+	r.policies[intent] = policy
+}
+
+// Route mengklasifikasikan req dan memutuskan tindakannya. Dari classifier
+// yang menang dipilih Match dengan confidence tertinggi. Error classifier,
+// Answer dan Prefetch tidak menggagalkan Route; pesan diserahkan ke model.
+func (r *Router) Route(ctx context.Context, req Request) Decision {
+	start := time.Now()
+	var best *Match
+	var candidates []string
+	for _, e := range r.classifiers {
+		matches, err := e.classifier.Classify(ctx, req.Text)
+		if err != nil {
+			log.Printf("Intent classifier %s error: %v", e.classifier.Name(), err)
+			continue
+		}
+		for _, m := range matches {
+			m.Classifier = e.classifier.Name()
+			candidates = append(candidates, fmt.Sprintf("%s/%s=%.2f", m.Classifier, m.Intent, m.Confidence))
+			if m.Confidence >= e.threshold && (best == nil || m.Confidence > best.Confidence) {
+				best = &m
+			}
+		}
+		if best != nil {
+			break
+		}
+	}
+
+	d := r.decide(ctx, req, best)
+	intent, classifier, confidence := "-", "-", 0.0
+	if best != nil {
+		intent, classifier, confidence = best.Intent, best.Classifier, best.Confidence
+	}
+	log.Printf("Intent router: text=%q intent=%s classifier=%s confidence=%.2f action=%s reason=%q candidates=[%s] took=%s",
+		req.Text, intent, classifier, confidence, d.Action, d.Reason, strings.Join(candidates, " "), time.Since(start).Round(time.Millisecond))
+	return d
+}
+
+func (r *Router) decide(ctx context.Context, req Request, best *Match) Decision {
+	if best == nil {
+		return Decision{Action: ActionDefer, Reason: "no intent above threshold"}
+	}
+	d := Decision{Action: ActionDefer, Match: best}
+	policy, ok := r.policies[best.Intent]
+	if !ok {
+		d.Reason = "no policy for intent"
+		return d
+	}
+	if policy.NeedsLocation && !req.HasLocation {
+		d.Action, d.Reason = ActionNeedLocation, "intent needs the user's location"
+		return d
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	var failures []string
+	if policy.Answer != nil && best.Confidence >= policy.AnswerAt {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		answer, err := policy.Answer(callCtx, *best)
+		cancel()
+		if err == nil {
+			d.Action, d.Answer, d.Reason = ActionAnswer, answer, "confidence above answer threshold"
+			return d
+		}
+		failures = append(failures, fmt.Sprintf("answer failed: %v", err))
+	}
+	if policy.Prefetch != nil && best.Confidence >= policy.PrefetchAt {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		prefetch, err := policy.Prefetch(callCtx, *best)
+		cancel()
+		if err == nil {
+			d.Action, d.Prefetch, d.Reason = ActionPrefetch, prefetch, "confidence above prefetch threshold"
+			return d
+		}
+		failures = append(failures, fmt.Sprintf("prefetch failed: %v", err))
+	}
+	d.Reason = "confidence below thresholds"
+	if len(failures) > 0 {
+		d.Reason = strings.Join(failures, "; ")
+	}
+	return d
+}
@@ -0,0 +1,258 @@
+package intent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubClassifier mengembalikan matches atau err tetap dan mencatat berapa
+// kali Classify dipanggil.
+type stubClassifier struct {
+	name    string
+	matches []Match
+	err     error
+	calls   int
+}
+
+func (c *stubClassifier) Name() string { return c.name }
+
+func (c *stubClassifier) Classify(context.Context, string) ([]Match, error) {
+	c.calls++
+	return c.matches, c.err
+}
+
+// stubEmbedder memetakan teks ke vektor tetap, atau gagal dengan err.
+type stubEmbedder struct {
+	vectors map[string][]float32
+	err     error
+	calls   int
+}
+
+func (e *stubEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i] = e.vectors[text]
+	}
+	return out, nil
+}
+
+func answerWith(status string) func(context.Context, Match) (*Answer, error) {
+	return func(context.Context, Match) (*Answer, error) {
+		return &Answer{Status: status}, nil
+	}
+}
+
+func prefetchWith(tool string) func(context.Context, Match) (*Prefetch, error) {
+	return func(context.Context, Match) (*Prefetch, error) {
+		return &Prefetch{Tool: tool}, nil
+	}
+}
+
+func TestRouterClassifierOrder(t *testing.T) {
+	first := &stubClassifier{name: "first", matches: []Match{{Intent: "a", Confidence: 0.6}, {Intent: "b", Confidence: 0.7}}}
+	second := &stubClassifier{name: "second", matches: []Match{{Intent: "c", Confidence: 1}}}
+	r := NewRouter()
+	r.Use(first, 0.5)
+	r.Use(second, 0.5)
+
+	d := r.Route(context.Background(), Request{Text: "x"})
+	if d.Match == nil || d.Match.Intent != "b" || d.Match.Classifier != "first" {
+		t.Fatalf("Match = %+v, ingin b dari first", d.Match)
+	}
+	if second.calls != 0 {
+		t.Errorf("classifier kedua dipanggil %d kali, ingin 0", second.calls)
+	}
+}
+
+func TestRouterClassifierThreshold(t *testing.T) {
+	first := &stubClassifier{name: "first", matches: []Match{{Intent: "a", Confidence: 0.95}}}
+	second := &stubClassifier{name: "second", matches: []Match{{Intent: "b", Confidence: 0.6}}}
+	r := NewRouter()
+	r.Use(first, 0.99)
+	r.Use(second, 0.5)
+
+	d := r.Route(context.Background(), Request{Text: "x"})
+	if d.Match == nil || d.Match.Intent != "b" {
+		t.Fatalf("Match = %+v, ingin b", d.Match)
+	}
+
+	second.matches[0].Confidence = 0.4
+	d = r.Route(context.Background(), Request{Text: "x"})
+	if d.Action != ActionDefer || d.Match != nil {
+		t.Errorf("Decision = %+v, ingin defer tanpa Match", d)
+	}
+}
+
+func TestRouterClassifierError(t *testing.T) {
+	failing := &stubClassifier{name: "failing", err: errors.New("boom")}
+	next := &stubClassifier{name: "next", matches: []Match{{Intent: "a", Confidence: 0.9}}}
+	r := NewRouter()
+	r.Use(failing, 0.5)
+	r.Use(next, 0.5)
+	r.Handle("a", Policy{AnswerAt: 0.5, Answer: answerWith("ok")})
+
+	d := r.Route(context.Background(), Request{Text: "x"})
+	if d.Action != ActionAnswer || d.Match.Classifier != "next" {
+		t.Fatalf("Decision = %+v, ingin answer dari next", d)
+	}
+
+	only := NewRouter()
+	only.Use(failing, 0.5)
+	if d := only.Route(context.Background(), Request{Text: "x"}); d.Action != ActionDefer {
+		t.Errorf("Action = %s, ingin defer jika semua classifier gagal", d.Action)
+	}
+}
+
+func TestRouterPolicy(t *testing.T) {
+	failAnswer := func(context.Context, Match) (*Answer, error) { return nil, errors.New("answer down") }
+	failPrefetch := func(context.Context, Match) (*Prefetch, error) { return nil, errors.New("prefetch down") }
+
+	tests := []struct {
+		name       string
+		confidence float64
+		policy     *Policy
+		location   bool
+		want       Action
+		reason     string
+	}{
+		{name: "tanpa policy", confidence: 0.9, want: ActionDefer, reason: "no policy"},
+		{name: "answer", confidence: 0.9, policy: &Policy{AnswerAt: 0.85, Answer: answerWith("ok")}, want: ActionAnswer},
+		{name: "di bawah answer", confidence: 0.8, policy: &Policy{AnswerAt: 0.85, Answer: answerWith("ok")}, want: ActionDefer, reason: "below thresholds"},
+		{
+			name:       "prefetch di bawah answer",
+			confidence: 0.8,
+			policy:     &Policy{AnswerAt: 0.85, Answer: answerWith("ok"), PrefetchAt: 0.7, Prefetch: prefetchWith("tool")},
+			want:       ActionPrefetch,
+		},
+		{
+			name:       "answer gagal jatuh ke prefetch",
+			confidence: 0.9,
+			policy:     &Policy{AnswerAt: 0.85, Answer: failAnswer, PrefetchAt: 0.85, Prefetch: prefetchWith("tool")},
+			want:       ActionPrefetch,
+		},
+		{
+			name:       "answer dan prefetch gagal",
+			confidence: 0.9,
+			policy:     &Policy{AnswerAt: 0.85, Answer: failAnswer, PrefetchAt: 0.85, Prefetch: failPrefetch},
+			want:       ActionDefer,
+			reason:     "answer failed: answer down; prefetch failed: prefetch down",
+		},
+		{name: "butuh lokasi", confidence: 0.9, policy: &Policy{NeedsLocation: true, AnswerAt: 0.85, Answer: failAnswer}, want: ActionNeedLocation},
+		{name: "butuh lokasi di bawah threshold", confidence: 0.6, policy: &Policy{NeedsLocation: true}, want: ActionNeedLocation},
+		{name: "lokasi ada", confidence: 0.9, policy: &Policy{NeedsLocation: true, AnswerAt: 0.85, Answer: answerWith("ok")}, location: true, want: ActionAnswer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRouter()
+			r.Use(&stubClassifier{name: "stub", matches: []Match{{Intent: "a", Confidence: tt.confidence}}}, 0.5)
+			if tt.policy != nil {
+				r.Handle("a", *tt.policy)
+			}
+			d := r.Route(context.Background(), Request{Text: "x", HasLocation: tt.location})
+			if d.Action != tt.want {
+				t.Fatalf("Action = %s (%s), ingin %s", d.Action, d.Reason, tt.want)
+			}
+			if !strings.Contains(d.Reason, tt.reason) {
+				t.Errorf("Reason = %q, ingin memuat %q", d.Reason, tt.reason)
+			}
+			if tt.want == ActionAnswer && d.Answer == nil {
+				t.Error("Answer nil")
+			}
+			if tt.want == ActionPrefetch && d.Prefetch == nil {
+				t.Error("Prefetch nil")
+			}
+		})
+	}
+}
+
+func TestRouterTimeout(t *testing.T) {
+	r := NewRouter()
+	r.Timeout = time.Millisecond
+	r.Use(&stubClassifier{name: "stub", matches: []Match{{Intent: "a", Confidence: 1}}}, 0.5)
+	r.Handle("a", Policy{AnswerAt: 0.5, Answer: func(ctx context.Context, _ Match) (*Answer, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}})
+
+	d := r.Route(context.Background(), Request{Text: "x"})
+	if d.Action != ActionDefer || !strings.Contains(d.Reason, context.DeadlineExceeded.Error()) {
+		t.Errorf("Decision = %+v, ingin defer karena deadline", d)
+	}
+}
+
+func TestRouterExtractor(t *testing.T) {
+	r := NewRouter()
+	r.Use(&Extractor{Label: "number", Intent: "a", Confidence: 1, Extract: func(text string) (any, bool) {
+		return text, text == "42"
+	}}, 0.9)
+	r.Use(&stubClassifier{name: "fallback", matches: []Match{{Intent: "b", Confidence: 0.6}}}, 0.5)
+
+	d := r.Route(context.Background(), Request{Text: "42"})
+	if d.Match == nil || d.Match.Intent != "a" || d.Match.Data != "42" {
+		t.Fatalf("Match = %+v, ingin a dengan Data 42", d.Match)
+	}
+	d = r.Route(context.Background(), Request{Text: "halo"})
+	if d.Match == nil || d.Match.Intent != "b" {
+		t.Errorf("Match = %+v, ingin b jika extractor tidak cocok", d.Match)
+	}
+}
+
+func TestRouterEmbedding(t *testing.T) {
+	embedder := &stubEmbedder{
+		vectors: map[string][]float32{
+			"cuaca?":  {1, 0},
+			"cafe?":   {0, 1},
+			"panas?":  {1, 0.1},
+			"lainnya": {-1, -1},
+		},
+		err: errors.New("embedding down"),
+	}
+	r := NewRouter()
+	r.Use(&Embedding{Label: "embedding", Embedder: embedder, Examples: map[string][]string{
+		"weather": {"cuaca?"},
+		"places":  {"cafe?"},
+	}}, 0.8)
+
+	d := r.Route(context.Background(), Request{Text: "panas?"})
+	if d.Action != ActionDefer || d.Match != nil {
+		t.Fatalf("Decision = %+v, ingin defer tanpa Match saat embedder gagal", d)
+	}
+
+	// Embedding contoh dicoba lagi setelah gagal.
+	embedder.err = nil
+	d = r.Route(context.Background(), Request{Text: "panas?"})
+	if d.Match == nil || d.Match.Intent != "weather" || d.Match.Confidence < 0.99 {
+		t.Fatalf("Match = %+v, ingin weather", d.Match)
+	}
+	if d := r.Route(context.Background(), Request{Text: "lainnya"}); d.Match != nil {
+		t.Errorf("Match = %+v, ingin nil di bawah threshold", d.Match)
+	}
+	// Satu panggilan gagal, satu untuk contoh, dua untuk teks.
+	if embedder.calls != 4 {
+		t.Errorf("Embed dipanggil %d kali, ingin 4", embedder.calls)
+	}
+}
+
+func TestRulesVocabulary(t *testing.T) {
+	rules := &Rules{Label: "keywords", Rules: []Rule{
+		{Intent: "a", Keywords: []string{"cuaca"}, Vocabulary: []string{"hari ini", " di sini "}, Confidence: 0.9},
+	}}
+	for text, want := range map[string]bool{
+		"Cuaca hari ini?":         true,
+		"cuaca di sini":           true,
+		"cuaca Jakarta hari ini?": false,
+		"cuaca ini":               false,
+	} {
+		matches, _ := rules.Classify(context.Background(), text)
+		if got := len(matches) == 1; got != want {
+			t.Errorf("Classify(%q) = %+v, ingin cocok %v", text, matches, want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package intent
+
+import (
+	"context"
+
+	genai "google.golang.org/genai"
+
+	"proxy/currency"
+	"proxy/tools"
+	"proxy/traveltools"
+)
+
+// Intent yang dikenali NewTravelRouter.
+const (
+	// CurrencyConvert: konversi sejumlah uang, Match.Data *currency.Query.
+	CurrencyConvert = "currency.convert"
+	// WeatherCurrent: cuaca sekarang di lokasi pengguna.
+	WeatherCurrent = "weather.current"
+	// WeatherForecast: prakiraan cuaca di lokasi pengguna.
+	WeatherForecast = "weather.forecast"
+	// WeatherPlace: cuaca di tempat yang disebut pengguna ("cuaca di
+	// Bandung"); diserahkan ke model yang bisa mengisi argumen city.
+	WeatherPlace = "weather.place"
+	// PlacesNearby: pertanyaan tempat di sekitar pengguna.
+	PlacesNearby = "places.nearby"
+	// PlacesSearch: pertanyaan tempat tanpa "terdekat" atau "sekitar sini"
+	// ("hotel murah di Bali"); diserahkan ke model tanpa meminta lokasi.
+	PlacesSearch = "places.search"
+)
+
+// Kata kunci rule NewTravelRouter, dicocokkan seperti dijelaskan di Rule.
+var (
+	weatherWords = []string{"cuaca", "weather", "suhu", "temperatur", "طقس"}
+	rainWords    = []string{"hujan", "gerimis", "rain", "مطر"}
+	futureWords  = []string{
+		"besok", "lusa", "nanti", "minggu depan", "prakiraan", "ramalan",
+		"tomorrow", "tonight", "later", "forecast", "next week", "غدا",
+	}
+	hereWords = []string{
+		" di sini ", " disini ", " sekitar sini ", " di sekitar ", " dekat sini ",
+		" here ", " around me ", " near me ", " هنا ",
+	}
+	// fillerWords adalah kata waktu sekarang dan kata tanya yang boleh
+	// menyertai pertanyaan cuaca di lokasi pengguna. Kata di luar daftar ini
+	// dianggap nama tempat, jadi "cuaca Jakarta hari ini" tidak dijawab
+	// dengan cuaca di lokasi pengguna.
+	fillerWords = []string{
+		"sekarang", "hari ini", "saat ini", "lagi", "pagi", "siang", "sore", "malam",
+		"gimana", "bagaimana", "berapa", "apa", "apakah", "bakal", "akan", "turun",
+		"panas", "dingin", "cerah", "nggak", "gak", "ga", "tidak", "ya", "dong", "sih",
+		"now", "right now", "today", "currently", "current", "what's", "what", "how",
+		"is", "the", "it", "like", "will", "be", "there", "hot", "cold",
+		"الآن", "اليوم", "كيف", "ما", "هل",
+	}
+	nearbyWords = []string{
+		"di dekat", "terdekat", "sekitar sini", "di sekitar", "dekat sini",
+		"near me", "nearby", "closest", "around here", "الأقرب",
+	}
+	placeTypeWords = []string{
+		"di mana", "lokasi", "tempat", "jarak", "restoran", "kafe", "mall", "toko",
+		"hotel", "wisata", "kuliner", "makanan", "minuman", "kopi", "nongkrong",
+		"restaurant", "cafe", "coffee",
+	}
+)
+
+// travelRules adalah rule kata kunci bawaan. Cuaca yang hanya disertai kata
+// waktu, kata tanya atau "di sini" dianggap cuaca di lokasi pengguna; cuaca
+// atau hujan dengan kata lain, misal nama kota, menjadi WeatherPlace, dan
+// pertanyaan tempat tanpa "terdekat" atau "sekitar sini" menjadi
+// PlacesSearch. Keduanya diserahkan ke model tanpa meminta lokasi, dan
+// classifier embedding tidak menebaknya sebagai intent di lokasi pengguna.
+var travelRules = []Rule{
+	{Intent: WeatherCurrent, Keywords: weatherWords, Exclude: futureWords, Vocabulary: append(append([]string(nil), fillerWords...), hereWords...), Confidence: 0.9},
+	{Intent: WeatherForecast, Keywords: append(append([]string(nil), weatherWords...), rainWords...), Require: futureWords, Vocabulary: append(append([]string(nil), fillerWords...), hereWords...), Confidence: 0.9},
+	{Intent: WeatherPlace, Keywords: append(append([]string(nil), weatherWords...), rainWords...), Exclude: hereWords, Confidence: 0.7},
+	{Intent: PlacesNearby, Keywords: nearbyWords, Confidence: 0.9},
+	{Intent: PlacesSearch, Keywords: placeTypeWords, Confidence: 0.6},
+}
+
+// travelExamples adalah contoh kalimat untuk classifier embedding.
+var travelExamples = map[string][]string{
+	WeatherCurrent: {
+		"cuaca sekarang gimana?", "lagi panas nggak di sini?", "what's the weather like right now?",
+	},
+	WeatherForecast: {
+		"besok hujan gak?", "nanti sore bakal hujan?", "will it rain tomorrow?",
+	},
+	PlacesNearby: {
+		"cafe terdekat di mana?", "ada tempat makan enak di sekitar sini?", "find a restaurant near me",
+	},
+}
+
+// Threshold dan batas confidence bawaan NewTravelRouter.
+const (
+	rulesThreshold     = 0.5
+	extractorThreshold = 0.9
+	embeddingThreshold = 0.8
+	answerThreshold    = 0.85
+	prefetchThreshold  = 0.85
+)
+
+// NewTravelRouter membuat Router untuk Travel Buddy: konversi mata uang yang
+// bisa di-parse dijawab langsung lewat convertCurrency, cuaca sekarang di
+// lokasi pengguna dijawab lewat getCurrentWeather, prakiraan di lokasi
+// pengguna di-prefetch lewat getWeatherForecast, dan pertanyaan tempat di
+// sekitar meminta lokasi jika belum ada. Tool dipanggil lewat reg dengan ctx
+// dari Route, jadi ctx harus membawa session pengguna (session.NewContext).
+// embedder boleh nil; jika diisi, classifier embedding ikut dipakai.
+func NewTravelRouter(reg *tools.Registry, embedder Embedder) *Router {
+	r := NewRouter()
+	r.Use(&Extractor{
+		Label:      "currency-parser",
+		Intent:     CurrencyConvert,
+		Confidence: 1,
+		Extract: func(text string) (any, bool) {
+			q, err := currency.Parse(text)
+			return q, err == nil
+		},
+	}, extractorThreshold)
+	r.Use(&Rules{Label: "keywords", Rules: travelRules}, rulesThreshold)
+	if embedder != nil {
+		r.Use(&Embedding{Label: "embedding", Embedder: embedder, Examples: travelExamples}, embeddingThreshold)
+	}
+
+	r.Handle(CurrencyConvert, Policy{
+		AnswerAt: answerThreshold,
+		Answer: ToolAnswer(reg, traveltools.ConvertTool, "currency_result", func(m Match) map[string]any {
+			q := m.Data.(*currency.Query)
+			return map[string]any{"amount": q.Amount, "from": q.From, "to": q.To}
+		}),
+	})
+	r.Handle(WeatherCurrent, Policy{
+		NeedsLocation: true,
+		AnswerAt:      answerThreshold,
+		Answer:        ToolAnswer(reg, traveltools.WeatherTool, "weather_result", nil),
+	})
+	r.Handle(WeatherForecast, Policy{
+		NeedsLocation: true,
+		PrefetchAt:    prefetchThreshold,
+		Prefetch:      ToolPrefetch(reg, traveltools.ForecastTool, nil),
+	})
+	r.Handle(PlacesNearby, Policy{NeedsLocation: true})
+	return r
+}
+
+// ToolAnswer membuat Policy.Answer yang memanggil tool di reg dan mengirim
+// hasilnya dengan status. args membuat argumen tool dari Match; nil berarti
+// tanpa argumen.
+func ToolAnswer(reg *tools.Registry, tool, status string, args func(Match) map[string]any) func(context.Context, Match) (*Answer, error) {
+	return func(ctx context.Context, m Match) (*Answer, error) {
+		data, err := callTool(ctx, reg, tool, args, m)
+		if err != nil {
+			return nil, err
+		}
+		return &Answer{Status: status, Data: data}, nil
+	}
+}
+
+// ToolPrefetch membuat Policy.Prefetch yang memanggil tool di reg. args
+// seperti pada ToolAnswer.
+func ToolPrefetch(reg *tools.Registry, tool string, args func(Match) map[string]any) func(context.Context, Match) (*Prefetch, error) {
+	return func(ctx context.Context, m Match) (*Prefetch, error) {
+		data, err := callTool(ctx, reg, tool, args, m)
+		if err != nil {
+			return nil, err
+		}
+		return &Prefetch{Tool: tool, Data: data}, nil
+	}
+}
+
+func callTool(ctx context.Context, reg *tools.Registry, tool string, args func(Match) map[string]any, m Match) (map[string]any, error) {
+	var callArgs map[string]any
+	if args != nil {
+		callArgs = args(m)
+	}
+	resp, err := reg.Call(ctx, &genai.FunctionCall{Name: tool, Args: callArgs})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response, nil
+}
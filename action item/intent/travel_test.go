@@ -0,0 +1,67 @@
+package intent
+
+import (
+	"context"
+	"testing"
+
+	"proxy/fakes"
+	"proxy/providers"
+	"proxy/session"
+	"proxy/tools"
+	"proxy/traveltools"
+)
+
+func TestTravelRouterLocation(t *testing.T) {
+	api := fakes.NewServer()
+	defer api.Close()
+	set, err := providers.New(api.ProvidersConfig())
+	if err != nil {
+		t.Fatalf("providers.New: %v", err)
+	}
+	reg := tools.NewRegistry()
+	traveltools.Register(reg, set)
+	router := NewTravelRouter(reg, nil)
+
+	tests := []struct {
+		name string
+		text string
+		// location diisi lewat SetLocation sebelum Route jika true.
+		location bool
+		want     Action
+		status   string
+	}{
+		{name: "cuaca tanpa lokasi", text: "cuaca sekarang gimana?", want: ActionNeedLocation},
+		{name: "cuaca dengan lokasi", text: "cuaca sekarang gimana?", location: true, want: ActionAnswer, status: "weather_result"},
+		{name: "prakiraan tanpa lokasi", text: "besok hujan gak?", want: ActionNeedLocation},
+		{name: "tempat terdekat tanpa lokasi", text: "cafe terdekat di mana?", want: ActionNeedLocation},
+		{name: "cuaca dalam bahasa inggris", text: "what's the weather like right now?", location: true, want: ActionAnswer, status: "weather_result"},
+		{name: "cuaca di kota lain", text: "cuaca di Bandung gimana?", want: ActionDefer},
+		{name: "cuaca kota tanpa preposisi", text: "cuaca Jakarta hari ini?", want: ActionDefer},
+		{name: "cuaca kota tanpa preposisi dengan lokasi", text: "cuaca Jakarta hari ini?", location: true, want: ActionDefer},
+		{name: "cuaca kota dalam bahasa inggris", text: "Bandung weather now", want: ActionDefer},
+		{name: "cuaca kota dalam bahasa inggris dengan lokasi", text: "Bandung weather now", location: true, want: ActionDefer},
+		{name: "prakiraan kota lain", text: "besok hujan di Bogor?", want: ActionDefer},
+		{name: "hotel di kota lain", text: "hotel murah di Bali?", want: ActionDefer},
+		{name: "lokasi tempat bernama", text: "Di mana lokasi Candi Borobudur?", want: ActionDefer},
+		{name: "wisata di kota lain", text: "tempat wisata di Lombok", want: ActionDefer},
+		{name: "konversi tanpa lokasi", text: "50 EUR berapa Rupiah?", want: ActionAnswer, status: "currency_result"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sess := session.New("s")
+			if tt.location {
+				sess.SetLocation(providers.Coordinates{Latitude: -7.7, Longitude: 110.4})
+			}
+			d := router.Route(session.NewContext(context.Background(), sess), Request{
+				Text:        tt.text,
+				HasLocation: sess.HasLocation(),
+			})
+			if d.Action != tt.want {
+				t.Fatalf("Action = %s (%s), ingin %s", d.Action, d.Reason, tt.want)
+			}
+			if tt.status != "" && (d.Answer == nil || d.Answer.Status != tt.status) {
+				t.Errorf("Answer = %+v, ingin status %s", d.Answer, tt.status)
+			}
+		})
+	}
+}
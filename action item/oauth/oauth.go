@@ -0,0 +1,165 @@
+// Package oauth menyediakan token source OAuth bersama untuk Vertex AI dan
+// Text-to-Speech. Token di-cache dan diperbarui sebelum kedaluwarsa, baik saat
+// diminta maupun di background lewat Run, sehingga setup Live API dan TTS tidak
+// membaca file kunci dan meminta token baru setiap kali.
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// CloudPlatformScope adalah scope yang dipakai Vertex AI dan Text-to-Speech.
+const CloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// DefaultRefreshBefore adalah jarak sebelum kedaluwarsa saat token diperbarui.
+const DefaultRefreshBefore = 5 * time.Minute
+
+// Source adalah oauth2.TokenSource yang aman dipakai bersama dari banyak
+// goroutine. Token dari base di-cache sampai RefreshBefore sebelum Expiry.
+type Source struct {
+	// RefreshBefore default DefaultRefreshBefore.
+	RefreshBefore time.Duration
+
+	base oauth2.TokenSource
+	mu   sync.Mutex
+	tok  *oauth2.Token
+	now  func() time.Time
+}
+
+// NewSource membungkus base dengan cache.
+func NewSource(base oauth2.TokenSource) *Source {
+	return &Source{base: base, now: time.Now}
+}
+
+// Static mengembalikan Source yang selalu memberi token yang sama dan tidak
+// pernah kedaluwarsa; untuk test dan server palsu.
+func Static(token string) *Source {
+	return NewSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token, TokenType: "Bearer"}))
+}
+
+// FromServiceAccountFile membuat Source dari file JSON service account.
+func FromServiceAccountFile(ctx context.Context, path string, scopes ...string) (*Source, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading key file: %v", err)
+	}
+	creds, err := google.CredentialsFromJSON(ctx, keyData, withDefaultScope(scopes)...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating credentials from JSON: %v", err)
+	}
+	return NewSource(creds.TokenSource), nil
+}
+
+// FromDefault membuat Source dari rantai Application Default Credentials
+// (GOOGLE_APPLICATION_CREDENTIALS, gcloud, metadata server).
+func FromDefault(ctx context.Context, scopes ...string) (*Source, error) {
+	creds, err := google.FindDefaultCredentials(ctx, withDefaultScope(scopes)...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding default credentials: %v", err)
+	}
+	return NewSource(creds.TokenSource), nil
+}
+
+// FromEnv memilih mode berdasarkan environment: VERTEX_ACCESS_TOKEN (token
+// statis), lalu GOOGLE_APPLICATION_CREDENTIALS (file service account), lalu
+// Application Default Credentials.
+func FromEnv(ctx context.Context) (*Source, error) {
+	if token := os.Getenv("VERTEX_ACCESS_TOKEN"); token != "" {
+		log.Println("Using static access token from VERTEX_ACCESS_TOKEN")
+		return Static(token), nil
+	}
+	if keyPath := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); keyPath != "" {
+		log.Printf("Using credentials file: %s", keyPath)
+		return FromServiceAccountFile(ctx, keyPath)
+	}
+	log.Println("Using Application Default Credentials")
+	return FromDefault(ctx)
+}
+
+func withDefaultScope(scopes []string) []string {
+	if len(scopes) == 0 {
+		return []string{CloudPlatformScope}
+	}
+	return scopes
+}
+
+func (s *Source) refreshBefore() time.Duration {
+	if s.RefreshBefore > 0 {
+		return s.RefreshBefore
+	}
+	return DefaultRefreshBefore
+}
+
+// fresh melaporkan apakah tok masih bisa dipakai tanpa diperbarui.
+func (s *Source) fresh(tok *oauth2.Token) bool {
+	if tok == nil || tok.AccessToken == "" {
+		return false
+	}
+	if tok.Expiry.IsZero() {
+		return true
+	}
+	return s.now().Add(s.refreshBefore()).Before(tok.Expiry)
+}
+
+// Token mengembalikan token yang di-cache, atau meminta token baru ke base
+// jika token hampir kedaluwarsa.
+func (s *Source) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fresh(s.tok) {
+		return s.tok, nil
+	}
+	return s.refreshLocked()
+}
+
+func (s *Source) refreshLocked() (*oauth2.Token, error) {
+	// creds.TokenSource sudah berupa ReuseTokenSource yang baru meminta token
+	// baru 10 detik sebelum kedaluwarsa; samakan batasnya dengan
+	// refreshBefore agar base benar-benar memperbarui token di sini.
+	tok, err := oauth2.ReuseTokenSourceWithExpiry(nil, s.base, s.refreshBefore()).Token()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving access token: %v", err)
+	}
+	s.tok = tok
+	return tok, nil
+}
+
+// AccessToken adalah Token yang hanya mengembalikan string access token.
+func (s *Source) AccessToken() (string, error) {
+	tok, err := s.Token()
+	if err != nil {
+		return "", err
+	}
+	return tok.AccessToken, nil
+}
+
+// Run memperbarui token di background sebelum kedaluwarsa sampai ctx selesai.
+// Kegagalan dicoba lagi setiap menit; token yang tidak pernah kedaluwarsa
+// (Static) tidak diperbarui.
+func (s *Source) Run(ctx context.Context) {
+	for {
+		wait := time.Minute
+		tok, err := s.Token()
+		if err != nil {
+			log.Printf("Gagal memperbarui access token: %v", err)
+		} else if tok.Expiry.IsZero() {
+			return
+		} else if d := tok.Expiry.Sub(s.now()) - s.refreshBefore(); d > 0 {
+			wait = d
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
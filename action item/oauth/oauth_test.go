@@ -0,0 +1,68 @@
+package oauth
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// countingSource adalah base palsu yang memberi token baru setiap dipanggil,
+// kedaluwarsa ttl setelahnya (tanpa Expiry jika ttl 0).
+type countingSource struct {
+	ttl     time.Duration
+	fetches atomic.Int32
+}
+
+func (c *countingSource) Token() (*oauth2.Token, error) {
+	n := c.fetches.Add(1)
+	tok := &oauth2.Token{AccessToken: fmt.Sprintf("tok-%d", n), TokenType: "Bearer"}
+	if c.ttl > 0 {
+		tok.Expiry = time.Now().Add(c.ttl)
+	}
+	return tok, nil
+}
+
+func TestSourceRefreshBefore(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		// reuse membungkus base dengan oauth2.ReuseTokenSource seperti
+		// creds.TokenSource.
+		reuse bool
+		want  int32
+	}{
+		{name: "token masih lama", ttl: time.Hour, reuse: true, want: 1},
+		{name: "token tanpa expiry", want: 1},
+		{name: "hampir kedaluwarsa", ttl: 4 * time.Minute, want: 3},
+		{name: "hampir kedaluwarsa lewat ReuseTokenSource", ttl: 4 * time.Minute, reuse: true, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := &countingSource{ttl: tt.ttl}
+			var src oauth2.TokenSource = base
+			if tt.reuse {
+				src = oauth2.ReuseTokenSource(nil, base)
+			}
+			s := NewSource(src)
+			s.RefreshBefore = 5 * time.Minute
+
+			var last string
+			for range 3 {
+				tok, err := s.AccessToken()
+				if err != nil {
+					t.Fatalf("AccessToken: %v", err)
+				}
+				last = tok
+			}
+			if got := base.fetches.Load(); got != tt.want {
+				t.Errorf("base dipanggil %d kali, ingin %d", got, tt.want)
+			}
+			if want := fmt.Sprintf("tok-%d", tt.want); last != want {
+				t.Errorf("token terakhir = %q, ingin %q", last, want)
+			}
+		})
+	}
+}
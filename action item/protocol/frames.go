@@ -0,0 +1,198 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+
+	"proxy/answer"
+)
+
+// Type frame klien.
+const (
+	TypeText        = "text"
+	TypeAudio       = "audio"
+	TypeAudioEnd    = "audio_end"
+	TypeImage       = "image"
+	TypeLocation    = "location"
+	TypePreferences = "preferences"
+)
+
+// Type frame proxy.
+const (
+	TypeReady           = "ready"
+	TypeAck             = "ack"
+	TypeStreaming       = "streaming"
+	TypeReplace         = "replace"
+	TypeResponse        = "response"
+	TypeAnswer          = "answer"
+	TypeLocationRequest = "location_request"
+	TypeError           = "error"
+)
+
+// Mime type default untuk frame audio dan image tanpa mime_type, dan untuk
+// frame WebSocket biner.
+const (
+	DefaultAudioMimeType = "audio/pcm;rate=16000"
+	DefaultImageMimeType = "image/jpeg"
+)
+
+// Text adalah pertanyaan teks pengguna; memulai giliran model.
+type Text struct {
+	Header
+	Text string `json:"text"`
+}
+
+func (f *Text) validate() error {
+	if f.Text == "" {
+		return errors.New("text kosong")
+	}
+	return nil
+}
+
+// Audio adalah potongan rekaman suara pengguna.
+type Audio struct {
+	Header
+	// Data adalah audio mentah, di JSON ditulis base64.
+	Data     []byte `json:"data"`
+	MimeType string `json:"mime_type,omitempty"`
+}
+
+func (f *Audio) validate() error {
+	if len(f.Data) == 0 {
+		return errors.New("data audio kosong")
+	}
+	if f.MimeType == "" {
+		f.MimeType = DefaultAudioMimeType
+	}
+	return nil
+}
+
+// AudioEnd menandai akhir rekaman suara; memulai giliran model.
+type AudioEnd struct {
+	Header
+}
+
+func (f *AudioEnd) validate() error { return nil }
+
+// Image adalah satu frame kamera atau gambar yang dilampirkan ke percakapan.
+type Image struct {
+	Header
+	// Data adalah gambar mentah, di JSON ditulis base64.
+	Data     []byte `json:"data"`
+	MimeType string `json:"mime_type,omitempty"`
+}
+
+func (f *Image) validate() error {
+	if len(f.Data) == 0 {
+		return errors.New("data gambar kosong")
+	}
+	if f.MimeType == "" {
+		f.MimeType = DefaultImageMimeType
+	}
+	return nil
+}
+
+// Location adalah posisi GPS pengguna. Latitude dan Longitude boleh ditulis
+// sebagai angka atau string angka.
+type Location struct {
+	Header
+	Latitude  json.Number `json:"latitude"`
+	Longitude json.Number `json:"longitude"`
+}
+
+func (f *Location) validate() error {
+	if f.Latitude == "" || f.Longitude == "" {
+		return errors.New("latitude dan longitude harus diisi")
+	}
+	return nil
+}
+
+// Preferences adalah bahasa (BCP-47, misal "id-ID") dan preferensi pengguna.
+type Preferences struct {
+	Header
+	Language    string            `json:"language,omitempty"`
+	Preferences map[string]string `json:"preferences,omitempty"`
+}
+
+func (f *Preferences) validate() error { return nil }
+
+// Ready dikirim sekali setelah koneksi ke Live API siap.
+type Ready struct {
+	Header
+	Version   int    `json:"version"`
+	SessionID string `json:"session_id"`
+}
+
+func (*Ready) frameType() string { return TypeReady }
+
+// Ack mengonfirmasi frame klien yang tidak dibalas model, misal audio,
+// location atau preferences.
+type Ack struct {
+	Header
+	// Of adalah type frame yang dikonfirmasi.
+	Of      string `json:"of"`
+	Message string `json:"message,omitempty"`
+	// Samples adalah jumlah sampel PCM 16 kHz yang sudah diteruskan ke Live
+	// API dari rekaman yang sedang berjalan; hanya untuk ack audio.
+	Samples int64 `json:"samples,omitempty"`
+}
+
+func (*Ack) frameType() string { return TypeAck }
+
+// Streaming adalah jawaban model sejauh ini selama giliran berjalan.
+type Streaming struct {
+	Header
+	Partial string `json:"partial"`
+}
+
+func (*Streaming) frameType() string { return TypeStreaming }
+
+// Replace berarti jawaban yang sudah di-stream untuk ID ini dibuang karena
+// tidak sesuai format; streaming dan response berikutnya menggantikannya.
+type Replace struct {
+	Header
+}
+
+func (*Replace) frameType() string { return TypeReplace }
+
+// Response adalah jawaban akhir model untuk satu giliran.
+type Response struct {
+	Header
+	Response   string            `json:"response"`
+	Transcript string            `json:"transcript,omitempty"`
+	Locations  []answer.Location `json:"locations,omitempty"`
+	Weather    *answer.Weather   `json:"weather,omitempty"`
+	// Audio adalah MP3 hasil Text-to-Speech dalam base64.
+	Audio string `json:"audio,omitempty"`
+}
+
+func (*Response) frameType() string { return TypeResponse }
+
+// Answer adalah jawaban langsung proxy tanpa model, misal hasil konversi
+// mata uang dari router intent.
+type Answer struct {
+	Header
+	// Kind menjelaskan isi Data, misal "currency_result" atau
+	// "weather_result".
+	Kind string `json:"kind"`
+	Data any    `json:"data"`
+}
+
+func (*Answer) frameType() string { return TypeAnswer }
+
+// LocationRequest meminta klien mengirim frame location.
+type LocationRequest struct {
+	Header
+	Message string `json:"message"`
+}
+
+func (*LocationRequest) frameType() string { return TypeLocationRequest }
+
+// ErrorMessage adalah frame error; buat dengan ErrorFrame.
+type ErrorMessage struct {
+	Header
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+func (*ErrorMessage) frameType() string { return TypeError }
@@ -0,0 +1,236 @@
+// Package protocol mendefinisikan protokol WebSocket antara klien (browser
+// atau aplikasi) dan proxy Travel Buddy di /ws. Setiap pesan teks adalah satu
+// frame JSON dengan field "type" dan "id" opsional; isi field lain bergantung
+// pada type.
+//
+// Versi protokol disepakati saat connect, lewat subprotocol WebSocket
+// "travelbuddy.v1" (new WebSocket(url, "travelbuddy.v1")) atau query
+// ?version=1. Klien yang tidak menyebut versi dianggap memakai Version. Jika
+// versi yang diminta tidak didukung, proxy menolak upgrade dengan HTTP 400
+// dan body berisi frame error "unsupported_version". Browser hanya melihat
+// koneksi gagal (close 1006) karena body handshake tidak bisa dibaca dari
+// JavaScript; klien lain bisa membaca frame error dari response.
+//
+// Frame klien ke proxy:
+//
+//	{"type": "text", "id": "1", "text": "cafe terdekat di mana?"}
+//	{"type": "audio", "id": "2", "data": "<base64>", "mime_type": "audio/pcm;rate=16000"}
+//	{"type": "audio_end", "id": "3"}
+//	{"type": "image", "id": "4", "data": "<base64>", "mime_type": "image/jpeg"}
+//	{"type": "location", "id": "5", "latitude": -6.2, "longitude": 106.8}
+//	{"type": "preferences", "id": "6", "language": "id-ID", "preferences": {"diet": "halal"}}
+//
+// Frame WebSocket biner diperlakukan sebagai frame audio tanpa id dengan
+// mime_type default.
+//
+// Frame text diproses berurutan tanpa menahan frame lain, jadi ack untuk
+// frame audio, location atau preferences bisa datang sebelum jawaban text
+// yang dikirim lebih dulu. Jika terlalu banyak frame text yang menunggu,
+// frame berikutnya ditolak dengan error "busy".
+//
+// Frame audio berurutan sampai audio_end membentuk satu rekaman. Proxy
+// menerima WebM/Opus dan Ogg/Opus (MediaRecorder), WAV, dan PCM mentah 16-bit
+// little-endian ("audio/pcm;rate=48000;channels=2", default 16 kHz mono),
+// lalu mengubahnya menjadi PCM 16 kHz mono untuk Live API. Format dikenali
+// dari header frame audio pertama, lalu dari mime_type-nya (default
+// "audio/pcm;rate=16000"); data berlabel audio/webm, audio/ogg atau audio/wav
+// tanpa header container ditolak. Ack untuk frame audio membawa jumlah sampel
+// 16 kHz yang sudah diteruskan dari rekaman itu. Format yang tidak didukung,
+// misal MP4/AAC, ditolak dengan error "unsupported_audio" dan data rusak
+// dengan "invalid_audio"; keduanya membatalkan rekaman yang sedang berjalan.
+//
+// Frame proxy ke klien:
+//
+//	{"type": "ready", "version": 1, "session_id": "session-1"}
+//	{"type": "ack", "id": "2", "of": "audio"}
+//	{"type": "streaming", "id": "1", "partial": "..."}
+//	{"type": "replace", "id": "1"}
+//	{"type": "response", "id": "1", "response": "...", "locations": [...], "weather": {...}, "audio": "<base64 mp3>"}
+//	{"type": "answer", "id": "1", "kind": "currency_result", "data": {...}}
+//	{"type": "location_request", "id": "1", "message": "..."}
+//	{"type": "error", "id": "1", "code": "unknown_frame", "message": "..."}
+//
+// Balasan membawa id frame klien yang dijawabnya: ack, answer,
+// location_request dan error untuk frame itu sendiri, streaming, replace dan
+// response untuk frame text atau audio_end terakhir yang memicu giliran model.
+// Jika jawaban model tidak sesuai format, proxy meminta model memperbaikinya
+// dan mengirim replace: klien membuang partial yang sudah tampil, lalu
+// streaming berikutnya dimulai dari awal jawaban perbaikan.
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// Version adalah versi protokol terbaru yang didukung proxy.
+const Version = 1
+
+// subprotocolPrefix diikuti nomor versi, misal "travelbuddy.v1".
+const subprotocolPrefix = "travelbuddy.v"
+
+// Subprotocols mengembalikan subprotocol WebSocket untuk semua versi yang
+// didukung, untuk websocket.Upgrader.Subprotocols.
+func Subprotocols() []string {
+	return []string{Subprotocol(Version)}
+}
+
+// Subprotocol mengembalikan nama subprotocol WebSocket untuk version.
+func Subprotocol(version int) string {
+	return subprotocolPrefix + strconv.Itoa(version)
+}
+
+// Negotiate memilih versi protokol untuk request upgrade r: versi tertinggi
+// yang didukung dari header Sec-WebSocket-Protocol, lalu query ?version=,
+// lalu Version jika klien tidak menyebut versi. Error bertipe *Error dengan
+// CodeUnsupportedVersion.
+func Negotiate(r *http.Request) (int, error) {
+	offered := websocket.Subprotocols(r)
+	if q := r.URL.Query().Get("version"); q != "" {
+		offered = append(offered, subprotocolPrefix+q)
+	}
+	if len(offered) == 0 {
+		return Version, nil
+	}
+	best := 0
+	for _, p := range offered {
+		if !strings.HasPrefix(p, subprotocolPrefix) {
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimPrefix(p, subprotocolPrefix))
+		if err == nil && v >= 1 && v <= Version && v > best {
+			best = v
+		}
+	}
+	if best == 0 {
+		return 0, &Error{
+			Code:    CodeUnsupportedVersion,
+			Message: fmt.Sprintf("versi protokol %s tidak didukung, gunakan %s", strings.Join(offered, ", "), strings.Join(Subprotocols(), ", ")),
+		}
+	}
+	return best, nil
+}
+
+// ErrorCode adalah kode error di frame error.
+type ErrorCode string
+
+const (
+	// CodeUnsupportedVersion: versi protokol yang diminta tidak didukung.
+	CodeUnsupportedVersion ErrorCode = "unsupported_version"
+	// CodeInvalidFrame: frame bukan JSON yang valid atau field-nya salah.
+	CodeInvalidFrame ErrorCode = "invalid_frame"
+	// CodeUnknownFrame: type frame tidak dikenal.
+	CodeUnknownFrame ErrorCode = "unknown_frame"
+	// CodeInvalidLocation: koordinat frame location tidak valid.
+	CodeInvalidLocation ErrorCode = "invalid_location"
+	// CodeUnsupportedAudio: format frame audio tidak didukung.
+	CodeUnsupportedAudio ErrorCode = "unsupported_audio"
+	// CodeInvalidAudio: data frame audio rusak.
+	CodeInvalidAudio ErrorCode = "invalid_audio"
+	// CodeUpstreamUnavailable: koneksi ke Live API terputus.
+	CodeUpstreamUnavailable ErrorCode = "upstream_unavailable"
+	// CodeUpstreamError: pesan dari Live API tidak bisa diproses.
+	CodeUpstreamError ErrorCode = "upstream_error"
+	// CodeBusy: terlalu banyak frame text yang masih menunggu diproses.
+	CodeBusy ErrorCode = "busy"
+	// CodeInternal: kegagalan lain di proxy.
+	CodeInternal ErrorCode = "internal"
+)
+
+// Error adalah kegagalan yang dilaporkan ke klien sebagai frame error.
+type Error struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// ErrorFrame mengubah err menjadi frame error untuk request id. Error selain
+// *Error dilaporkan dengan CodeInternal.
+func ErrorFrame(id string, err error) *ErrorMessage {
+	var perr *Error
+	if !errors.As(err, &perr) {
+		perr = &Error{Code: CodeInternal, Message: err.Error()}
+	}
+	return &ErrorMessage{Header: Header{ID: id}, Code: perr.Code, Message: perr.Message}
+}
+
+// Header adalah field yang ada di setiap frame.
+type Header struct {
+	Type string `json:"type"`
+	// ID dipilih klien untuk mencocokkan balasan dengan request-nya.
+	ID string `json:"id,omitempty"`
+}
+
+func (h *Header) header() *Header { return h }
+
+// RequestID mengembalikan ID frame.
+func (h *Header) RequestID() string { return h.ID }
+
+// ClientFrame adalah frame dari klien; lihat Decode.
+type ClientFrame interface {
+	RequestID() string
+	header() *Header
+	validate() error
+}
+
+// ServerFrame adalah frame dari proxy; lihat Encode.
+type ServerFrame interface {
+	frameType() string
+	header() *Header
+}
+
+// clientFrames membuat frame kosong untuk setiap type frame klien.
+var clientFrames = map[string]func() ClientFrame{
+	TypeText:        func() ClientFrame { return new(Text) },
+	TypeAudio:       func() ClientFrame { return new(Audio) },
+	TypeAudioEnd:    func() ClientFrame { return new(AudioEnd) },
+	TypeImage:       func() ClientFrame { return new(Image) },
+	TypeLocation:    func() ClientFrame { return new(Location) },
+	TypePreferences: func() ClientFrame { return new(Preferences) },
+}
+
+// Decode membaca satu frame teks dari klien, misal *Text atau *Location.
+// Frame yang bukan JSON, type-nya tidak dikenal atau field-nya tidak valid
+// menghasilkan *Error (CodeInvalidFrame atau CodeUnknownFrame); frame tetap
+// dikembalikan jika type-nya dikenal sehingga ID-nya bisa dibalas.
+func Decode(data []byte) (ClientFrame, error) {
+	var h Header
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, &Error{Code: CodeInvalidFrame, Message: fmt.Sprintf("frame bukan JSON object yang valid: %v", err)}
+	}
+	if h.Type == "" {
+		return &h, &Error{Code: CodeInvalidFrame, Message: "field type harus diisi"}
+	}
+	newFrame, ok := clientFrames[h.Type]
+	if !ok {
+		return &h, &Error{Code: CodeUnknownFrame, Message: fmt.Sprintf("type frame %q tidak dikenal", h.Type)}
+	}
+	frame := newFrame()
+	if err := json.Unmarshal(data, frame); err != nil {
+		*frame.header() = h
+		return frame, &Error{Code: CodeInvalidFrame, Message: fmt.Sprintf("frame %s tidak valid: %v", h.Type, err)}
+	}
+	if err := frame.validate(); err != nil {
+		return frame, &Error{Code: CodeInvalidFrame, Message: fmt.Sprintf("frame %s tidak valid: %v", h.Type, err)}
+	}
+	return frame, nil
+}
+
+// Encode menulis frame proxy sebagai JSON dengan type yang sesuai.
+func Encode(frame ServerFrame) ([]byte, error) {
+	frame.header().Type = frame.frameType()
+	return json.Marshal(frame)
+}
+
+// validate membuat Header memenuhi ClientFrame untuk frame yang type-nya
+// tidak dikenal.
+func (h *Header) validate() error { return nil }
@@ -0,0 +1,77 @@
+package protocol
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func FuzzStatusFrames(f *testing.F) {
+	for _, s := range []string{
+		"",
+		"koneksi ke Live API terputus",
+		`"}, "type": "response", "x": "`,
+		`C:\temp\"\`,
+		"baris satu\nbaris dua\r\n\ttab",
+		"\x00\x1f\u2028\u2029</script>",
+		"bukan utf-8 \xff\xfe \xe2\x82",
+	} {
+		f.Add("1", s, s)
+		f.Add(s, string(CodeUpstreamError), s)
+	}
+	f.Fuzz(func(t *testing.T, id, code, message string) {
+		// encoding/json mengganti setiap byte UTF-8 yang tidak valid dengan
+		// U+FFFD, sama seperti konversi ke []rune.
+		valid := func(s string) string { return string([]rune(s)) }
+		header := func(typ string) map[string]any {
+			m := map[string]any{"type": typ}
+			if id != "" {
+				m["id"] = valid(id)
+			}
+			return m
+		}
+		with := func(m map[string]any, kv ...string) map[string]any {
+			for i := 0; i < len(kv); i += 2 {
+				m[kv[i]] = valid(kv[i+1])
+			}
+			return m
+		}
+
+		tests := []struct {
+			frame ServerFrame
+			want  map[string]any
+		}{
+			{
+				frame: ErrorFrame(id, &Error{Code: ErrorCode(code), Message: message}),
+				want:  with(header(TypeError), "code", code, "message", message),
+			},
+			{
+				frame: &Ack{Header: Header{ID: id}, Of: code, Message: message},
+				want: func() map[string]any {
+					m := with(header(TypeAck), "of", code)
+					if message != "" {
+						m["message"] = valid(message)
+					}
+					return m
+				}(),
+			},
+			{
+				frame: &LocationRequest{Header: Header{ID: id}, Message: message},
+				want:  with(header(TypeLocationRequest), "message", message),
+			},
+		}
+		for _, tt := range tests {
+			data, err := Encode(tt.frame)
+			if err != nil {
+				t.Fatalf("Encode(%T): %v", tt.frame, err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("unmarshal %s: %v", data, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%T = %s, ingin %v", tt.frame, data, tt.want)
+			}
+		}
+	})
+}
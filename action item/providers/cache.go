@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultPlaceDetailsTTL adalah lama detail tempat disimpan oleh
+// CachedPlaces jika TTL kosong.
+const DefaultPlaceDetailsTTL = time.Hour
+
+// CachedPlaces membungkus PlacesProvider dan menyimpan hasil Details per
+// place ID selama TTL, sehingga model yang meminta detail tempat yang sama
+// berulang kali tidak memanggil Places API lagi. SearchText dan SearchNearby
+// diteruskan apa adanya. Aman dipakai dari banyak goroutine.
+type CachedPlaces struct {
+	PlacesProvider
+	// TTL default DefaultPlaceDetailsTTL.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	details map[string]cachedDetails
+	now     func() time.Time
+}
+
+type cachedDetails struct {
+	details *PlaceDetails
+	expires time.Time
+}
+
+// NewCachedPlaces membungkus p dengan cache Details.
+func NewCachedPlaces(p PlacesProvider, ttl time.Duration) *CachedPlaces {
+	return &CachedPlaces{
+		PlacesProvider: p,
+		TTL:            ttl,
+		details:        make(map[string]cachedDetails),
+		now:            time.Now,
+	}
+}
+
+func (c *CachedPlaces) ttl() time.Duration {
+	if c.TTL > 0 {
+		return c.TTL
+	}
+	return DefaultPlaceDetailsTTL
+}
+
+// Details mengembalikan detail dari cache, atau dari provider yang dibungkus
+// jika belum ada atau sudah kedaluwarsa. Error tidak di-cache. Hasilnya
+// dipakai bersama antar pemanggil, jadi jangan diubah.
+func (c *CachedPlaces) Details(ctx context.Context, id string) (*PlaceDetails, error) {
+	c.mu.Lock()
+	entry, ok := c.details[id]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.details, nil
+	}
+
+	details, err := c.PlacesProvider.Details(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, e := range c.details {
+		if !now.Before(e.expires) {
+			delete(c.details, key)
+		}
+	}
+	c.details[id] = cachedDetails{details: details, expires: now.Add(c.ttl())}
+	return details, nil
+}
@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubPlaces menghitung pemanggilan Details per ID. ID di fail selalu gagal.
+type stubPlaces struct {
+	PlacesProvider
+	calls map[string]int
+	fail  map[string]bool
+}
+
+func (s *stubPlaces) Details(ctx context.Context, id string) (*PlaceDetails, error) {
+	s.calls[id]++
+	if s.fail[id] {
+		return nil, errors.New("gagal")
+	}
+	return &PlaceDetails{ID: id, Name: id}, nil
+}
+
+func TestCachedPlacesTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		// after adalah jeda sebelum permintaan kedua.
+		after time.Duration
+		calls int
+	}{
+		{"masih berlaku", 10 * time.Minute, 9 * time.Minute, 1},
+		{"tepat kedaluwarsa", 10 * time.Minute, 10 * time.Minute, 2},
+		{"sudah kedaluwarsa", 10 * time.Minute, time.Hour, 2},
+		{"TTL default masih berlaku", 0, 59 * time.Minute, 1},
+		{"TTL default kedaluwarsa", 0, DefaultPlaceDetailsTTL, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubPlaces{calls: make(map[string]int)}
+			c := NewCachedPlaces(stub, tt.ttl)
+			now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+			c.now = func() time.Time { return now }
+
+			first, err := c.Details(context.Background(), "klotok")
+			if err != nil {
+				t.Fatalf("Details: %v", err)
+			}
+			now = now.Add(tt.after)
+			second, err := c.Details(context.Background(), "klotok")
+			if err != nil {
+				t.Fatalf("Details: %v", err)
+			}
+			if got := stub.calls["klotok"]; got != tt.calls {
+				t.Errorf("provider dipanggil %d kali, ingin %d", got, tt.calls)
+			}
+			if cached := first == second; cached != (tt.calls == 1) {
+				t.Errorf("hasil kedua dari cache = %v, ingin %v", cached, tt.calls == 1)
+			}
+		})
+	}
+}
+
+func TestCachedPlacesErrorNotCached(t *testing.T) {
+	stub := &stubPlaces{calls: make(map[string]int), fail: map[string]bool{"tutup": true}}
+	c := NewCachedPlaces(stub, time.Hour)
+	for range 2 {
+		if _, err := c.Details(context.Background(), "tutup"); err == nil {
+			t.Fatal("Details tidak mengembalikan error provider")
+		}
+	}
+	if got := stub.calls["tutup"]; got != 2 {
+		t.Errorf("provider dipanggil %d kali, ingin 2", got)
+	}
+}
+
+func TestCachedPlacesPrune(t *testing.T) {
+	stub := &stubPlaces{calls: make(map[string]int)}
+	c := NewCachedPlaces(stub, 10*time.Minute)
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Details(context.Background(), "klotok")
+	c.Details(context.Background(), "mang-engking")
+	now = now.Add(15 * time.Minute)
+	c.Details(context.Background(), "merapi-cafe")
+
+	// Entri yang kedaluwarsa dibuang saat entri baru disimpan
+	if len(c.details) != 1 {
+		t.Errorf("cache berisi %d entri, ingin 1: %v", len(c.details), c.details)
+	}
+	if _, ok := c.details["merapi-cafe"]; !ok {
+		t.Errorf("merapi-cafe tidak ada di cache: %v", c.details)
+	}
+}
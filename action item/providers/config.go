@@ -0,0 +1,149 @@
+package providers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Nama backend yang bisa dipilih lewat Config.
+const (
+	BackendOpenWeatherMap   = "openweathermap"
+	BackendWeatherAPI       = "weatherapi"
+	BackendGooglePlaces     = "google"
+	BackendGoogleGeocoding  = "google"
+	BackendExchangerateHost = "exchangeratehost"
+)
+
+// Config memilih backend untuk setiap provider beserta API key-nya.
+type Config struct {
+	WeatherBackend   string
+	PlacesBackend    string
+	GeocodingBackend string
+	FXBackend        string
+
+	OpenWeatherMapKey  string
+	WeatherAPIKey      string
+	GooglePlacesKey    string
+	GoogleGeocodingKey string
+	ExchangeRateKey    string
+
+	// Base URL kosong berarti endpoint produksi. Diisi untuk mengarahkan
+	// provider ke server lain, misalnya fakes.Server saat test.
+	OpenWeatherMapURL  string
+	WeatherAPIURL      string
+	GooglePlacesURL    string
+	GoogleGeocodingURL string
+	ExchangeRateURL    string
+
+	// HTTPClient dipakai semua provider; nil berarti http.DefaultClient.
+	HTTPClient *http.Client
+
+	// PlaceDetailsTTL adalah lama detail tempat di-cache; 0 berarti
+	// DefaultPlaceDetailsTTL.
+	PlaceDetailsTTL time.Duration
+}
+
+// ConfigFromEnv membaca Config dari environment variables:
+//
+//	WEATHER_PROVIDER    openweathermap (default) | weatherapi
+//	PLACES_PROVIDER     google (default)
+//	GEOCODING_PROVIDER  google (default) | openweathermap
+//	FX_PROVIDER         exchangeratehost (default)
+//
+// API key dibaca dari OPEN_WEATHER_API_KEY (atau OPENWEATHERMAP_API_KEY),
+// WEATHER_API_KEY, GOOGLE_PLACE_API_KEY, GOOGLE_GEOCODING_API_KEY (default
+// GOOGLE_PLACE_API_KEY) dan CURRENCY_API_KEY. Base URL bisa diganti lewat
+// OPENWEATHERMAP_BASE_URL, WEATHERAPI_BASE_URL, GOOGLE_PLACES_BASE_URL,
+// GOOGLE_GEOCODING_BASE_URL dan EXCHANGERATE_BASE_URL.
+func ConfigFromEnv() Config {
+	return Config{
+		WeatherBackend:   envOr("WEATHER_PROVIDER", BackendOpenWeatherMap),
+		PlacesBackend:    envOr("PLACES_PROVIDER", BackendGooglePlaces),
+		GeocodingBackend: envOr("GEOCODING_PROVIDER", BackendGoogleGeocoding),
+		FXBackend:        envOr("FX_PROVIDER", BackendExchangerateHost),
+
+		OpenWeatherMapKey:  envOr("OPEN_WEATHER_API_KEY", os.Getenv("OPENWEATHERMAP_API_KEY")),
+		WeatherAPIKey:      os.Getenv("WEATHER_API_KEY"),
+		GooglePlacesKey:    os.Getenv("GOOGLE_PLACE_API_KEY"),
+		GoogleGeocodingKey: envOr("GOOGLE_GEOCODING_API_KEY", os.Getenv("GOOGLE_PLACE_API_KEY")),
+		ExchangeRateKey:    os.Getenv("CURRENCY_API_KEY"),
+
+		OpenWeatherMapURL:  os.Getenv("OPENWEATHERMAP_BASE_URL"),
+		WeatherAPIURL:      os.Getenv("WEATHERAPI_BASE_URL"),
+		GooglePlacesURL:    os.Getenv("GOOGLE_PLACES_BASE_URL"),
+		GoogleGeocodingURL: os.Getenv("GOOGLE_GEOCODING_BASE_URL"),
+		ExchangeRateURL:    os.Getenv("EXCHANGERATE_BASE_URL"),
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// Set berisi provider yang aktif.
+type Set struct {
+	Weather   WeatherProvider
+	Places    PlacesProvider
+	Geocoding GeocodingProvider
+	FX        FXProvider
+}
+
+// New membuat Set sesuai backend di cfg. Backend yang tidak dikenal
+// menghasilkan error; API key yang kosong hanya diperingatkan karena baru
+// dibutuhkan saat provider dipanggil.
+func New(cfg Config) (*Set, error) {
+	set := &Set{}
+
+	switch strings.ToLower(cfg.WeatherBackend) {
+	case BackendOpenWeatherMap, "":
+		warnMissingKey("OPEN_WEATHER_API_KEY", cfg.OpenWeatherMapKey)
+		set.Weather = &OpenWeatherMap{APIKey: cfg.OpenWeatherMapKey, BaseURL: cfg.OpenWeatherMapURL, HTTPClient: cfg.HTTPClient}
+	case BackendWeatherAPI:
+		warnMissingKey("WEATHER_API_KEY", cfg.WeatherAPIKey)
+		set.Weather = &WeatherAPI{APIKey: cfg.WeatherAPIKey, BaseURL: cfg.WeatherAPIURL, HTTPClient: cfg.HTTPClient}
+	default:
+		return nil, fmt.Errorf("weather provider tidak dikenal: %s", cfg.WeatherBackend)
+	}
+
+	switch strings.ToLower(cfg.PlacesBackend) {
+	case BackendGooglePlaces, "":
+		warnMissingKey("GOOGLE_PLACE_API_KEY", cfg.GooglePlacesKey)
+		set.Places = NewCachedPlaces(&GooglePlaces{APIKey: cfg.GooglePlacesKey, BaseURL: cfg.GooglePlacesURL, HTTPClient: cfg.HTTPClient}, cfg.PlaceDetailsTTL)
+	default:
+		return nil, fmt.Errorf("places provider tidak dikenal: %s", cfg.PlacesBackend)
+	}
+
+	switch strings.ToLower(cfg.GeocodingBackend) {
+	case BackendGoogleGeocoding, "":
+		warnMissingKey("GOOGLE_GEOCODING_API_KEY", cfg.GoogleGeocodingKey)
+		set.Geocoding = &GoogleGeocoding{APIKey: cfg.GoogleGeocodingKey, BaseURL: cfg.GoogleGeocodingURL, HTTPClient: cfg.HTTPClient}
+	case BackendOpenWeatherMap:
+		warnMissingKey("OPEN_WEATHER_API_KEY", cfg.OpenWeatherMapKey)
+		set.Geocoding = &OpenWeatherMap{APIKey: cfg.OpenWeatherMapKey, BaseURL: cfg.OpenWeatherMapURL, HTTPClient: cfg.HTTPClient}
+	default:
+		return nil, fmt.Errorf("geocoding provider tidak dikenal: %s", cfg.GeocodingBackend)
+	}
+
+	switch strings.ToLower(cfg.FXBackend) {
+	case BackendExchangerateHost, "":
+		warnMissingKey("CURRENCY_API_KEY", cfg.ExchangeRateKey)
+		set.FX = &ExchangerateHost{APIKey: cfg.ExchangeRateKey, BaseURL: cfg.ExchangeRateURL, HTTPClient: cfg.HTTPClient}
+	default:
+		return nil, fmt.Errorf("FX provider tidak dikenal: %s", cfg.FXBackend)
+	}
+
+	return set, nil
+}
+
+func warnMissingKey(name, value string) {
+	if value == "" {
+		log.Printf("WARNING: %s tidak ditemukan di environment variables", name)
+	}
+}
@@ -0,0 +1,185 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultExchangerateHostURL adalah base URL api.exchangerate.host.
+const DefaultExchangerateHostURL = "https://api.exchangerate.host"
+
+// ExchangerateHost adalah FXProvider untuk api.exchangerate.host.
+type ExchangerateHost struct {
+	APIKey string
+	// BaseURL default DefaultExchangerateHostURL.
+	BaseURL string
+	// HTTPClient default http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// exchangerateHostStatus adalah field status yang sama di semua endpoint
+// exchangerate.host.
+type exchangerateHostStatus struct {
+	Success bool `json:"success"`
+	Error   *struct {
+		Type string `json:"type"`
+		Info string `json:"info"`
+	} `json:"error"`
+}
+
+func (s *exchangerateHostStatus) err() error {
+	if s.Success {
+		return nil
+	}
+	if s.Error != nil {
+		return fmt.Errorf("API call tidak berhasil (success=false), error type: %s, info: %s", s.Error.Type, s.Error.Info)
+	}
+	return fmt.Errorf("API call tidak berhasil (success=false)")
+}
+
+// Struktur data untuk membaca respon dari API exchangerate.host/convert.
+// Versi API yang berbeda menaruh nilai tukar di info.quote, info.rate atau
+// result (untuk amount=1 ketiganya sama). historical dan date diisi jika
+// request memakai parameter date.
+type exchangerateHostResponse struct {
+	exchangerateHostStatus
+	Query struct {
+		From   string  `json:"from"`
+		To     string  `json:"to"`
+		Amount float64 `json:"amount"`
+	} `json:"query"`
+	Info struct {
+		Timestamp int64   `json:"timestamp"`
+		Quote     float64 `json:"quote"`
+		Rate      float64 `json:"rate"`
+	} `json:"info"`
+
+	Historical bool    `json:"historical"`
+	Date       string  `json:"date"`
+	Result     float64 `json:"result"`
+}
+
+func (r *exchangerateHostResponse) rate() float64 {
+	switch {
+	case r.Info.Quote != 0:
+		return r.Info.Quote
+	case r.Info.Rate != 0:
+		return r.Info.Rate
+	}
+	return r.Result
+}
+
+// exchangerateHostTimeframe adalah respon /timeframe: quotes per tanggal
+// dengan key pasangan mata uang seperti "USDIDR".
+type exchangerateHostTimeframe struct {
+	exchangerateHostStatus
+	Quotes map[string]map[string]float64 `json:"quotes"`
+}
+
+func (p *ExchangerateHost) Rate(ctx context.Context, from, to string) (*Rate, error) {
+	return p.convert(ctx, from, to, "")
+}
+
+func (p *ExchangerateHost) RateOn(ctx context.Context, from, to string, date time.Time) (*Rate, error) {
+	return p.convert(ctx, from, to, date.UTC().Format(time.DateOnly))
+}
+
+// convert mengambil kurs 1 unit from ke to lewat /convert, pada tanggal date
+// (YYYY-MM-DD) atau kurs terbaru jika date kosong.
+func (p *ExchangerateHost) convert(ctx context.Context, from, to, date string) (*Rate, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk Exchange Rate tidak tersedia")
+	}
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+
+	params := url.Values{}
+	params.Add("access_key", p.APIKey)
+	params.Add("from", from)
+	params.Add("to", to)
+	params.Add("amount", "1") // Ambil rate untuk 1 unit
+	if date != "" {
+		params.Add("date", date)
+	}
+	log.Printf("Requesting exchange rate API: from=%s to=%s date=%s", from, to, date)
+
+	var data exchangerateHostResponse
+	if err := getJSON(ctx, p.HTTPClient, baseURL(p.BaseURL, DefaultExchangerateHostURL)+"/convert?"+params.Encode(), &data); err != nil {
+		return nil, err
+	}
+	if err := data.err(); err != nil {
+		return nil, err
+	}
+
+	rate := data.rate()
+	if rate == 0 {
+		return nil, fmt.Errorf("rate dari %s ke %s tidak valid atau 0 dari API", from, to)
+	}
+
+	timestamp := time.Now().UTC()
+	if data.Info.Timestamp != 0 {
+		timestamp = time.Unix(data.Info.Timestamp, 0).UTC()
+	}
+	result := &Rate{
+		From:      from,
+		To:        to,
+		Rate:      rate,
+		Timestamp: timestamp,
+		Provider:  "exchangerate.host",
+	}
+	if date != "" {
+		result.Date = date
+		if data.Historical && data.Date != "" {
+			result.Date = data.Date
+		}
+	}
+	return result, nil
+}
+
+func (p *ExchangerateHost) RateSeries(ctx context.Context, from, to string, start, end time.Time) (*RateSeries, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk Exchange Rate tidak tersedia")
+	}
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+	start, end = start.UTC(), end.UTC()
+	if end.Before(start) {
+		return nil, fmt.Errorf("tanggal akhir %s sebelum tanggal awal %s", end.Format(time.DateOnly), start.Format(time.DateOnly))
+	}
+	if days := int(end.Sub(start).Hours() / 24); days > MaxRateSeriesDays {
+		return nil, fmt.Errorf("rentang tanggal maksimal %d hari, diminta %d hari", MaxRateSeriesDays, days)
+	}
+
+	params := url.Values{}
+	params.Add("access_key", p.APIKey)
+	params.Add("source", from)
+	params.Add("currencies", to)
+	params.Add("start_date", start.Format(time.DateOnly))
+	params.Add("end_date", end.Format(time.DateOnly))
+	log.Printf("Requesting exchange rate timeframe API: from=%s to=%s %s..%s", from, to,
+		params.Get("start_date"), params.Get("end_date"))
+
+	var data exchangerateHostTimeframe
+	if err := getJSON(ctx, p.HTTPClient, baseURL(p.BaseURL, DefaultExchangerateHostURL)+"/timeframe?"+params.Encode(), &data); err != nil {
+		return nil, err
+	}
+	if err := data.err(); err != nil {
+		return nil, err
+	}
+
+	points := make([]RatePoint, 0, len(data.Quotes))
+	for date, quotes := range data.Quotes {
+		if rate := quotes[from+to]; rate != 0 {
+			points = append(points, RatePoint{Date: date, Rate: rate})
+		}
+	}
+	// Tanggal YYYY-MM-DD bisa diurutkan sebagai string
+	sort.Slice(points, func(i, j int) bool { return points[i].Date < points[j].Date })
+	return NewRateSeries(from, to, "exchangerate.host", points)
+}
@@ -0,0 +1,42 @@
+package providers
+
+import "math"
+
+// clampForecastDays membatasi days ke 1 sampai MaxForecastDays.
+func clampForecastDays(days int) int {
+	return max(1, min(days, MaxForecastDays))
+}
+
+// dailyFromHourly merangkum entri per jam menjadi prakiraan harian per tanggal
+// lokal. Kondisi harian diambil dari entri dengan peluang presipitasi
+// tertinggi (jika sama, kondisi yang paling sering muncul) supaya hari yang
+// sempat hujan tidak diringkas sebagai cerah. Peluang presipitasi dan angin
+// memakai nilai tertinggi hari itu.
+func dailyFromHourly(entries []HourlyForecast) []DailyForecast {
+	var daily []DailyForecast
+	var counts map[string]int
+	for _, entry := range entries {
+		date := entry.Time.Format("2006-01-02")
+		if len(daily) == 0 || daily[len(daily)-1].Date != date {
+			daily = append(daily, DailyForecast{
+				Date:            date,
+				MinTemperatureC: math.Inf(1),
+				MaxTemperatureC: math.Inf(-1),
+			})
+			counts = make(map[string]int)
+		}
+		day := &daily[len(daily)-1]
+		day.MinTemperatureC = math.Min(day.MinTemperatureC, entry.TemperatureC)
+		day.MaxTemperatureC = math.Max(day.MaxTemperatureC, entry.TemperatureC)
+		day.MaxWindSpeedKph = math.Max(day.MaxWindSpeedKph, entry.WindSpeedKph)
+		counts[entry.Condition]++
+		switch {
+		case entry.PrecipitationPct > day.PrecipitationPct:
+			day.PrecipitationPct = entry.PrecipitationPct
+			day.Condition = entry.Condition
+		case entry.PrecipitationPct == day.PrecipitationPct && counts[entry.Condition] > counts[day.Condition]:
+			day.Condition = entry.Condition
+		}
+	}
+	return daily
+}
@@ -0,0 +1,95 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDailyFromHourly(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	at := func(day, hour int) time.Time { return time.Date(2025, 5, day, hour, 0, 0, 0, wib) }
+
+	tests := []struct {
+		name    string
+		entries []HourlyForecast
+		want    []DailyForecast
+	}{
+		{
+			name: "dikelompokkan per tanggal lokal",
+			entries: []HourlyForecast{
+				{Time: at(1, 19), TemperatureC: 26, Condition: "cerah", WindSpeedKph: 5},
+				// 02.00 WIB masih 1 Mei di UTC, tetapi masuk 2 Mei
+				{Time: at(2, 2), TemperatureC: 23, Condition: "cerah", WindSpeedKph: 4},
+				{Time: at(2, 14), TemperatureC: 31, Condition: "cerah", WindSpeedKph: 12},
+			},
+			want: []DailyForecast{
+				{Date: "2025-05-01", MinTemperatureC: 26, MaxTemperatureC: 26, Condition: "cerah", MaxWindSpeedKph: 5},
+				{Date: "2025-05-02", MinTemperatureC: 23, MaxTemperatureC: 31, Condition: "cerah", MaxWindSpeedKph: 12},
+			},
+		},
+		{
+			name: "kondisi dari peluang hujan tertinggi",
+			entries: []HourlyForecast{
+				{Time: at(2, 7), TemperatureC: 28, PrecipitationPct: 10, Condition: "awan tersebar"},
+				{Time: at(2, 10), TemperatureC: 30, PrecipitationPct: 10, Condition: "awan tersebar"},
+				{Time: at(2, 13), TemperatureC: 25, PrecipitationPct: 80, Condition: "hujan ringan"},
+				{Time: at(2, 16), TemperatureC: 27, PrecipitationPct: 40, Condition: "awan tersebar"},
+			},
+			want: []DailyForecast{
+				{Date: "2025-05-02", MinTemperatureC: 25, MaxTemperatureC: 30, PrecipitationPct: 80, Condition: "hujan ringan"},
+			},
+		},
+		{
+			name: "peluang sama memakai kondisi tersering",
+			entries: []HourlyForecast{
+				{Time: at(3, 1), TemperatureC: 24, Condition: "cerah"},
+				{Time: at(3, 4), TemperatureC: 24, Condition: "berawan"},
+				{Time: at(3, 7), TemperatureC: 27, Condition: "berawan"},
+			},
+			want: []DailyForecast{
+				{Date: "2025-05-03", MinTemperatureC: 24, MaxTemperatureC: 27, Condition: "berawan"},
+			},
+		},
+		{
+			name: "kosong",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dailyFromHourly(tt.entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dailyFromHourly = %+v, ingin %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWeatherAPIZone(t *testing.T) {
+	epoch := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC).Unix()
+	tests := []struct {
+		name      string
+		tzID      string
+		localtime string
+		epoch     int64
+		zone      string
+		offset    time.Duration
+	}{
+		{"zona dikenal", "Asia/Jakarta", "2025-05-01 07:00", epoch, "WIB", 7 * time.Hour},
+		{"zona tidak dikenal dari localtime", "Asia/Nusantara", "2025-05-01 08:00", epoch, "Asia/Nusantara", 8 * time.Hour},
+		// localtime tanpa detik, jadi epoch 20 detik setelah menit tetap +05.30
+		{"offset dibulatkan", "Asia/Tidak_Ada", "2025-05-01 05:30", epoch + 20, "Asia/Tidak_Ada", 5*time.Hour + 30*time.Minute},
+		{"offset negatif", "America/Tidak_Ada", "2025-04-30 20:00", epoch, "America/Tidak_Ada", -4 * time.Hour},
+		{"localtime rusak", "Asia/Nusantara", "kemarin", epoch, "UTC", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := weatherAPIZone(tt.tzID, tt.localtime, tt.epoch)
+			name, offset := time.Unix(tt.epoch, 0).In(zone).Zone()
+			if name != tt.zone || time.Duration(offset)*time.Second != tt.offset {
+				t.Errorf("weatherAPIZone(%s, %s) = %s %ds, ingin %s %v", tt.tzID, tt.localtime, name, offset, tt.zone, tt.offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package providers
+
+import "math"
+
+// earthRadiusKm adalah jari-jari rata-rata bumi untuk rumus haversine.
+const earthRadiusKm = 6371.0
+
+// DistanceKm menghitung jarak lingkaran besar (haversine) antara a dan b dalam
+// kilometer.
+func DistanceKm(a, b Coordinates) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(b.Latitude - a.Latitude)
+	dLon := toRad(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(a.Latitude))*math.Cos(toRad(b.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// compassPoints adalah 16 arah mata angin searah jarum jam dari utara.
+var compassPoints = [...]string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// CompassDirection mengubah arah dalam derajat (0 = utara) menjadi salah satu
+// dari 16 arah mata angin, misal 225 menjadi "SW".
+func CompassDirection(deg int) string {
+	i := int(math.Round(float64(((deg%360)+360)%360)/22.5)) % len(compassPoints)
+	return compassPoints[i]
+}
@@ -0,0 +1,34 @@
+package providers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Coordinates
+		want float64
+	}{
+		{"titik sama", Coordinates{-7.7956, 110.3695}, Coordinates{-7.7956, 110.3695}, 0},
+		{"satu derajat di khatulistiwa", Coordinates{0, 100}, Coordinates{0, 101}, 111.195},
+		{"satu derajat lintang", Coordinates{-7, 110}, Coordinates{-8, 110}, 111.195},
+		{"kutub ke kutub", Coordinates{90, 0}, Coordinates{-90, 0}, 20015.087},
+		{"antipoda", Coordinates{0, 0}, Coordinates{0, 180}, 20015.087},
+		{"melewati garis tanggal", Coordinates{0, 179.5}, Coordinates{0, -179.5}, 111.195},
+		{"Tugu Jogja ke Kopi Klotok", Coordinates{-7.7829, 110.3671}, Coordinates{-7.6458, 110.4275}, 16.634},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DistanceKm(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("DistanceKm(%v, %v) = %.4f, ingin %.3f", tt.a, tt.b, got, tt.want)
+			}
+			if back := DistanceKm(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+				t.Errorf("DistanceKm tidak simetris: %v dan %v", got, back)
+			}
+		})
+	}
+}
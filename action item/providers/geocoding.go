@@ -0,0 +1,39 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+)
+
+// Locate mengembalikan hasil geocoding teratas untuk query, misal "Sleman",
+// "Malioboro" atau alamat lengkap. Error jika tidak ada hasil.
+func Locate(ctx context.Context, g GeocodingProvider, query string) (*GeocodeResult, error) {
+	if g == nil {
+		return nil, fmt.Errorf("geocoding provider tidak tersedia")
+	}
+	results, err := g.Geocode(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("lokasi tidak ditemukan: %s", query)
+	}
+	return &results[0], nil
+}
+
+// PlaceName mengembalikan nama tempat untuk c lewat reverse geocoding, misal
+// "Sleman, Daerah Istimewa Yogyakarta". Hasil kosong jika g nil atau tidak ada
+// hasil.
+func PlaceName(ctx context.Context, g GeocodingProvider, c Coordinates) (string, error) {
+	if g == nil {
+		return "", nil
+	}
+	results, err := g.ReverseGeocode(ctx, c)
+	if err != nil || len(results) == 0 {
+		return "", err
+	}
+	if results[0].Address != "" {
+		return results[0].Address, nil
+	}
+	return results[0].Name, nil
+}
@@ -0,0 +1,107 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DefaultGoogleGeocodingURL adalah base URL Google Geocoding API.
+const DefaultGoogleGeocodingURL = "https://maps.googleapis.com"
+
+// GoogleGeocoding adalah GeocodingProvider untuk Google Geocoding API. Selain
+// kota, API ini juga mengenali landmark dan alamat jalan.
+type GoogleGeocoding struct {
+	APIKey string
+	// BaseURL default DefaultGoogleGeocodingURL.
+	BaseURL string
+	// HTTPClient default http.DefaultClient.
+	HTTPClient *http.Client
+	// Language adalah bahasa hasil, misal "id"; kosong berarti default API.
+	Language string
+}
+
+type googleGeocodingResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Results      []struct {
+		FormattedAddress  string `json:"formatted_address"`
+		AddressComponents []struct {
+			LongName  string   `json:"long_name"`
+			ShortName string   `json:"short_name"`
+			Types     []string `json:"types"`
+		} `json:"address_components"`
+		Geometry struct {
+			Location struct {
+				Lat float64 `json:"lat"`
+				Lng float64 `json:"lng"`
+			} `json:"location"`
+		} `json:"geometry"`
+	} `json:"results"`
+}
+
+func (p *GoogleGeocoding) Geocode(ctx context.Context, query string) ([]GeocodeResult, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query geocoding kosong")
+	}
+	params := url.Values{}
+	params.Add("address", query)
+	return p.request(ctx, params)
+}
+
+func (p *GoogleGeocoding) ReverseGeocode(ctx context.Context, c Coordinates) ([]GeocodeResult, error) {
+	params := url.Values{}
+	params.Add("latlng", strconv.FormatFloat(c.Latitude, 'f', -1, 64)+","+strconv.FormatFloat(c.Longitude, 'f', -1, 64))
+	return p.request(ctx, params)
+}
+
+func (p *GoogleGeocoding) request(ctx context.Context, params url.Values) ([]GeocodeResult, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk Google Geocoding tidak tersedia")
+	}
+	if p.Language != "" {
+		params.Add("language", p.Language)
+	}
+	log.Printf("Requesting geocoding API (Google): %s", params.Encode())
+	params.Add("key", p.APIKey)
+
+	var data googleGeocodingResponse
+	if err := getJSON(ctx, p.HTTPClient, baseURL(p.BaseURL, DefaultGoogleGeocodingURL)+"/maps/api/geocode/json?"+params.Encode(), &data); err != nil {
+		return nil, err
+	}
+	// Geocoding API mengembalikan 200 juga untuk error; statusnya ada di body
+	switch data.Status {
+	case "OK", "ZERO_RESULTS":
+	default:
+		return nil, fmt.Errorf("Geocoding API mengembalikan status error: %s %s", data.Status, data.ErrorMessage)
+	}
+
+	results := make([]GeocodeResult, 0, len(data.Results))
+	for _, r := range data.Results {
+		result := GeocodeResult{
+			Address: r.FormattedAddress,
+			Coordinates: Coordinates{
+				Latitude:  r.Geometry.Location.Lat,
+				Longitude: r.Geometry.Location.Lng,
+			},
+		}
+		for i, component := range r.AddressComponents {
+			if i == 0 {
+				result.Name = component.LongName
+			}
+			for _, t := range component.Types {
+				if t == "country" {
+					result.Country = component.ShortName
+				}
+			}
+		}
+		if result.Name == "" {
+			result.Name = r.FormattedAddress
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,292 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+)
+
+// DefaultGooglePlacesURL adalah base URL Google Places API (New).
+const DefaultGooglePlacesURL = "https://places.googleapis.com"
+
+// placesFieldMask adalah field yang diminta dari searchText dan searchNearby;
+// location dibutuhkan untuk menghitung jarak ke pengguna.
+const placesFieldMask = "places.id,places.displayName,places.formattedAddress,places.priceLevel," +
+	"places.location,places.rating,places.userRatingCount,places.googleMapsUri," +
+	"places.primaryType,places.currentOpeningHours.openNow"
+
+// placeDetailsFieldMask adalah field yang diminta dari Place Details.
+const placeDetailsFieldMask = "id,displayName,formattedAddress,location,rating,userRatingCount," +
+	"googleMapsUri,internationalPhoneNumber,websiteUri,currentOpeningHours,regularOpeningHours,reviews"
+
+// maxPlacesRadiusM adalah radius maksimum locationBias/locationRestriction
+// yang diterima Places API.
+const maxPlacesRadiusM = 50000
+
+// GooglePlaces adalah PlacesProvider untuk Google Places API (New).
+type GooglePlaces struct {
+	APIKey string
+	// BaseURL default DefaultGooglePlacesURL.
+	BaseURL string
+	// HTTPClient default http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// googleOpeningHours adalah currentOpeningHours/regularOpeningHours.
+type googleOpeningHours struct {
+	OpenNow             *bool    `json:"openNow"`
+	WeekdayDescriptions []string `json:"weekdayDescriptions"`
+}
+
+// googlePlace adalah satu Place dari Places API (New), baik dari pencarian
+// maupun Place Details.
+type googlePlace struct {
+	ID          string `json:"id"`
+	DisplayName struct {
+		Text string `json:"text"`
+	} `json:"displayName"`
+	FormattedAddress string `json:"formattedAddress"`
+	PriceLevel       string `json:"priceLevel"`
+	Location         *struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	} `json:"location"`
+	Rating                   float64             `json:"rating"`
+	UserRatingCount          int                 `json:"userRatingCount"`
+	GoogleMapsURI            string              `json:"googleMapsUri"`
+	PrimaryType              string              `json:"primaryType"`
+	InternationalPhoneNumber string              `json:"internationalPhoneNumber"`
+	WebsiteURI               string              `json:"websiteUri"`
+	CurrentOpeningHours      *googleOpeningHours `json:"currentOpeningHours"`
+	RegularOpeningHours      *googleOpeningHours `json:"regularOpeningHours"`
+	Reviews                  []struct {
+		Rating float64 `json:"rating"`
+		Text   *struct {
+			Text string `json:"text"`
+		} `json:"text"`
+		OriginalText *struct {
+			Text string `json:"text"`
+		} `json:"originalText"`
+		RelativePublishTimeDescription string `json:"relativePublishTimeDescription"`
+		AuthorAttribution              struct {
+			DisplayName string `json:"displayName"`
+			URI         string `json:"uri"`
+			PhotoURI    string `json:"photoUri"`
+		} `json:"authorAttribution"`
+	} `json:"reviews"`
+}
+
+type googlePlacesResponse struct {
+	Places []googlePlace `json:"places"`
+}
+
+func (g *googlePlace) location() *Coordinates {
+	if g.Location == nil {
+		return nil
+	}
+	return &Coordinates{Latitude: g.Location.Latitude, Longitude: g.Location.Longitude}
+}
+
+func (g *googlePlace) openNow() *bool {
+	if g.CurrentOpeningHours == nil || g.CurrentOpeningHours.OpenNow == nil {
+		return nil
+	}
+	openNow := *g.CurrentOpeningHours.OpenNow
+	return &openNow
+}
+
+func (g *googlePlace) place() Place {
+	return Place{
+		ID:              g.ID,
+		Name:            g.DisplayName.Text,
+		Address:         g.FormattedAddress,
+		PriceLevel:      g.PriceLevel,
+		Location:        g.location(),
+		Rating:          g.Rating,
+		UserRatingCount: g.UserRatingCount,
+		GoogleMapsURI:   g.GoogleMapsURI,
+		Type:            g.PrimaryType,
+		OpenNow:         g.openNow(),
+	}
+}
+
+func (g *googlePlace) details() *PlaceDetails {
+	d := &PlaceDetails{
+		ID:              g.ID,
+		Name:            g.DisplayName.Text,
+		Address:         g.FormattedAddress,
+		Location:        g.location(),
+		Rating:          g.Rating,
+		UserRatingCount: g.UserRatingCount,
+		GoogleMapsURI:   g.GoogleMapsURI,
+		Phone:           g.InternationalPhoneNumber,
+		Website:         g.WebsiteURI,
+		OpenNow:         g.openNow(),
+	}
+	// Jam buka minggu ini lebih akurat (hari libur), jatuh ke jam reguler
+	switch {
+	case g.CurrentOpeningHours != nil && len(g.CurrentOpeningHours.WeekdayDescriptions) > 0:
+		d.OpeningHours = g.CurrentOpeningHours.WeekdayDescriptions
+	case g.RegularOpeningHours != nil:
+		d.OpeningHours = g.RegularOpeningHours.WeekdayDescriptions
+	}
+	for _, r := range g.Reviews {
+		review := Review{
+			AuthorName:     r.AuthorAttribution.DisplayName,
+			AuthorURI:      r.AuthorAttribution.URI,
+			AuthorPhotoURI: r.AuthorAttribution.PhotoURI,
+			Rating:         r.Rating,
+			RelativeTime:   r.RelativePublishTimeDescription,
+		}
+		switch {
+		case r.Text != nil:
+			review.Text = r.Text.Text
+		case r.OriginalText != nil:
+			review.Text = r.OriginalText.Text
+		}
+		d.Reviews = append(d.Reviews, review)
+	}
+	return d
+}
+
+// googlePlacesCircle adalah circle untuk locationBias/locationRestriction.
+type googlePlacesCircle struct {
+	Circle struct {
+		Center Coordinates `json:"center"`
+		Radius float64     `json:"radius"`
+	} `json:"circle"`
+}
+
+func newGooglePlacesCircle(center Coordinates, radiusM float64) *googlePlacesCircle {
+	c := &googlePlacesCircle{}
+	c.Circle.Center = center
+	c.Circle.Radius = math.Min(radiusM, maxPlacesRadiusM)
+	return c
+}
+
+type googlePlacesSearchTextRequest struct {
+	TextQuery    string              `json:"textQuery"`
+	LocationBias *googlePlacesCircle `json:"locationBias,omitempty"`
+	IncludedType string              `json:"includedType,omitempty"`
+	MinRating    float64             `json:"minRating,omitempty"`
+	OpenNow      bool                `json:"openNow,omitempty"`
+	PriceLevels  []string            `json:"priceLevels,omitempty"`
+}
+
+type googlePlacesSearchNearbyRequest struct {
+	IncludedTypes       []string            `json:"includedTypes,omitempty"`
+	MaxResultCount      int                 `json:"maxResultCount,omitempty"`
+	RankPreference      string              `json:"rankPreference,omitempty"`
+	LocationRestriction *googlePlacesCircle `json:"locationRestriction"`
+}
+
+func (p *GooglePlaces) SearchText(ctx context.Context, q PlaceQuery) ([]Place, error) {
+	body := googlePlacesSearchTextRequest{
+		TextQuery:    q.Text,
+		IncludedType: q.Type,
+		MinRating:    q.MinRating,
+		OpenNow:      q.OpenNow,
+		PriceLevels:  q.PriceLevels,
+	}
+	if q.Bias != nil {
+		radius := q.BiasRadiusM
+		if radius <= 0 {
+			radius = DefaultBiasRadiusM
+		}
+		body.LocationBias = newGooglePlacesCircle(*q.Bias, radius)
+	}
+	return p.search(ctx, "/v1/places:searchText", q.Text, body)
+}
+
+func (p *GooglePlaces) SearchNearby(ctx context.Context, q NearbyQuery) ([]Place, error) {
+	body := googlePlacesSearchNearbyRequest{
+		MaxResultCount:      q.MaxResults,
+		LocationRestriction: newGooglePlacesCircle(q.Center, q.RadiusM),
+	}
+	if q.Type != "" {
+		body.IncludedTypes = []string{q.Type}
+	}
+	if q.RankByDistance {
+		body.RankPreference = "DISTANCE"
+	}
+	places, err := p.search(ctx, "/v1/places:searchNearby", q.Type, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// searchNearby tidak punya filter rating dan jam buka, jadi disaring di sini
+	filtered := places[:0]
+	for _, place := range places {
+		if place.Rating < q.MinRating {
+			continue
+		}
+		if q.OpenNow && (place.OpenNow == nil || !*place.OpenNow) {
+			continue
+		}
+		filtered = append(filtered, place)
+	}
+	return filtered, nil
+}
+
+// search mengirim body ke endpoint Places (searchText/searchNearby) dan
+// menormalisasi hasilnya.
+func (p *GooglePlaces) search(ctx context.Context, path, query string, body any) ([]Place, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk Google Places tidak tersedia")
+	}
+	searchURL := baseURL(p.BaseURL, DefaultGooglePlacesURL) + path
+	log.Printf("Requesting place API: %s (query: %s)", searchURL, query)
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error encode request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, searchURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("error membuat request: %v", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("X-Goog-Api-Key", p.APIKey)
+	req.Header.Add("X-Goog-FieldMask", placesFieldMask)
+
+	var data googlePlacesResponse
+	if err := doJSON(p.HTTPClient, req, &data); err != nil {
+		return nil, err
+	}
+
+	places := make([]Place, 0, len(data.Places))
+	for i := range data.Places {
+		places = append(places, data.Places[i].place())
+	}
+	return places, nil
+}
+
+func (p *GooglePlaces) Details(ctx context.Context, id string) (*PlaceDetails, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk Google Places tidak tersedia")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("place ID kosong")
+	}
+	detailsURL := baseURL(p.BaseURL, DefaultGooglePlacesURL) + "/v1/places/" + url.PathEscape(id)
+	log.Printf("Requesting place details API: %s", detailsURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, detailsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error membuat request: %v", err)
+	}
+	req.Header.Add("X-Goog-Api-Key", p.APIKey)
+	req.Header.Add("X-Goog-FieldMask", placeDetailsFieldMask)
+
+	var data googlePlace
+	if err := doJSON(p.HTTPClient, req, &data); err != nil {
+		return nil, err
+	}
+	return data.details(), nil
+}
@@ -0,0 +1,214 @@
+package providers_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"proxy/fakes"
+	"proxy/providers"
+)
+
+// startPlaces menjalankan fakes.Server dan mengembalikan GooglePlaces yang
+// diarahkan ke server itu.
+func startPlaces(t *testing.T) (*fakes.Server, *providers.GooglePlaces) {
+	t.Helper()
+	api := fakes.NewServer()
+	t.Cleanup(api.Close)
+	return api, &providers.GooglePlaces{APIKey: fakes.APIKey, BaseURL: api.URL, HTTPClient: api.Client()}
+}
+
+// lastBody mengembalikan body JSON request terakhir ke path sebagai map.
+func lastBody(t *testing.T, api *fakes.Server, path string) map[string]any {
+	t.Helper()
+	requests := api.Requests()
+	if len(requests) == 0 || requests[len(requests)-1].Path != path {
+		t.Fatalf("request = %+v, ingin ke %s", requests, path)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(requests[len(requests)-1].Body, &body); err != nil {
+		t.Fatalf("body %s: %v", requests[len(requests)-1].Body, err)
+	}
+	return body
+}
+
+func decode(t *testing.T, s string) map[string]any {
+	t.Helper()
+	var v map[string]any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("json %s: %v", s, err)
+	}
+	return v
+}
+
+func placeIDs(places []providers.Place) string {
+	var ids []string
+	for _, p := range places {
+		ids = append(ids, strings.TrimPrefix(p.ID, "fake-place-"))
+	}
+	return strings.Join(ids, ",")
+}
+
+var tugu = providers.Coordinates{Latitude: -7.7829, Longitude: 110.3671}
+
+func TestGooglePlacesSearchText(t *testing.T) {
+	tests := []struct {
+		name  string
+		query providers.PlaceQuery
+		body  string
+		want  string
+	}{
+		{
+			name:  "teks saja",
+			query: providers.PlaceQuery{Text: "kuliner Sleman"},
+			body:  `{"textQuery": "kuliner Sleman"}`,
+			want:  "klotok,mang-engking,merapi-cafe",
+		},
+		{
+			name:  "bias radius default",
+			query: providers.PlaceQuery{Text: "kopi", Bias: &tugu},
+			body:  `{"textQuery": "kopi", "locationBias": {"circle": {"center": {"latitude": -7.7829, "longitude": 110.3671}, "radius": 5000}}}`,
+			want:  "klotok,mang-engking,merapi-cafe",
+		},
+		{
+			name:  "bias radius dibatasi",
+			query: providers.PlaceQuery{Text: "kopi", Bias: &tugu, BiasRadiusM: 80000},
+			body:  `{"textQuery": "kopi", "locationBias": {"circle": {"center": {"latitude": -7.7829, "longitude": 110.3671}, "radius": 50000}}}`,
+			want:  "klotok,mang-engking,merapi-cafe",
+		},
+		{
+			name:  "tipe",
+			query: providers.PlaceQuery{Text: "kopi", Type: "cafe"},
+			body:  `{"textQuery": "kopi", "includedType": "cafe"}`,
+			want:  "merapi-cafe",
+		},
+		{
+			name:  "rating minimum",
+			query: providers.PlaceQuery{Text: "kopi", MinRating: 4.5},
+			body:  `{"textQuery": "kopi", "minRating": 4.5}`,
+			want:  "klotok,mang-engking",
+		},
+		{
+			name:  "buka sekarang",
+			query: providers.PlaceQuery{Text: "kopi", OpenNow: true},
+			body:  `{"textQuery": "kopi", "openNow": true}`,
+			want:  "klotok,merapi-cafe",
+		},
+		{
+			name:  "harga",
+			query: providers.PlaceQuery{Text: "kopi", PriceLevels: []string{"PRICE_LEVEL_INEXPENSIVE", "PRICE_LEVEL_MODERATE"}},
+			body:  `{"textQuery": "kopi", "priceLevels": ["PRICE_LEVEL_INEXPENSIVE", "PRICE_LEVEL_MODERATE"]}`,
+			want:  "klotok,mang-engking",
+		},
+		{
+			name:  "semua filter",
+			query: providers.PlaceQuery{Text: "kopi", Type: "restaurant", MinRating: 4, OpenNow: true, PriceLevels: []string{"PRICE_LEVEL_INEXPENSIVE"}},
+			body:  `{"textQuery": "kopi", "includedType": "restaurant", "minRating": 4, "openNow": true, "priceLevels": ["PRICE_LEVEL_INEXPENSIVE"]}`,
+			want:  "klotok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, places := startPlaces(t)
+			got, err := places.SearchText(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("SearchText: %v", err)
+			}
+			if body := lastBody(t, api, "/v1/places:searchText"); !reflect.DeepEqual(body, decode(t, tt.body)) {
+				t.Errorf("body = %v, ingin %s", body, tt.body)
+			}
+			if ids := placeIDs(got); ids != tt.want {
+				t.Errorf("hasil = %s, ingin %s", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestGooglePlacesSearchNearby(t *testing.T) {
+	tests := []struct {
+		name  string
+		query providers.NearbyQuery
+		body  string
+		want  string
+	}{
+		{
+			name:  "tanpa filter",
+			query: providers.NearbyQuery{Center: tugu, RadiusM: 2000},
+			body:  `{"locationRestriction": {"circle": {"center": {"latitude": -7.7829, "longitude": 110.3671}, "radius": 2000}}}`,
+			want:  "klotok,mang-engking,merapi-cafe",
+		},
+		{
+			name:  "tipe dan urut jarak",
+			query: providers.NearbyQuery{Center: tugu, RadiusM: 2000, Type: "restaurant", MaxResults: 5, RankByDistance: true},
+			body:  `{"includedTypes": ["restaurant"], "maxResultCount": 5, "rankPreference": "DISTANCE", "locationRestriction": {"circle": {"center": {"latitude": -7.7829, "longitude": 110.3671}, "radius": 2000}}}`,
+			want:  "klotok,mang-engking",
+		},
+		{
+			name:  "radius dibatasi",
+			query: providers.NearbyQuery{Center: tugu, RadiusM: 100000},
+			body:  `{"locationRestriction": {"circle": {"center": {"latitude": -7.7829, "longitude": 110.3671}, "radius": 50000}}}`,
+			want:  "klotok,mang-engking,merapi-cafe",
+		},
+		// searchNearby tidak menerima minRating dan openNow, jadi keduanya
+		// tidak dikirim dan hasilnya disaring di client
+		{
+			name:  "rating minimum",
+			query: providers.NearbyQuery{Center: tugu, RadiusM: 2000, MinRating: 4.5},
+			body:  `{"locationRestriction": {"circle": {"center": {"latitude": -7.7829, "longitude": 110.3671}, "radius": 2000}}}`,
+			want:  "klotok,mang-engking",
+		},
+		{
+			name:  "buka sekarang",
+			query: providers.NearbyQuery{Center: tugu, RadiusM: 2000, Type: "restaurant", OpenNow: true},
+			body:  `{"includedTypes": ["restaurant"], "locationRestriction": {"circle": {"center": {"latitude": -7.7829, "longitude": 110.3671}, "radius": 2000}}}`,
+			want:  "klotok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, places := startPlaces(t)
+			got, err := places.SearchNearby(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("SearchNearby: %v", err)
+			}
+			if body := lastBody(t, api, "/v1/places:searchNearby"); !reflect.DeepEqual(body, decode(t, tt.body)) {
+				t.Errorf("body = %v, ingin %s", body, tt.body)
+			}
+			if ids := placeIDs(got); ids != tt.want {
+				t.Errorf("hasil = %s, ingin %s", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestGooglePlacesResponse(t *testing.T) {
+	_, places := startPlaces(t)
+	got, err := places.SearchText(context.Background(), providers.PlaceQuery{Text: "kopi", Type: "cafe"})
+	if err != nil {
+		t.Fatalf("SearchText: %v", err)
+	}
+	open := true
+	want := []providers.Place{{
+		ID:              "fake-place-merapi-cafe",
+		Name:            "Merapi View Cafe",
+		Address:         "Jl. Kaliurang KM 22, Sleman, Yogyakarta",
+		PriceLevel:      "PRICE_LEVEL_EXPENSIVE",
+		Location:        &providers.Coordinates{Latitude: -7.6, Longitude: 110.423},
+		Rating:          4.2,
+		UserRatingCount: 1520,
+		GoogleMapsURI:   "https://maps.google.com/?q=place_id:fake-place-merapi-cafe",
+		Type:            "cafe",
+		OpenNow:         &open,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchText = %+v, ingin %+v", got, want)
+	}
+
+	if got, err := (&providers.GooglePlaces{BaseURL: places.BaseURL}).SearchText(context.Background(), providers.PlaceQuery{Text: "kopi"}); err == nil {
+		t.Errorf("SearchText tanpa API key = %+v, ingin error", got)
+	}
+}
@@ -0,0 +1,59 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DefaultGoogleSearchURL adalah base URL Google Custom Search JSON API.
+const DefaultGoogleSearchURL = "https://www.googleapis.com"
+
+// maxSearchResults adalah jumlah hasil yang diminta dari Custom Search.
+const maxSearchResults = 5
+
+// SearchResult adalah satu hasil pencarian web.
+type SearchResult struct {
+	Title   string `json:"title"`
+	Link    string `json:"link"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchResults adalah hasil GoogleSearch.Search.
+type SearchResults struct {
+	Items []SearchResult `json:"items"`
+}
+
+// GoogleSearch memanggil Google Custom Search JSON API.
+type GoogleSearch struct {
+	APIKey string
+	// CX adalah ID Programmable Search Engine.
+	CX string
+	// BaseURL default DefaultGoogleSearchURL.
+	BaseURL string
+	// HTTPClient default http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Search mencari query di web dan mengembalikan maksimal 5 hasil teratas.
+func (s *GoogleSearch) Search(ctx context.Context, query string) (*SearchResults, error) {
+	if s.APIKey == "" || s.CX == "" {
+		return nil, fmt.Errorf("API key atau CX untuk Google Search tidak tersedia")
+	}
+
+	params := url.Values{}
+	params.Add("key", s.APIKey)
+	params.Add("cx", s.CX)
+	params.Add("q", query)
+	params.Add("num", strconv.Itoa(maxSearchResults))
+	log.Printf("Requesting Google Search API untuk query: %s", query)
+
+	results := &SearchResults{Items: []SearchResult{}}
+	if err := getJSON(ctx, s.HTTPClient, baseURL(s.BaseURL, DefaultGoogleSearchURL)+"/customsearch/v1?"+params.Encode(), results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
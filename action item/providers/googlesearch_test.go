@@ -0,0 +1,38 @@
+package providers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"proxy/fakes"
+	"proxy/providers"
+)
+
+func TestGoogleSearch(t *testing.T) {
+	api := fakes.NewServer()
+	defer api.Close()
+	search := &providers.GoogleSearch{APIKey: fakes.APIKey, CX: "fake-cx", BaseURL: api.URL, HTTPClient: api.Client()}
+
+	got, err := search.Search(context.Background(), "candi borobudur")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(got.Items) != 1 || got.Items[0].Title != "Hasil pencarian untuk candi borobudur" || got.Items[0].Link == "" {
+		t.Errorf("Items = %+v", got.Items)
+	}
+	query := api.Requests()[0].Query
+	if query.Get("cx") != "fake-cx" || query.Get("num") != "5" || query.Get("q") != "candi borobudur" {
+		t.Errorf("query = %v", query)
+	}
+
+	// Status error memakai pesan yang sama dengan provider lain
+	api.Fail("/customsearch/v1", 500)
+	if _, err := search.Search(context.Background(), "x"); err == nil || !strings.Contains(err.Error(), "API mengembalikan status error: 500") {
+		t.Errorf("Search dengan status 500 = %v", err)
+	}
+
+	if _, err := (&providers.GoogleSearch{BaseURL: api.URL}).Search(context.Background(), "x"); err == nil {
+		t.Error("Search tanpa API key berhasil")
+	}
+}
@@ -0,0 +1,59 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// httpClient mengembalikan c, atau http.DefaultClient jika c nil.
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
+
+// baseURL mengembalikan base tanpa "/" di akhir, atau fallback jika base kosong.
+func baseURL(base, fallback string) string {
+	if base == "" {
+		return fallback
+	}
+	return strings.TrimRight(base, "/")
+}
+
+// doJSON mengirim req lewat client dan mendecode body JSON ke out. Status
+// selain 200 dikembalikan sebagai error beserta body-nya.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	res, err := httpClient(client).Do(req)
+	if err != nil {
+		return fmt.Errorf("error mengirim request: %v", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error membaca body response: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("API mengembalikan status error: %s, Body: %s", res.Status, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		log.Printf("Error unmarshal response body: %s", string(body))
+		return fmt.Errorf("error unmarshal response: %v", err)
+	}
+	return nil
+}
+
+// getJSON adalah doJSON untuk request GET ke url.
+func getJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error membuat request: %v", err)
+	}
+	return doJSON(client, req, out)
+}
@@ -0,0 +1,110 @@
+package providers
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minorUnits adalah jumlah digit desimal mata uang yang tidak 2 (ISO 4217).
+// IDR secara resmi punya 2 desimal, tetapi sen tidak dipakai lagi sehingga
+// dibulatkan ke rupiah penuh.
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IDR": 0, "ISK": 0, "JPY": 0,
+	"KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits mengembalikan jumlah digit desimal mata uang code, misal 0 untuk
+// IDR dan JPY, 2 untuk USD dan 3 untuk KWD.
+func MinorUnits(code string) int {
+	if n, ok := minorUnits[strings.ToUpper(code)]; ok {
+		return n
+	}
+	return 2
+}
+
+// Conversion adalah hasil konversi sejumlah uang. Amount dan ConvertedAmount
+// berupa string desimal supaya tidak ada galat float saat ditampilkan.
+type Conversion struct {
+	From            string    `json:"from"`
+	To              string    `json:"to"`
+	Amount          string    `json:"amount"`
+	ConvertedAmount string    `json:"converted_amount"` // dibulatkan ke MinorUnits(To)
+	Rate            float64   `json:"rate"`
+	Timestamp       time.Time `json:"timestamp"`
+	Provider        string    `json:"provider"`
+}
+
+// Convert menghitung amount × rate.Rate dengan aritmetika desimal (big.Rat)
+// lalu membulatkan hasilnya ke minor unit mata uang tujuan, dengan nilai
+// tengah dibulatkan menjauhi nol. amount adalah angka desimal seperti "50"
+// atau "12.5" dan lebih dulu dibulatkan ke minor unit mata uang asal.
+func Convert(amount string, rate *Rate) (*Conversion, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return nil, fmt.Errorf("jumlah tidak valid: %q", amount)
+	}
+	if value.Sign() < 0 {
+		return nil, fmt.Errorf("jumlah tidak boleh negatif: %s", amount)
+	}
+	// FormatFloat 'f' -1 memberi representasi desimal terpendek dari rate,
+	// jadi 0.88 dihitung sebagai 88/100, bukan nilai biner float64-nya
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate.Rate, 'f', -1, 64))
+	if !ok {
+		return nil, fmt.Errorf("rate tidak valid: %v", rate.Rate)
+	}
+
+	// Jumlah dibulatkan ke minor unit mata uang asal sebelum dikalikan, jadi
+	// Amount × Rate yang dilaporkan tetap sama dengan ConvertedAmount
+	amountText := value.FloatString(MinorUnits(rate.From))
+	value.SetString(amountText)
+
+	converted := new(big.Rat).Mul(value, r)
+	return &Conversion{
+		From:            rate.From,
+		To:              rate.To,
+		Amount:          amountText,
+		ConvertedAmount: converted.FloatString(MinorUnits(rate.To)),
+		Rate:            rate.Rate,
+		Timestamp:       rate.Timestamp,
+		Provider:        rate.Provider,
+	}, nil
+}
+
+// NewRateSeries membuat RateSeries dari points (urut per tanggal) dan
+// menghitung min, max, rata-rata dan perubahan. Error jika points kosong.
+func NewRateSeries(from, to, provider string, points []RatePoint) (*RateSeries, error) {
+	if len(points) == 0 {
+		return nil, fmt.Errorf("tidak ada data kurs %s ke %s pada rentang tersebut", from, to)
+	}
+	series := &RateSeries{
+		From:      from,
+		To:        to,
+		StartDate: points[0].Date,
+		EndDate:   points[len(points)-1].Date,
+		Rates:     points,
+		Min:       points[0],
+		Max:       points[0],
+		Provider:  provider,
+	}
+	var sum float64
+	for _, p := range points {
+		sum += p.Rate
+		if p.Rate < series.Min.Rate {
+			series.Min = p
+		}
+		if p.Rate > series.Max.Rate {
+			series.Max = p
+		}
+	}
+	series.Average = sum / float64(len(points))
+	if first := points[0].Rate; first != 0 {
+		series.ChangePct = math.Round((points[len(points)-1].Rate-first)/first*10000) / 100
+	}
+	return series, nil
+}
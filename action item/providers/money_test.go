@@ -0,0 +1,133 @@
+package providers
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMinorUnits(t *testing.T) {
+	for code, want := range map[string]int{"IDR": 0, "JPY": 0, "jpy": 0, "USD": 2, "EUR": 2, "BHD": 3, "KWD": 3} {
+		if got := MinorUnits(code); got != want {
+			t.Errorf("MinorUnits(%s) = %d, ingin %d", code, got, want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	timestamp := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		amount string
+		from   string
+		to     string
+		rate   float64
+		// wantAmount adalah amount setelah dibulatkan ke minor unit from.
+		wantAmount string
+		want       string
+	}{
+		{"euro ke rupiah", "50", "EUR", "IDR", 18700, "50.00", "935000"},
+		{"rupiah dibulatkan", "3", "USD", "IDR", 16500.5, "3.00", "49502"},
+		{"setengah rupiah menjauhi nol", "1", "USD", "IDR", 16500.5, "1.00", "16501"},
+		{"yen tanpa desimal", "12.5", "USD", "JPY", 150, "12.50", "1875"},
+		// amount dibulatkan ke minor unit from sebelum dikalikan, jadi
+		// amount × rate selalu sama dengan hasilnya
+		{"amount yen dibulatkan", "1000.6", "JPY", "IDR", 110, "1001", "110110"},
+		{"amount di bawah satu sen", "0.005", "USD", "IDR", 16200, "0.01", "162"},
+		{"amount tiga desimal", "1234.565", "USD", "IDR", 16500, "1234.57", "20370405"},
+		{"dinar bahrain tiga desimal", "100", "USD", "BHD", 0.376, "100.00", "37.600"},
+		{"rupiah ke dinar bahrain", "1000000", "IDR", "BHD", 0.0000228, "1000000", "22.800"},
+		{"dinar kuwait dibulatkan", "1", "USD", "KWD", 0.30745, "1.00", "0.307"},
+		// 1.5 × 0.07 dalam float64 adalah 0.10500000000000001 atau
+		// 0.10499999999999998 tergantung urutan; desimal selalu 0.105
+		{"tanpa galat float", "1.5", "EUR", "USD", 0.07, "1.50", "0.11"},
+		{"nol", "0", "EUR", "USD", 1.1, "0.00", "0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := &Rate{From: tt.from, To: tt.to, Rate: tt.rate, Timestamp: timestamp, Provider: "uji"}
+			got, err := Convert(tt.amount, rate)
+			if err != nil {
+				t.Fatalf("Convert(%s): %v", tt.amount, err)
+			}
+			want := Conversion{From: tt.from, To: tt.to, Amount: tt.wantAmount, ConvertedAmount: tt.want, Rate: tt.rate, Timestamp: timestamp, Provider: "uji"}
+			if *got != want {
+				t.Errorf("Convert(%s %s ke %s) = %+v, ingin %+v", tt.amount, tt.from, tt.to, *got, want)
+			}
+		})
+	}
+}
+
+func TestConvertError(t *testing.T) {
+	rate := &Rate{From: "EUR", To: "IDR", Rate: 18700}
+	for _, amount := range []string{"", "lima puluh", "-5", "1e", "50 euro"} {
+		if got, err := Convert(amount, rate); err == nil {
+			t.Errorf("Convert(%q) = %+v, ingin error", amount, got)
+		}
+	}
+}
+
+func TestNewRateSeries(t *testing.T) {
+	tests := []struct {
+		name      string
+		points    []RatePoint
+		min       RatePoint
+		max       RatePoint
+		average   float64
+		changePct float64
+	}{
+		{
+			name: "naik",
+			points: []RatePoint{
+				{"2025-04-28", 16000}, {"2025-04-29", 16400}, {"2025-04-30", 15800}, {"2025-05-01", 16600},
+			},
+			min:       RatePoint{"2025-04-30", 15800},
+			max:       RatePoint{"2025-05-01", 16600},
+			average:   16200,
+			changePct: 3.75,
+		},
+		{
+			name:      "turun dibulatkan dua desimal",
+			points:    []RatePoint{{"2025-04-29", 3}, {"2025-04-30", 2.9}, {"2025-05-01", 2.9}},
+			min:       RatePoint{"2025-04-30", 2.9},
+			max:       RatePoint{"2025-04-29", 3},
+			average:   2.933333,
+			changePct: -3.33,
+		},
+		{
+			name:      "satu hari",
+			points:    []RatePoint{{"2025-05-01", 0.88}},
+			min:       RatePoint{"2025-05-01", 0.88},
+			max:       RatePoint{"2025-05-01", 0.88},
+			average:   0.88,
+			changePct: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRateSeries("USD", "IDR", "uji", tt.points)
+			if err != nil {
+				t.Fatalf("NewRateSeries: %v", err)
+			}
+			if got.Min != tt.min || got.Max != tt.max {
+				t.Errorf("min/max = %v/%v, ingin %v/%v", got.Min, got.Max, tt.min, tt.max)
+			}
+			if math.Abs(got.Average-tt.average) > 1e-6 || got.ChangePct != tt.changePct {
+				t.Errorf("average %v change_pct %v, ingin %v %v", got.Average, got.ChangePct, tt.average, tt.changePct)
+			}
+			last := tt.points[len(tt.points)-1]
+			if got.StartDate != tt.points[0].Date || got.EndDate != last.Date || len(got.Rates) != len(tt.points) {
+				t.Errorf("rentang %s sampai %s dengan %d kurs", got.StartDate, got.EndDate, len(got.Rates))
+			}
+			if got.From != "USD" || got.To != "IDR" || got.Provider != "uji" {
+				t.Errorf("series = %+v", got)
+			}
+		})
+	}
+
+	if got, err := NewRateSeries("USD", "IDR", "uji", nil); err == nil {
+		t.Errorf("NewRateSeries tanpa kurs = %+v, ingin error", got)
+	}
+}
@@ -0,0 +1,242 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultOpenWeatherMapURL adalah base URL api.openweathermap.org.
+const DefaultOpenWeatherMapURL = "https://api.openweathermap.org"
+
+// OpenWeatherMap adalah WeatherProvider untuk api.openweathermap.org.
+type OpenWeatherMap struct {
+	APIKey string
+	// BaseURL default DefaultOpenWeatherMapURL.
+	BaseURL string
+	// HTTPClient default http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Struct untuk response dari OpenWeatherMap
+type openWeatherMapResponse struct {
+	Name     string `json:"name"`
+	Dt       int64  `json:"dt"`
+	Timezone int    `json:"timezone"` // offset UTC dalam detik
+	Sys      struct {
+		Country string `json:"country"`
+		Sunrise int64  `json:"sunrise"`
+		Sunset  int64  `json:"sunset"`
+	} `json:"sys"`
+	Main struct {
+		Temp      float64 `json:"temp"`
+		FeelsLike float64 `json:"feels_like"`
+		Humidity  int     `json:"humidity"`
+	} `json:"main"`
+	Wind struct {
+		Speed float64 `json:"speed"` // m/s untuk units=metric
+		Deg   int     `json:"deg"`
+	} `json:"wind"`
+	Weather []struct {
+		Description string `json:"description"`
+	} `json:"weather"`
+}
+
+func (p *OpenWeatherMap) CurrentWeather(ctx context.Context, q WeatherQuery) (*Weather, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk OpenWeatherMap tidak tersedia")
+	}
+
+	params, err := openWeatherMapParams(q)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Requesting weather API (OpenWeatherMap): %s", params.Encode())
+	params.Add("appid", p.APIKey)
+
+	var data openWeatherMapResponse
+	if err := getJSON(ctx, p.HTTPClient, baseURL(p.BaseURL, DefaultOpenWeatherMapURL)+"/data/2.5/weather?"+params.Encode(), &data); err != nil {
+		return nil, err
+	}
+
+	zone := time.FixedZone("", data.Timezone)
+	weather := &Weather{
+		Location:         data.Name,
+		Country:          data.Sys.Country,
+		LocalTime:        time.Unix(data.Dt, 0).In(zone),
+		TemperatureC:     data.Main.Temp,
+		FeelsLikeC:       data.Main.FeelsLike,
+		HumidityPct:      data.Main.Humidity,
+		WindSpeedKph:     data.Wind.Speed * 3.6,
+		WindDirectionDeg: data.Wind.Deg,
+		WindDirection:    CompassDirection(data.Wind.Deg),
+		Units:            MetricUnits,
+		Provider:         "openweathermap",
+	}
+	if len(data.Weather) > 0 {
+		weather.Condition = data.Weather[0].Description
+	}
+	if data.Sys.Sunrise != 0 && data.Sys.Sunset != 0 {
+		sunrise := time.Unix(data.Sys.Sunrise, 0).In(zone)
+		sunset := time.Unix(data.Sys.Sunset, 0).In(zone)
+		weather.Sunrise, weather.Sunset = &sunrise, &sunset
+	}
+	return weather, nil
+}
+
+// openWeatherMapParams mengubah q menjadi parameter lokasi dan units=metric.
+func openWeatherMapParams(q WeatherQuery) (url.Values, error) {
+	params := url.Values{}
+	switch {
+	case q.Coordinates != nil:
+		params.Add("lat", strconv.FormatFloat(q.Coordinates.Latitude, 'f', -1, 64))
+		params.Add("lon", strconv.FormatFloat(q.Coordinates.Longitude, 'f', -1, 64))
+	case q.City != "":
+		params.Add("q", q.City)
+	default:
+		return nil, fmt.Errorf("lokasi cuaca kosong: butuh koordinat atau kota")
+	}
+	params.Add("units", "metric")
+	return params, nil
+}
+
+// Struct untuk response /data/2.5/forecast (per 3 jam, maksimal 5 hari)
+type openWeatherMapForecastResponse struct {
+	List []struct {
+		Dt   int64 `json:"dt"`
+		Main struct {
+			Temp float64 `json:"temp"`
+		} `json:"main"`
+		Weather []struct {
+			Description string `json:"description"`
+		} `json:"weather"`
+		Wind struct {
+			Speed float64 `json:"speed"`
+			Deg   int     `json:"deg"`
+		} `json:"wind"`
+		Pop float64 `json:"pop"` // peluang presipitasi 0 sampai 1
+	} `json:"list"`
+	City struct {
+		Name     string `json:"name"`
+		Timezone int    `json:"timezone"` // offset UTC dalam detik
+	} `json:"city"`
+}
+
+func (p *OpenWeatherMap) Forecast(ctx context.Context, q ForecastQuery) (*Forecast, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk OpenWeatherMap tidak tersedia")
+	}
+
+	params, err := openWeatherMapParams(q.WeatherQuery)
+	if err != nil {
+		return nil, err
+	}
+	days := clampForecastDays(q.Days)
+	// 8 entri per hari; hari ini bisa terpotong jadi minta satu hari lebih
+	params.Add("cnt", strconv.Itoa(min((days+1)*8, 40)))
+	log.Printf("Requesting forecast API (OpenWeatherMap): %s", params.Encode())
+	params.Add("appid", p.APIKey)
+
+	var data openWeatherMapForecastResponse
+	if err := getJSON(ctx, p.HTTPClient, baseURL(p.BaseURL, DefaultOpenWeatherMapURL)+"/data/2.5/forecast?"+params.Encode(), &data); err != nil {
+		return nil, err
+	}
+
+	zone := time.FixedZone("", data.City.Timezone)
+	entries := make([]HourlyForecast, 0, len(data.List))
+	for _, item := range data.List {
+		entry := HourlyForecast{
+			Time:             time.Unix(item.Dt, 0).In(zone),
+			TemperatureC:     item.Main.Temp,
+			PrecipitationPct: int(math.Round(item.Pop * 100)),
+			WindSpeedKph:     item.Wind.Speed * 3.6,
+			WindDirectionDeg: item.Wind.Deg,
+		}
+		if len(item.Weather) > 0 {
+			entry.Condition = item.Weather[0].Description
+		}
+		entries = append(entries, entry)
+	}
+
+	forecast := &Forecast{
+		Location: data.City.Name,
+		Daily:    dailyFromHourly(entries),
+		Units:    MetricUnits,
+		Provider: "openweathermap",
+	}
+	if len(forecast.Daily) > days {
+		forecast.Daily = forecast.Daily[:days]
+	}
+	if q.Hours > 0 && len(entries) > 0 {
+		end := entries[0].Time.Add(time.Duration(q.Hours) * time.Hour)
+		for _, entry := range entries {
+			if !entry.Time.Before(end) {
+				break
+			}
+			forecast.Hourly = append(forecast.Hourly, entry)
+		}
+	}
+	return forecast, nil
+}
+
+// Struct untuk response Geocoding API OpenWeatherMap (/geo/1.0/direct dan
+// /geo/1.0/reverse)
+type openWeatherMapGeocodeResponse []struct {
+	Name    string  `json:"name"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Country string  `json:"country"`
+	State   string  `json:"state"`
+}
+
+// Geocode mencari kota lewat Geocoding API OpenWeatherMap. Berbeda dengan
+// GoogleGeocoding, API ini hanya mengenali nama kota, bukan alamat.
+func (p *OpenWeatherMap) Geocode(ctx context.Context, query string) ([]GeocodeResult, error) {
+	if query == "" {
+		return nil, fmt.Errorf("query geocoding kosong")
+	}
+	params := url.Values{}
+	params.Add("q", query)
+	return p.geocode(ctx, "/geo/1.0/direct", params)
+}
+
+func (p *OpenWeatherMap) ReverseGeocode(ctx context.Context, c Coordinates) ([]GeocodeResult, error) {
+	params := url.Values{}
+	params.Add("lat", strconv.FormatFloat(c.Latitude, 'f', -1, 64))
+	params.Add("lon", strconv.FormatFloat(c.Longitude, 'f', -1, 64))
+	return p.geocode(ctx, "/geo/1.0/reverse", params)
+}
+
+func (p *OpenWeatherMap) geocode(ctx context.Context, path string, params url.Values) ([]GeocodeResult, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk OpenWeatherMap tidak tersedia")
+	}
+	params.Add("limit", "5")
+	log.Printf("Requesting geocoding API (OpenWeatherMap): %s %s", path, params.Encode())
+	params.Add("appid", p.APIKey)
+
+	var data openWeatherMapGeocodeResponse
+	if err := getJSON(ctx, p.HTTPClient, baseURL(p.BaseURL, DefaultOpenWeatherMapURL)+path+"?"+params.Encode(), &data); err != nil {
+		return nil, err
+	}
+
+	results := make([]GeocodeResult, 0, len(data))
+	for _, r := range data {
+		address := r.Name
+		if r.State != "" {
+			address += ", " + r.State
+		}
+		results = append(results, GeocodeResult{
+			Name:        r.Name,
+			Address:     address,
+			Country:     r.Country,
+			Coordinates: Coordinates{Latitude: r.Lat, Longitude: r.Lon},
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,281 @@
+// Package providers mendefinisikan sumber data cuaca, tempat, geocoding dan
+// kurs mata uang sebagai interface dengan struct domain yang sudah
+// dinormalisasi. Setiap backend (OpenWeatherMap, weatherapi.com, Google
+// Places, Google Geocoding, exchangerate.host) punya adapter sendiri, dan
+// backend yang dipakai dipilih saat startup lewat Config. CLI agent maupun
+// proxy WebSocket memakai provider yang sama.
+package providers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Coordinates adalah titik lokasi dalam derajat desimal.
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// ParseCoordinates membaca latitude/longitude dalam bentuk string, misal
+// dari pesan lokasi klien.
+func ParseCoordinates(lat, lon string) (Coordinates, error) {
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("latitude tidak valid %q: %v", lat, err)
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+	if err != nil {
+		return Coordinates{}, fmt.Errorf("longitude tidak valid %q: %v", lon, err)
+	}
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return Coordinates{}, fmt.Errorf("koordinat di luar jangkauan: %s, %s", lat, lon)
+	}
+	return Coordinates{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// GeocodeResult adalah satu hasil geocoding: nama tempat, alamat lengkap dan
+// koordinatnya.
+type GeocodeResult struct {
+	Name        string      `json:"name"`
+	Address     string      `json:"address,omitempty"`
+	Country     string      `json:"country,omitempty"` // kode ISO 3166-1 alpha-2, misal "ID"
+	Coordinates Coordinates `json:"coordinates"`
+}
+
+// GeocodingProvider mengubah nama kota, tempat atau alamat menjadi koordinat
+// (forward) dan sebaliknya (reverse). Hasil diurutkan dari yang paling cocok.
+type GeocodingProvider interface {
+	Geocode(ctx context.Context, query string) ([]GeocodeResult, error)
+	ReverseGeocode(ctx context.Context, c Coordinates) ([]GeocodeResult, error)
+}
+
+// WeatherQuery menentukan lokasi cuaca: Coordinates jika ada, selain itu City.
+type WeatherQuery struct {
+	Coordinates *Coordinates
+	City        string
+}
+
+// WeatherUnits menjelaskan satuan field numerik Weather dan Forecast supaya
+// model tidak perlu menebak dari nama field.
+type WeatherUnits struct {
+	Temperature   string `json:"temperature"`
+	WindSpeed     string `json:"wind_speed"`
+	WindDirection string `json:"wind_direction"`
+	Humidity      string `json:"humidity"`
+	Precipitation string `json:"precipitation_probability"`
+}
+
+// MetricUnits adalah satuan yang dipakai semua WeatherProvider.
+var MetricUnits = WeatherUnits{
+	Temperature:   "°C",
+	WindSpeed:     "km/h",
+	WindDirection: "degrees",
+	Humidity:      "%",
+	Precipitation: "%",
+}
+
+// Weather adalah kondisi cuaca saat ini yang sudah dinormalisasi. Semua waktu
+// memakai zona waktu lokasi. Response mentah provider tidak pernah diteruskan
+// ke model.
+type Weather struct {
+	Location         string       `json:"location"`
+	Country          string       `json:"country,omitempty"`
+	LocalTime        time.Time    `json:"local_time"`
+	Condition        string       `json:"condition"`
+	TemperatureC     float64      `json:"temperature_c"`
+	FeelsLikeC       float64      `json:"feels_like_c"`
+	HumidityPct      int          `json:"humidity_pct"`
+	WindSpeedKph     float64      `json:"wind_speed_kph"`
+	WindDirectionDeg int          `json:"wind_direction_deg"`
+	WindDirection    string       `json:"wind_direction"` // arah mata angin, misal "SW"
+	Sunrise          *time.Time   `json:"sunrise,omitempty"`
+	Sunset           *time.Time   `json:"sunset,omitempty"`
+	Units            WeatherUnits `json:"units"`
+	Provider         string       `json:"provider"`
+}
+
+// MaxForecastDays adalah horizon prakiraan terjauh yang didukung semua
+// provider cuaca.
+const MaxForecastDays = 5
+
+// ForecastQuery adalah lokasi dan horizon prakiraan cuaca.
+type ForecastQuery struct {
+	WeatherQuery
+	Days  int // jumlah hari termasuk hari ini, 1 sampai MaxForecastDays
+	Hours int // jam ke depan untuk prakiraan per jam; 0 berarti tanpa Hourly
+}
+
+// HourlyForecast adalah prakiraan pada satu titik waktu. Jaraknya tergantung
+// provider: per jam untuk weatherapi.com, per 3 jam untuk OpenWeatherMap.
+type HourlyForecast struct {
+	Time             time.Time `json:"time"` // waktu lokal lokasi
+	TemperatureC     float64   `json:"temperature_c"`
+	PrecipitationPct int       `json:"precipitation_probability_pct"`
+	Condition        string    `json:"condition"`
+	WindSpeedKph     float64   `json:"wind_speed_kph"`
+	WindDirectionDeg int       `json:"wind_direction_deg"`
+}
+
+// DailyForecast adalah ringkasan prakiraan satu hari.
+type DailyForecast struct {
+	Date             string  `json:"date"` // YYYY-MM-DD waktu lokal
+	MinTemperatureC  float64 `json:"min_temperature_c"`
+	MaxTemperatureC  float64 `json:"max_temperature_c"`
+	PrecipitationPct int     `json:"precipitation_probability_pct"`
+	Condition        string  `json:"condition"`
+	MaxWindSpeedKph  float64 `json:"max_wind_speed_kph"`
+}
+
+// Forecast adalah prakiraan cuaca yang sudah dinormalisasi.
+type Forecast struct {
+	Location string           `json:"location"`
+	Hourly   []HourlyForecast `json:"hourly,omitempty"`
+	Daily    []DailyForecast  `json:"daily"`
+	Units    WeatherUnits     `json:"units"`
+	Provider string           `json:"provider"`
+}
+
+// WeatherProvider mengambil data cuaca.
+type WeatherProvider interface {
+	CurrentWeather(ctx context.Context, q WeatherQuery) (*Weather, error)
+	Forecast(ctx context.Context, q ForecastQuery) (*Forecast, error)
+}
+
+// Place adalah satu hasil pencarian tempat. DistanceKm diisi oleh pemanggil
+// yang mengetahui lokasi pengguna (lihat DistanceKm).
+type Place struct {
+	ID              string       `json:"id,omitempty"`
+	Name            string       `json:"name"`
+	Address         string       `json:"address"`
+	PriceLevel      string       `json:"price_level,omitempty"`
+	Location        *Coordinates `json:"location,omitempty"`
+	Rating          float64      `json:"rating,omitempty"`
+	UserRatingCount int          `json:"user_rating_count,omitempty"`
+	GoogleMapsURI   string       `json:"google_maps_uri,omitempty"`
+	Type            string       `json:"type,omitempty"`
+	OpenNow         *bool        `json:"open_now,omitempty"`
+	DistanceKm      *float64     `json:"distance_km,omitempty"`
+}
+
+// Nilai PriceLevel dari termurah ke termahal.
+var PriceLevels = []string{
+	"PRICE_LEVEL_INEXPENSIVE",
+	"PRICE_LEVEL_MODERATE",
+	"PRICE_LEVEL_EXPENSIVE",
+	"PRICE_LEVEL_VERY_EXPENSIVE",
+}
+
+// DefaultBiasRadiusM adalah radius locationBias jika PlaceQuery.BiasRadiusM
+// kosong.
+const DefaultBiasRadiusM = 5000
+
+// PlaceQuery adalah pencarian tempat berdasarkan teks bebas. Bias
+// memprioritaskan hasil di sekitar koordinat tersebut; filter lain opsional.
+type PlaceQuery struct {
+	Text        string
+	Bias        *Coordinates
+	BiasRadiusM float64
+	Type        string   // tipe tempat Google, misal "restaurant" atau "cafe"
+	MinRating   float64  // 0 sampai 5
+	OpenNow     bool     // hanya tempat yang sedang buka
+	PriceLevels []string // subset dari PriceLevels
+}
+
+// NearbyQuery adalah pencarian tempat di dalam radius dari Center.
+type NearbyQuery struct {
+	Center         Coordinates
+	RadiusM        float64
+	Type           string
+	MaxResults     int
+	RankByDistance bool
+	MinRating      float64
+	OpenNow        bool
+}
+
+// PlacesProvider mencari tempat.
+type PlacesProvider interface {
+	// SearchText mencari dengan teks bebas, misal "warung enak di sleman".
+	SearchText(ctx context.Context, q PlaceQuery) ([]Place, error)
+	// SearchNearby mencari tempat di sekitar suatu titik.
+	SearchNearby(ctx context.Context, q NearbyQuery) ([]Place, error)
+	// Details mengambil detail satu tempat berdasarkan Place.ID.
+	Details(ctx context.Context, id string) (*PlaceDetails, error)
+}
+
+// Review adalah satu ulasan Google Maps. AuthorPhotoURI adalah foto profil
+// penulis dari authorAttribution.
+type Review struct {
+	AuthorName     string  `json:"author_name"`
+	AuthorURI      string  `json:"author_uri,omitempty"`
+	AuthorPhotoURI string  `json:"author_photo_uri,omitempty"`
+	Rating         float64 `json:"rating,omitempty"`
+	Text           string  `json:"text"`
+	RelativeTime   string  `json:"relative_time,omitempty"`
+}
+
+// PlaceDetails adalah detail satu tempat: jam buka, kontak dan ulasan teratas.
+type PlaceDetails struct {
+	ID              string       `json:"id"`
+	Name            string       `json:"name"`
+	Address         string       `json:"address"`
+	Location        *Coordinates `json:"location,omitempty"`
+	Rating          float64      `json:"rating,omitempty"`
+	UserRatingCount int          `json:"user_rating_count,omitempty"`
+	GoogleMapsURI   string       `json:"google_maps_uri,omitempty"`
+	Phone           string       `json:"phone,omitempty"`
+	Website         string       `json:"website,omitempty"`
+	OpenNow         *bool        `json:"open_now,omitempty"`
+	OpeningHours    []string     `json:"opening_hours,omitempty"` // satu baris per hari, misal "Monday: 7:00 AM – 10:00 PM"
+	Reviews         []Review     `json:"reviews,omitempty"`
+}
+
+// Rate adalah nilai tukar 1 unit From dalam mata uang To. Date diisi untuk
+// kurs historis (YYYY-MM-DD).
+type Rate struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Rate      float64   `json:"rate"`
+	Date      string    `json:"date,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Provider  string    `json:"provider"`
+}
+
+// RatePoint adalah kurs penutupan satu hari dalam RateSeries.
+type RatePoint struct {
+	Date string  `json:"date"` // YYYY-MM-DD
+	Rate float64 `json:"rate"`
+}
+
+// RateSeries adalah kurs harian From ke To dalam satu rentang tanggal beserta
+// ringkasannya. ChangePct adalah perubahan dari kurs pertama ke terakhir.
+type RateSeries struct {
+	From      string      `json:"from"`
+	To        string      `json:"to"`
+	StartDate string      `json:"start_date"`
+	EndDate   string      `json:"end_date"`
+	Rates     []RatePoint `json:"rates"`
+	Min       RatePoint   `json:"min"`
+	Max       RatePoint   `json:"max"`
+	Average   float64     `json:"average"`
+	ChangePct float64     `json:"change_pct"`
+	Provider  string      `json:"provider"`
+}
+
+// MaxRateSeriesDays adalah selisih hari terbesar antara tanggal awal dan akhir
+// RateSeries.
+const MaxRateSeriesDays = 365
+
+// FXProvider mengambil nilai tukar mata uang. Kode mata uang memakai ISO 4217
+// dan tanggal memakai UTC.
+type FXProvider interface {
+	// Rate mengambil kurs terbaru.
+	Rate(ctx context.Context, from, to string) (*Rate, error)
+	// RateOn mengambil kurs pada tanggal date.
+	RateOn(ctx context.Context, from, to string, date time.Time) (*Rate, error)
+	// RateSeries mengambil kurs harian dari start sampai end (inklusif).
+	RateSeries(ctx context.Context, from, to string, start, end time.Time) (*RateSeries, error)
+}
@@ -0,0 +1,121 @@
+package providers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"proxy/fakes"
+	"proxy/providers"
+)
+
+func TestForecast(t *testing.T) {
+	api := fakes.NewServer()
+	defer api.Close()
+
+	// Prakiraan palsu mulai 1 Mei 07.00 WIB; 2 Mei hujan ringan pukul 13.00–17.59.
+	// Kedua provider harus merangkumnya menjadi prakiraan harian yang sama.
+	daily := []providers.DailyForecast{
+		{Date: "2025-05-01", MinTemperatureC: 24, MaxTemperatureC: 30, PrecipitationPct: 10, Condition: "awan tersebar", MaxWindSpeedKph: 9},
+		{Date: "2025-05-02", MinTemperatureC: 24, MaxTemperatureC: 30, PrecipitationPct: 80, Condition: "hujan ringan", MaxWindSpeedKph: 9},
+	}
+	tests := []struct {
+		name     string
+		provider providers.WeatherProvider
+		// step adalah jarak antar entri per jam.
+		step time.Duration
+	}{
+		{
+			name:     "openweathermap",
+			provider: &providers.OpenWeatherMap{APIKey: fakes.APIKey, BaseURL: api.URL, HTTPClient: api.Client()},
+			step:     3 * time.Hour,
+		},
+		{
+			name:     "weatherapi",
+			provider: &providers.WeatherAPI{APIKey: fakes.APIKey, BaseURL: api.URL, HTTPClient: api.Client()},
+			step:     time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.provider.Forecast(context.Background(), providers.ForecastQuery{
+				WeatherQuery: providers.WeatherQuery{City: "Sleman"},
+				Days:         2,
+				Hours:        6,
+			})
+			if err != nil {
+				t.Fatalf("Forecast: %v", err)
+			}
+			if !reflect.DeepEqual(got.Daily, daily) {
+				t.Errorf("Daily = %+v, ingin %+v", got.Daily, daily)
+			}
+
+			// Entri per jam memakai waktu lokal lokasi selama 6 jam ke depan
+			start := fakes.Timestamp
+			if want := int(6 * time.Hour / tt.step); len(got.Hourly) != want {
+				t.Fatalf("Hourly berisi %d entri, ingin %d", len(got.Hourly), want)
+			}
+			for i, h := range got.Hourly {
+				want := start.Add(time.Duration(i) * tt.step)
+				_, offset := h.Time.Zone()
+				if !h.Time.Equal(want) || offset != 7*60*60 {
+					t.Errorf("Hourly[%d].Time = %v, ingin %v WIB", i, h.Time, want.In(fakes.ForecastZone))
+				}
+			}
+			if h := got.Hourly[0]; h.Time.Hour() != 7 || h.Condition != "awan tersebar" || h.TemperatureC != 30 {
+				t.Errorf("Hourly[0] = %+v, ingin 07.00 awan tersebar 30°C", h)
+			}
+		})
+	}
+}
+
+func TestWeatherAPIForecastHalfHourZone(t *testing.T) {
+	// Di zona +05:30 jam lokal dimulai pada menit ke-30 UTC. Pukul 10.45 IST
+	// (05.15 UTC) jam berjalan adalah 10.00 IST, yang jatuh sebelum 05.00 UTC.
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	now := time.Date(2025, 5, 1, 10, 45, 0, 0, ist)
+	var hours []map[string]any
+	for h := 0; h < 24; h++ {
+		hours = append(hours, map[string]any{
+			"time_epoch": time.Date(2025, 5, 1, h, 0, 0, 0, ist).Unix(),
+			"temp_c":     float64(20 + h),
+			"condition":  map[string]any{"text": "Sunny"},
+		})
+	}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"location": map[string]any{
+				"name":            "Mumbai",
+				"tz_id":           "Asia/Kolkata",
+				"localtime_epoch": now.Unix(),
+				"localtime":       now.Format("2006-01-02 15:04"),
+			},
+			"forecast": map[string]any{"forecastday": []map[string]any{{"date": "2025-05-01", "hour": hours}}},
+		})
+	}))
+	defer api.Close()
+
+	provider := &providers.WeatherAPI{APIKey: fakes.APIKey, BaseURL: api.URL, HTTPClient: api.Client()}
+	got, err := provider.Forecast(context.Background(), providers.ForecastQuery{
+		WeatherQuery: providers.WeatherQuery{City: "Mumbai"},
+		Days:         1,
+		Hours:        3,
+	})
+	if err != nil {
+		t.Fatalf("Forecast: %v", err)
+	}
+	if len(got.Hourly) != 3 {
+		t.Fatalf("Hourly berisi %d entri, ingin 3", len(got.Hourly))
+	}
+	for i, h := range got.Hourly {
+		_, offset := h.Time.Zone()
+		if h.Time.Hour() != 10+i || h.Time.Minute() != 0 || offset != 5*60*60+30*60 {
+			t.Errorf("Hourly[%d].Time = %v, ingin %02d.00 IST", i, h.Time, 10+i)
+		}
+	}
+}
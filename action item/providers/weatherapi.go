@@ -0,0 +1,229 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultWeatherAPIURL adalah base URL api.weatherapi.com.
+const DefaultWeatherAPIURL = "http://api.weatherapi.com"
+
+// WeatherAPI adalah WeatherProvider untuk weatherapi.com.
+type WeatherAPI struct {
+	APIKey string
+	// BaseURL default DefaultWeatherAPIURL.
+	BaseURL string
+	// HTTPClient default http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+func (p *WeatherAPI) CurrentWeather(ctx context.Context, q WeatherQuery) (*Weather, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk weatherapi.com tidak tersedia")
+	}
+
+	location, err := weatherAPILocation(q)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Requesting weather API (weatherapi.com): q=%s", location)
+
+	// forecast.json dengan days=1 berisi current sekaligus astro (sunrise/sunset)
+	data, err := p.forecast(ctx, location, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := weatherAPIZone(data.Location.TzID, data.Location.Localtime, data.Location.LocaltimeEpoch)
+	weather := &Weather{
+		Location:         data.Location.Name,
+		Country:          data.Location.Country,
+		LocalTime:        time.Unix(data.Location.LocaltimeEpoch, 0).In(zone),
+		Condition:        data.Current.Condition.Text,
+		TemperatureC:     data.Current.TempC,
+		FeelsLikeC:       data.Current.FeelsLikeC,
+		HumidityPct:      data.Current.Humidity,
+		WindSpeedKph:     data.Current.WindKph,
+		WindDirectionDeg: data.Current.WindDegree,
+		WindDirection:    CompassDirection(data.Current.WindDegree),
+		Units:            MetricUnits,
+		Provider:         "weatherapi",
+	}
+	if len(data.Forecast.Forecastday) > 0 {
+		today := data.Forecast.Forecastday[0]
+		weather.Sunrise = parseWeatherAPIClock(today.Date, today.Astro.Sunrise, zone)
+		weather.Sunset = parseWeatherAPIClock(today.Date, today.Astro.Sunset, zone)
+	}
+	return weather, nil
+}
+
+// parseWeatherAPIClock menggabungkan date ("2006-01-02") dan jam astro
+// ("06:12 AM") menjadi waktu di zone, atau nil jika tidak bisa di-parse.
+func parseWeatherAPIClock(date, clock string, zone *time.Location) *time.Time {
+	t, err := time.ParseInLocation("2006-01-02 03:04 PM", date+" "+clock, zone)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// weatherAPILocation mengubah q menjadi parameter q weatherapi.com ("lat,lon"
+// atau nama kota).
+func weatherAPILocation(q WeatherQuery) (string, error) {
+	switch {
+	case q.Coordinates != nil:
+		return strconv.FormatFloat(q.Coordinates.Latitude, 'f', -1, 64) + "," +
+			strconv.FormatFloat(q.Coordinates.Longitude, 'f', -1, 64), nil
+	case q.City != "":
+		return q.City, nil
+	}
+	return "", fmt.Errorf("lokasi cuaca kosong: butuh koordinat atau kota")
+}
+
+// Struct response /v1/forecast.json dari weatherapi.com, dipakai untuk cuaca
+// saat ini maupun prakiraan
+type weatherAPIResponse struct {
+	Location struct {
+		Name           string `json:"name"`
+		Region         string `json:"region"`
+		Country        string `json:"country"`
+		TzID           string `json:"tz_id"`
+		LocaltimeEpoch int64  `json:"localtime_epoch"`
+		Localtime      string `json:"localtime"` // "2006-01-02 15:04" waktu lokal
+	} `json:"location"`
+	Current struct {
+		TempC      float64 `json:"temp_c"`
+		FeelsLikeC float64 `json:"feelslike_c"`
+		Condition  struct {
+			Text string `json:"text"`
+		} `json:"condition"`
+		Humidity   int     `json:"humidity"`
+		WindKph    float64 `json:"wind_kph"`
+		WindDegree int     `json:"wind_degree"`
+	} `json:"current"`
+	Forecast struct {
+		Forecastday []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MaxTempC          float64 `json:"maxtemp_c"`
+				MinTempC          float64 `json:"mintemp_c"`
+				MaxWindKph        float64 `json:"maxwind_kph"`
+				DailyChanceOfRain int     `json:"daily_chance_of_rain"`
+				Condition         struct {
+					Text string `json:"text"`
+				} `json:"condition"`
+			} `json:"day"`
+			Astro struct {
+				Sunrise string `json:"sunrise"` // "06:12 AM"
+				Sunset  string `json:"sunset"`
+			} `json:"astro"`
+			Hour []struct {
+				TimeEpoch    int64   `json:"time_epoch"`
+				TempC        float64 `json:"temp_c"`
+				WindKph      float64 `json:"wind_kph"`
+				WindDegree   int     `json:"wind_degree"`
+				ChanceOfRain int     `json:"chance_of_rain"`
+				Condition    struct {
+					Text string `json:"text"`
+				} `json:"condition"`
+			} `json:"hour"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
+}
+
+// forecast memanggil /v1/forecast.json untuk location selama days hari.
+func (p *WeatherAPI) forecast(ctx context.Context, location string, days int) (*weatherAPIResponse, error) {
+	params := url.Values{}
+	params.Add("key", p.APIKey)
+	params.Add("q", location)
+	params.Add("days", strconv.Itoa(days))
+
+	var data weatherAPIResponse
+	if err := getJSON(ctx, p.HTTPClient, baseURL(p.BaseURL, DefaultWeatherAPIURL)+"/v1/forecast.json?"+params.Encode(), &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (p *WeatherAPI) Forecast(ctx context.Context, q ForecastQuery) (*Forecast, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("API key untuk weatherapi.com tidak tersedia")
+	}
+
+	location, err := weatherAPILocation(q.WeatherQuery)
+	if err != nil {
+		return nil, err
+	}
+	days := clampForecastDays(q.Days)
+	// Jam yang diminta bisa melewati hari terakhir
+	fetchDays := days
+	if needed := q.Hours/24 + 2; q.Hours > 0 && needed > fetchDays {
+		fetchDays = min(needed, MaxForecastDays)
+	}
+	log.Printf("Requesting forecast API (weatherapi.com): q=%s days=%d", location, fetchDays)
+
+	data, err := p.forecast(ctx, location, fetchDays)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := weatherAPIZone(data.Location.TzID, data.Location.Localtime, data.Location.LocaltimeEpoch)
+	forecast := &Forecast{
+		Location: data.Location.Name,
+		Units:    MetricUnits,
+		Provider: "weatherapi",
+	}
+
+	// Entri per jam dimulai dari jam yang sedang berjalan di lokasi, yaitu
+	// entri terakhir yang tidak lebih dari satu jam sebelum localtime. Jam
+	// tidak dipotong dengan Truncate karena itu membulatkan di UTC, sehingga
+	// jam berjalan di zona +05:30 atau +09:30 ikut terbuang.
+	after := time.Unix(data.Location.LocaltimeEpoch, 0).Add(-time.Hour)
+	for i, day := range data.Forecast.Forecastday {
+		if i < days {
+			forecast.Daily = append(forecast.Daily, DailyForecast{
+				Date:             day.Date,
+				MinTemperatureC:  day.Day.MinTempC,
+				MaxTemperatureC:  day.Day.MaxTempC,
+				PrecipitationPct: day.Day.DailyChanceOfRain,
+				Condition:        day.Day.Condition.Text,
+				MaxWindSpeedKph:  day.Day.MaxWindKph,
+			})
+		}
+		for _, hour := range day.Hour {
+			t := time.Unix(hour.TimeEpoch, 0)
+			if !t.After(after) || len(forecast.Hourly) >= q.Hours {
+				continue
+			}
+			forecast.Hourly = append(forecast.Hourly, HourlyForecast{
+				Time:             t.In(zone),
+				TemperatureC:     hour.TempC,
+				PrecipitationPct: hour.ChanceOfRain,
+				Condition:        hour.Condition.Text,
+				WindSpeedKph:     hour.WindKph,
+				WindDirectionDeg: hour.WindDegree,
+			})
+		}
+	}
+	return forecast, nil
+}
+
+// weatherAPIZone mengembalikan zona waktu tzID. Jika database zona waktu
+// tidak tersedia, offset dihitung dari selisih localtime dan localtimeEpoch.
+func weatherAPIZone(tzID, localtime string, localtimeEpoch int64) *time.Location {
+	if zone, err := time.LoadLocation(tzID); err == nil {
+		return zone
+	}
+	local, err := time.Parse("2006-01-02 15:04", localtime)
+	if err != nil {
+		return time.UTC
+	}
+	// localtime tanpa detik, jadi bulatkan ke 15 menit terdekat
+	offset := time.Duration(local.Unix()-localtimeEpoch) * time.Second
+	return time.FixedZone(tzID, int(offset.Round(15*time.Minute).Seconds()))
+}
@@ -1,23 +1,36 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
-	"golang.org/x/oauth2/google"
 	genai "google.golang.org/genai"
+	"proxy/agent"
+	"proxy/answer"
+	"proxy/audio"
+	"proxy/intent"
+	"proxy/oauth"
+	"proxy/protocol"
+	"proxy/providers"
+	"proxy/schema"
+	"proxy/session"
+	"proxy/tools"
+	"proxy/traveltools"
 )
 
 const (
@@ -26,7 +39,6 @@ const (
 	PORT        = "8081"
 	// Google Text-to-Speech API endpoint
 	TTS_URL = "https://texttospeech.googleapis.com/v1/text:synthesize"
-	apiKey  = "f568e88096dc8eab78411b6fed1d5eaa"
 )
 
 var (
@@ -35,75 +47,52 @@ var (
 		CheckOrigin: func(r *http.Request) bool {
 			return true // Allow all origins (adjust as needed for security)
 		},
+		Subprotocols: protocol.Subprotocols(),
 	}
-	// Variabel untuk menyimpan koordinat lokasi
-	userLatitude  = "-7.3305"
-	userLongitude = "110.5084"
-	locationMutex sync.RWMutex // Mutex untuk mengamankan akses ke variabel lokasi
-
-	exchangeRateFunc = &genai.FunctionDeclaration{
-		Name:        "getExchangeRate",
-		Description: "Returns the current exchange rate from one currency to another.",
-		Parameters: &genai.Schema{
-			Type: genai.TypeObject,
-			Properties: map[string]*genai.Schema{
-				"from": {
-					Type:        genai.TypeString,
-					Description: "The currency code to convert from (e.g., USD, EUR, IDR).",
-				},
-				"to": {
-					Type:        genai.TypeString,
-					Description: "The currency code to convert to (e.g., USD, EUR, IDR).",
-				},
-			},
-			Required: []string{"from", "to"},
-		},
-	}
+	// Penomoran ID session untuk setiap client yang terhubung
+	sessionSeq atomic.Int64
+
+	// Provider cuaca, tempat dan kurs yang dipilih saat startup (lihat providers.ConfigFromEnv)
+	providerSet *providers.Set
+	// toolRegistry berisi semua tool yang bisa dipanggil Vertex AI lewat toolCall.
+	// Deklarasinya juga dikirim di pesan setup oleh setupVertexAI.
+	toolRegistry *tools.Registry
+	// intentRouter memutuskan pesan teks mana yang dijawab langsung, diberi
+	// data tool lebih dulu, atau diteruskan ke model (lihat intent.NewTravelRouter).
+	intentRouter *intent.Router
+
+	// Endpoint dan client untuk Text-to-Speech; bisa diarahkan ke server lain
+	// lewat TTS_BASE_URL (misal fakes.Server).
+	ttsURL        = TTS_URL
+	ttsHTTPClient = http.DefaultClient
+
+	// Endpoint Live API yang di-dial setupVertexAI; bisa diganti lewat
+	// LIVE_SERVICE_URL (misal ke fakes.LiveServer).
+	serviceURL = SERVICE_URL
+	// tokenSource dipakai bersama oleh setupVertexAI dan textToSpeech (lihat
+	// oauth.FromEnv untuk urutan kredensial).
+	tokenSource *oauth.Source
+	// toolCallTimeout membatasi satu toolCall Live API, sama dengan batas
+	// eksekusi tool di jalur REST (agent.Config.CallTimeout).
+	toolCallTimeout = agent.DefaultCallTimeout
 )
 
+// textQueueSize adalah jumlah frame text per koneksi yang boleh menunggu
+// routeTexts; frame berikutnya ditolak dengan error "busy".
+const textQueueSize = 8
+
 type AuthToken struct {
 	AccessToken string `json:"access_token"`
 }
 
+// getAccessToken mengambil bearer token dari tokenSource. Token di-cache dan
+// diperbarui sebelum kedaluwarsa, jadi aman dipanggil di setiap setupVertexAI
+// (termasuk saat client reconnect) dan setiap textToSpeech.
 func getAccessToken() (string, error) {
-	// Load .env file - don't return error if file doesn't exist
-	_ = godotenv.Load() // Ignore error if .env doesn't exist
-
-	// Get key.json path from .env
-	keyPath := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
-	if keyPath == "" {
-		// Use the path from the folder structure
-		keyPath = "d:\\SMP\\dev gilang\\translation-ai-demo\\action item\\.env\\key.json"
-		log.Printf("Using default credentials file: %s", keyPath)
-	}
-
-	// Read the key file
-	keyFile, err := os.Open(keyPath)
-	if err != nil {
-		return "", fmt.Errorf("error opening key file: %v", err)
+	if tokenSource == nil {
+		return "", fmt.Errorf("token source belum dikonfigurasi")
 	}
-	defer keyFile.Close()
-
-	keyData, err := io.ReadAll(keyFile)
-	if err != nil {
-		return "", fmt.Errorf("error reading key file: %v", err)
-	}
-
-	// Create credentials from JSON key file
-	creds, err := google.CredentialsFromJSON(context.Background(), keyData,
-		"https://www.googleapis.com/auth/cloud-platform")
-	if err != nil {
-		return "", fmt.Errorf("error creating credentials from JSON: %v", err)
-	}
-
-	// Get token from credentials
-	tokenSource := creds.TokenSource
-	token, err := tokenSource.Token()
-	if err != nil {
-		return "", fmt.Errorf("error retrieving access token: %v", err)
-	}
-
-	return token.AccessToken, nil
+	return tokenSource.AccessToken()
 }
 
 // Response struct for parsing Vertex AI responses
@@ -165,8 +154,7 @@ func textToSpeech(text, language string) (string, error) {
 	}
 
 	// Create HTTP request
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", TTS_URL, strings.NewReader(string(jsonData)))
+	req, err := http.NewRequest("POST", ttsURL, strings.NewReader(string(jsonData)))
 	if err != nil {
 		return "", fmt.Errorf("error creating TTS request: %v", err)
 	}
@@ -175,7 +163,7 @@ func textToSpeech(text, language string) (string, error) {
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send request
-	resp, err := client.Do(req)
+	resp, err := ttsHTTPClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("error sending TTS request: %v", err)
 	}
@@ -200,75 +188,6 @@ func textToSpeech(text, language string) (string, error) {
 	return ttsResp.AudioContent, nil
 }
 
-func GetExchangeRate(dari, ke string) (float64, error) {
-	// Gunakan apiKey yang sudah didefinisikan di konstanta
-	apiURL := fmt.Sprintf("https://api.exchangerate.host/convert?access_key=%s&from=%s&to=%s&amount=1", apiKey, dari, ke)
-	log.Printf("Requesting exchange rate API: %s", apiURL)
-
-	method := "GET"
-	client := &http.Client{}
-
-	req, err := http.NewRequest(method, apiURL, nil)
-	if err != nil {
-		return 0, fmt.Errorf("error membuat request: %v", err)
-	}
-
-	res, err := client.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("error mengirim request: %v", err)
-	}
-	defer res.Body.Close()
-
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return 0, fmt.Errorf("error membaca body response: %v", err)
-	}
-
-	if res.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("API mengembalikan status error: %s, Body: %s", res.Status, string(body))
-	}
-
-	// Struktur sementara untuk parsing JSON rate
-	var result struct {
-		Success bool `json:"success"`
-		Info    struct {
-			Rate float64 `json:"rate"` // Pastikan nama field sesuai dengan respons API ('rate' atau 'quote')
-		} `json:"info"`
-		// Tambahkan field lain jika perlu untuk debugging atau validasi
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		// Coba parsing alternatif jika field rate berbeda (misal: 'quote')
-		var altResult struct {
-			Success bool `json:"success"`
-			Info    struct {
-				Quote float64 `json:"quote"`
-			} `json:"info"`
-			Result float64 `json:"result"` // Field 'result' juga umum
-		}
-		if errAlt := json.Unmarshal(body, &altResult); errAlt != nil {
-			log.Printf("Raw API Response Body: %s", string(body)) // Log raw body jika unmarshal gagal
-			return 0, fmt.Errorf("error unmarshal response (kedua format gagal): %v / %v", err, errAlt)
-		}
-		if !altResult.Success || (altResult.Info.Quote == 0 && altResult.Result == 0) {
-			log.Printf("Raw API Response Body (Alt): %s", string(body))
-			return 0, fmt.Errorf("API call (alt format) tidak berhasil atau rate/result tidak valid. Success: %v, Quote: %f, Result: %f", altResult.Success, altResult.Info.Quote, altResult.Result)
-		}
-		// Gunakan Quote atau Result tergantung mana yang ada
-		if altResult.Info.Quote != 0 {
-			return altResult.Info.Quote, nil
-		}
-		return altResult.Result, nil // Asumsikan result adalah rate jika quote 0
-	}
-
-	if !result.Success || result.Info.Rate == 0 {
-		log.Printf("Raw API Response Body: %s", string(body))
-		return 0, fmt.Errorf("API call tidak berhasil atau rate tidak valid. Success: %v, Rate: %f", result.Success, result.Info.Rate)
-	}
-
-	return result.Info.Rate, nil
-}
-
 // Function to perform Google Search using Vertex AI
 func performGoogleSearch(query string) (string, error) {
 	ctx := context.Background()
@@ -281,7 +200,10 @@ func performGoogleSearch(query string) (string, error) {
 
 	// Create client using the example code approach
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		HTTPOptions: genai.HTTPOptions{APIVersion: "v1"},
+		HTTPOptions: genai.HTTPOptions{
+			BaseURL:    os.Getenv("GENAI_BASE_URL"),
+			APIVersion: "v1",
+		},
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to create genai client: %v", err)
@@ -320,260 +242,323 @@ func performGoogleSearch(query string) (string, error) {
 	return respText, nil
 }
 
-// Modify the proxyMessagesClient function to detect search queries
-func proxyMessagesClient(src, dest *websocket.Conn, name string, wg *sync.WaitGroup) {
+// proxyMessagesClient membaca frame protocol dari client dan meneruskannya ke
+// Vertex AI. Frame yang tidak dikenal atau tidak valid ditolak dengan frame
+// error yang membawa ID request-nya.
+func proxyMessagesClient(src, dest *wsConn, sess *session.Session, name string, wg *sync.WaitGroup) {
 	defer wg.Done()
 	defer src.Close()
+	// Tutup juga koneksi Vertex AI supaya proxyMessagesServer ikut selesai
+	defer dest.Close()
+
+	// Audio client diubah ke PCM 16 kHz mono per rekaman
+	pipeline := audio.NewPipeline(audio.Config{})
+
+	// Frame text dirutekan berurutan oleh routeTexts di goroutine sendiri,
+	// dengan context yang dibatalkan saat koneksi client ditutup
+	ctx, cancel := context.WithCancel(session.NewContext(context.Background(), sess))
+	defer cancel()
+	texts := make(chan *protocol.Text, textQueueSize)
+	defer close(texts)
+	go routeTexts(ctx, src, dest, sess, name, texts)
 
 	for {
 		messageType, message, err := src.ReadMessage()
 		if err != nil {
 			log.Printf("%s connection closed: %v", name, err)
-			responseMessage := fmt.Sprintf(`{"status": "fail connect to websocket", "code": 500, "message": "%v"}`, err)
-			if err := dest.WriteMessage(websocket.TextMessage, []byte(responseMessage)); err != nil {
-				log.Printf("%s error sending message: %v", name, err)
-			}
 			return
 		}
 
-		// Handle binary message (for audio input)
+		var frame protocol.ClientFrame
 		if messageType == websocket.BinaryMessage {
-			// Convert binary data to base64 string
-			base64Data := base64.StdEncoding.EncodeToString(message)
-
-			// Format for real-time audio streaming to Vertex AI
-			responseMessage := fmt.Sprintf(`{
-				"realtimeInput": {
-					"mediaChunks": [{
-						"mime_type": "audio/webm",
-						"data": "%s"
-					}]
+			// Frame biner adalah potongan audio tanpa ID
+			frame = &protocol.Audio{Data: message, MimeType: protocol.DefaultAudioMimeType}
+		} else {
+			log.Printf("Raw client message: %s", string(message))
+			frame, err = protocol.Decode(message)
+			if err != nil {
+				log.Printf("%s frame ditolak: %v", name, err)
+				id := ""
+				if frame != nil {
+					id = frame.RequestID()
 				}
-			}`, base64Data)
-
-			if err := dest.WriteMessage(websocket.TextMessage, []byte(responseMessage)); err != nil {
-				log.Printf("%s error sending audio message: %v", name, err)
-				return
+				if err := src.WriteFrame(protocol.ErrorFrame(id, err)); err != nil {
+					log.Printf("%s error sending frame error: %v", name, err)
+				}
+				continue
 			}
+		}
 
-			// Send confirmation back to client
-			audioConfirmation := `{"status": "audio_received", "code": 200}`
-			if err := src.WriteMessage(websocket.TextMessage, []byte(audioConfirmation)); err != nil {
-				log.Printf("%s error sending audio confirmation: %v", name, err)
-			}
+		if err := handleClientFrame(src, dest, sess, pipeline, texts, name, frame); err != nil {
+			log.Printf("%s error sending message: %v", name, err)
+			return
+		}
+	}
+}
 
-			continue
+// handleClientFrame memproses satu frame client. Frame text diantrekan ke
+// texts untuk routeTexts. Error hanya dikembalikan jika pengiriman ke Vertex
+// AI gagal, sehingga koneksi perlu ditutup.
+func handleClientFrame(src, dest *wsConn, sess *session.Session, pipeline *audio.Pipeline, texts chan<- *protocol.Text, name string, frame protocol.ClientFrame) error {
+	reply := func(f protocol.ServerFrame) {
+		if err := src.WriteFrame(f); err != nil {
+			log.Printf("%s error sending reply: %v", name, err)
 		}
+	}
 
-		log.Printf("Raw client message: %s", string(message))
+	switch f := frame.(type) {
+	case *protocol.Text:
+		select {
+		case texts <- f:
+		default:
+			log.Printf("%s antrean text penuh, frame %s ditolak", name, f.ID)
+			reply(protocol.ErrorFrame(f.ID, &protocol.Error{Code: protocol.CodeBusy, Message: "Terlalu banyak pesan yang sedang diproses"}))
+		}
+		return nil
 
-		// Cek apakah ini pesan lokasi
-		var locationMsg LocationMessage
-		if err := json.Unmarshal(message, &locationMsg); err == nil && locationMsg.Type == "location" {
-			// Update koordinat lokasi
-			locationMutex.Lock()
-			userLatitude = locationMsg.Latitude
-			userLongitude = locationMsg.Longitude
-			locationMutex.Unlock()
+	case *protocol.Audio:
+		// Live API hanya menerima PCM 16 kHz mono; rekaman browser
+		// di-transcode dan dikirim per frame berdurasi tetap
+		frames, err := pipeline.Write(f.Data, f.MimeType)
+		if err != nil {
+			log.Printf("%s audio ditolak: %v", name, err)
+			pipeline.Reset()
+			code := protocol.CodeInvalidAudio
+			if errors.Is(err, audio.ErrUnsupportedFormat) {
+				code = protocol.CodeUnsupportedAudio
+			}
+			reply(protocol.ErrorFrame(f.ID, &protocol.Error{Code: code, Message: err.Error()}))
+			return nil
+		}
+		if err := writeAudioFrames(dest, frames); err != nil {
+			return err
+		}
+		reply(&protocol.Ack{Header: protocol.Header{ID: f.ID}, Of: protocol.TypeAudio, Samples: pipeline.Stats().OutputSamples})
+		return nil
+
+	case *protocol.AudioEnd:
+		// Kirim sisa rekaman sebelum menandai akhir audio stream
+		frames, stats := pipeline.Flush()
+		if err := writeAudioFrames(dest, frames); err != nil {
+			return err
+		}
+		if stats.Format != "" {
+			log.Printf("%s rekaman %s %d Hz %d channel: %d sampel masuk, %d sampel 16 kHz (%v) dalam %d frame",
+				name, stats.Format, stats.SourceRate, stats.SourceChannels, stats.InputSamples, stats.OutputSamples, stats.Duration(), stats.Frames)
+		}
+		log.Printf("Sending end of audio stream to Vertex AI")
+		sess.SetRequestID(f.ID)
+		return writeRealtimeInput(dest, schema.LiveRealtimeInput{EndOfStream: true})
+
+	case *protocol.Image:
+		if err := writeRealtimeInput(dest, schema.LiveRealtimeInput{
+			MediaChunks: []*genai.Blob{{MIMEType: f.MimeType, Data: f.Data}},
+		}); err != nil {
+			return err
+		}
+		reply(&protocol.Ack{Header: protocol.Header{ID: f.ID}, Of: protocol.TypeImage})
+		return nil
 
-			log.Printf("Lokasi pengguna diperbarui: lat=%s, lon=%s", userLatitude, userLongitude)
+	case *protocol.Location:
+		// Update koordinat lokasi milik session ini saja
+		coords, err := providers.ParseCoordinates(f.Latitude.String(), f.Longitude.String())
+		if err != nil {
+			log.Printf("%s lokasi tidak valid: %v", name, err)
+			reply(protocol.ErrorFrame(f.ID, &protocol.Error{Code: protocol.CodeInvalidLocation, Message: "Lokasi tidak valid"}))
+			return nil
+		}
+		changed := sess.SetLocation(coords)
 
-			// Kirim konfirmasi ke klien
-			confirmationMsg := `{"status": "location_updated", "code": 200, "message": "Lokasi berhasil diperbarui"}`
-			if err := src.WriteMessage(websocket.TextMessage, []byte(confirmationMsg)); err != nil {
-				log.Printf("%s error sending location confirmation: %v", name, err)
+		log.Printf("Lokasi session %s diperbarui: lat=%v, lon=%v", sess.ID, coords.Latitude, coords.Longitude)
+
+		// Beritahu model lokasi terbaru lewat context turn (turn_complete
+		// false) supaya percakapan berlanjut tanpa memicu jawaban baru.
+		// Reverse geocoding bisa lambat, jadi koordinat dikirim dulu dan nama
+		// tempat menyusul di context turn berikutnya.
+		if changed {
+			contextMsg, err := locationContextMessage(coords, "")
+			if err != nil {
+				log.Printf("%s error building location context: %v", name, err)
+			} else if err := dest.WriteMessage(websocket.TextMessage, contextMsg); err != nil {
+				return err
 			}
-			continue
+			go sendLocationName(dest, sess, coords, name)
 		}
 
-		// Try to parse as JSON object first
-		var jsonData map[string]interface{}
-		if err := json.Unmarshal(message, &jsonData); err == nil {
-			// Successfully parsed as JSON object
-			if textContent, ok := jsonData["text"].(string); ok {
-				// Cek apakah pertanyaan memerlukan lokasi
-				needsLocation := false
-
-				// Kata kunci yang menunjukkan pertanyaan terkait lokasi
-				locationKeywords := []string{
-					"di dekat", "terdekat", "sekitar sini", "di sekitar",
-					"dekat sini", "di mana", "lokasi", "tempat", "jarak",
-					"restoran", "kafe", "mall", "toko", "hotel", "wisata",
-					"kuliner", "makanan", "minuman", "kopi", "nongkrong",
-				}
+		reply(&protocol.Ack{Header: protocol.Header{ID: f.ID}, Of: protocol.TypeLocation, Message: "Lokasi berhasil diperbarui"})
+		return nil
 
-				lowerText := strings.ToLower(textContent)
-				for _, keyword := range locationKeywords {
-					if strings.Contains(lowerText, keyword) {
-						needsLocation = true
-						break
-					}
-				}
+	case *protocol.Preferences:
+		sess.SetLanguage(f.Language)
+		sess.SetPreferences(f.Preferences)
+		log.Printf("Preferensi session %s diperbarui: bahasa=%s, %v", sess.ID, sess.Language(), sess.Preferences())
 
-				// Jika pertanyaan memerlukan lokasi tapi koordinat masih default
-				if needsLocation && (userLatitude == "0.0" && userLongitude == "0.0") {
-					// Kirim permintaan untuk mengaktifkan GPS
-					locationRequestMsg := `{"status": "location_request", "code": 200, "message": "Untuk menjawab pertanyaan ini, kami memerlukan lokasi Anda. Mohon aktifkan GPS dan izinkan akses lokasi."}`
-					if err := src.WriteMessage(websocket.TextMessage, []byte(locationRequestMsg)); err != nil {
-						log.Printf("%s error sending location request: %v", name, err)
-					}
-					continue
-				}
+		reply(&protocol.Ack{Header: protocol.Header{ID: f.ID}, Of: protocol.TypePreferences, Message: "Preferensi berhasil diperbarui"})
+		return nil
+	}
 
-				// === Tambahkan kode deteksi cuaca di sini ===
-				if strings.Contains(lowerText, "cuaca") || strings.Contains(lowerText, "weather") {
-					weatherResult, err := GetWeatherData(userLatitude, userLongitude)
-					if err != nil {
-						src.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"status":"weather_failed","message":"%v"}`, err)))
-					} else {
-						src.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"status":"weather_result","data":%s}`, weatherResult)))
-					}
-					continue
-				}
-				// === Sampai sini ===
-
-				// Regular message handling
-				responseMessage := fmt.Sprintf(`{
-					"client_content": {
-						"turns": [{
-							"role": "user",
-							"parts": [{ "text": %s }]
-						}],
-						"turn_complete": true
-					}
-				}`, string(json.RawMessage(fmt.Sprintf(`"%s"`, textContent))))
+	// Decode hanya mengembalikan frame di atas; sisanya tetap ditolak
+	reply(protocol.ErrorFrame(frame.RequestID(), &protocol.Error{Code: protocol.CodeUnknownFrame, Message: fmt.Sprintf("frame %T tidak ditangani", frame)}))
+	return nil
+}
 
-				log.Printf("Sending to Vertex AI: %s", responseMessage)
-				if err := dest.WriteMessage(websocket.TextMessage, []byte(responseMessage)); err != nil {
-					log.Printf("%s error sending message: %v", name, err)
-					return
-				}
-				continue
-			}
+// routeTexts menjalankan handleText untuk setiap frame dari texts secara
+// berurutan. Router intent bisa memanggil provider sampai
+// intent.DefaultTimeout, jadi dijalankan di luar loop baca
+// proxyMessagesClient supaya frame audio, lokasi dan preferensi tetap
+// dibaca. Jika pengiriman ke Vertex AI gagal, kedua koneksi ditutup.
+func routeTexts(ctx context.Context, src, dest *wsConn, sess *session.Session, name string, texts <-chan *protocol.Text) {
+	for f := range texts {
+		if err := handleText(ctx, src, dest, sess, name, f); err != nil {
+			log.Printf("%s error sending message: %v", name, err)
+			src.Close()
+			dest.Close()
+			return
 		}
+	}
+}
 
-		// If not a direct JSON object, try the Message struct
-		var requestMessage Message
-		if err := json.Unmarshal(message, &requestMessage); err != nil {
-			log.Printf("Error parsing JSON as Message: %v", err)
-			continue // Skip this message but keep connection alive
+// handleText merutekan satu frame text lewat intentRouter dengan ctx milik
+// koneksi client. Error hanya dikembalikan jika pengiriman ke Vertex AI gagal.
+func handleText(ctx context.Context, src, dest *wsConn, sess *session.Session, name string, f *protocol.Text) error {
+	reply := func(frame protocol.ServerFrame) {
+		if err := src.WriteFrame(frame); err != nil {
+			log.Printf("%s error sending reply: %v", name, err)
 		}
+	}
 
-		if requestMessage.Type == "" || requestMessage.Content == "" {
-			log.Printf("Invalid message format: %+v", requestMessage)
-			continue
+	// Router intent menjawab pertanyaan sederhana tanpa model, meminta
+	// lokasi, atau melampirkan data tool ke giliran model
+	decision := intentRouter.Route(ctx, intent.Request{
+		Text:        f.Text,
+		HasLocation: sess.HasLocation(),
+	})
+	if ctx.Err() != nil {
+		// Client sudah terputus; tidak ada yang perlu dijawab
+		return nil
+	}
+	switch decision.Action {
+	case intent.ActionNeedLocation:
+		// Minta client mengaktifkan GPS
+		reply(&protocol.LocationRequest{
+			Header:  protocol.Header{ID: f.ID},
+			Message: "Untuk menjawab pertanyaan ini, kami memerlukan lokasi Anda. Mohon aktifkan GPS dan izinkan akses lokasi.",
+		})
+		return nil
+	case intent.ActionAnswer:
+		reply(&protocol.Answer{
+			Header: protocol.Header{ID: f.ID},
+			Kind:   decision.Answer.Status,
+			Data:   decision.Answer.Data,
+		})
+		return nil
+	case intent.ActionPrefetch:
+		prefetchMsg, err := prefetchTurnMessage(f.Text, decision.Prefetch)
+		if err != nil {
+			log.Printf("%s error building prefetch turn: %v", name, err)
+			break
 		}
+		log.Printf("Sending to Vertex AI with prefetched %s: %s", decision.Prefetch.Tool, prefetchMsg)
+		sess.SetRequestID(f.ID)
+		return dest.WriteMessage(websocket.TextMessage, prefetchMsg)
+	}
 
-		log.Printf("Parsed message: %+v", requestMessage)
-
-		responseMessage := ""
-
-		if requestMessage.Type == "text" {
-			// Fix the JSON formatting and ensure proper escaping of user content
-			responseMessage = fmt.Sprintf(`{
-				"client_content": {
-					"turns": [{
-						"role": "user",
-						"parts": [{ "text": %s }]
-					}],
-					"turn_complete": true
-				}
-			}`, string(json.RawMessage(fmt.Sprintf(`"%s"`, requestMessage.Content))))
-		} else if requestMessage.Type == "audio" {
-			// Format for real-time audio streaming
-			responseMessage = fmt.Sprintf(`{
-				"realtimeInput": {
-                  "mediaChunks": [{
-                    "mime_type": "audio/webm",
-                    "data": "%s"
-                  }]
-                }
-			}`, requestMessage.Content)
-
-			// Send confirmation back to client
-			audioConfirmation := `{"status": "audio_received", "code": 200}`
-			if err := src.WriteMessage(websocket.TextMessage, []byte(audioConfirmation)); err != nil {
-				log.Printf("%s error sending audio confirmation: %v", name, err)
-			}
-		} else if requestMessage.Type == "audio_end" {
-			// Signal end of audio stream
-			responseMessage = `{
-				"realtimeInput": {
-					"endOfStream": true
-				}
-			}`
-		} else if requestMessage.Type == "image" {
-			responseMessage = fmt.Sprintf(`{
-				"realtimeInput": {
-                  "mediaChunks": [{
-                    "mime_type": "image/jpeg",
-                    "data": "%s"
-                  }]
-                }
-			}`, requestMessage.Content)
-		}
+	responseMessage, err := userTurnMessage(true, f.Text)
+	if err != nil {
+		log.Printf("%s error building text turn: %v", name, err)
+		reply(protocol.ErrorFrame(f.ID, err))
+		return nil
+	}
 
-		log.Printf("Sending to Vertex AI: %s", responseMessage)
+	log.Printf("Sending to Vertex AI: %s", responseMessage)
+	sess.SetRequestID(f.ID)
+	return dest.WriteMessage(websocket.TextMessage, responseMessage)
+}
 
-		if responseMessage != "" {
-			if err := dest.WriteMessage(websocket.TextMessage, []byte(responseMessage)); err != nil {
-				log.Printf("%s error sending message: %v", name, err)
-				return
-			}
+// userTurnMessage membuat pesan client_content berisi satu giliran user dengan
+// satu part per text. turnComplete false hanya menambah konteks tanpa memicu
+// jawaban model. Text kosong ditolak karena menjadi part tanpa isi.
+func userTurnMessage(turnComplete bool, texts ...string) ([]byte, error) {
+	parts := make([]*genai.Part, len(texts))
+	for i, text := range texts {
+		if text == "" {
+			return nil, fmt.Errorf("part %d giliran user kosong", i)
 		}
+		parts[i] = genai.NewPartFromText(text)
 	}
+	return schema.LiveMessage(schema.LiveClientMessage{
+		ClientContent: &schema.LiveClientContent{
+			Turns:        []*genai.Content{genai.NewContentFromParts(parts, genai.RoleUser)},
+			TurnComplete: turnComplete,
+		},
+	})
 }
 
-type Message struct {
-	Type    string `json:"type"`
-	Content string `json:"content"`
+// writeRealtimeInput mengirim input realtime ke Vertex AI.
+func writeRealtimeInput(dest *wsConn, input schema.LiveRealtimeInput) error {
+	msg, err := schema.LiveMessage(schema.LiveClientMessage{RealtimeInput: &input})
+	if err != nil {
+		return err
+	}
+	return dest.WriteMessage(websocket.TextMessage, msg)
 }
 
-// Struktur untuk pesan lokasi
-type LocationMessage struct {
-	Type      string `json:"type"`
-	Latitude  string `json:"latitude"`
-	Longitude string `json:"longitude"`
+// writeAudioFrames mengirim setiap frame PCM sebagai input realtime tersendiri.
+func writeAudioFrames(dest *wsConn, frames []audio.Frame) error {
+	for _, frame := range frames {
+		if err := writeRealtimeInput(dest, schema.LiveRealtimeInput{
+			MediaChunks: []*genai.Blob{{MIMEType: audio.MimeType, Data: frame.Data}},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Struktur untuk parsing toolCall dari Vertex AI
 type ToolCall struct {
-	FunctionCalls []struct {
-		Name string         `json:"name"`
-		Args map[string]any `json:"args"`
-	} `json:"functionCalls"`
+	FunctionCalls []*genai.FunctionCall `json:"functionCalls"`
 }
 
-// Struktur untuk parsing pesan lengkap dari Vertex AI
+// Struktur untuk parsing pesan lengkap dari Vertex AI. Live API mengirim field
+// dalam camelCase (serverContent.modelTurn, dst.).
 type VertexAIMessage struct {
 	ServerContent struct {
-		TurnComplete bool `json:"turn_complete"`
+		TurnComplete bool `json:"turnComplete"`
 		ModelTurn    struct {
 			Parts []struct {
 				Text string `json:"text"`
 			} `json:"parts"`
-		} `json:"model_turn"`
-	} `json:"server_content"`
-	SetupComplete      struct{}  `json:"setupComplete"`
-	ToolCall           *ToolCall `json:"toolCall,omitempty"` // Tambahkan field ToolCall
-	GenerationComplete bool      `json:"generationComplete"` // Tambahkan field generationComplete
+		} `json:"modelTurn"`
+		GenerationComplete bool `json:"generationComplete"`
+	} `json:"serverContent"`
+	SetupComplete *struct{} `json:"setupComplete"`
+	ToolCall      *ToolCall `json:"toolCall,omitempty"` // Tambahkan field ToolCall
 }
 
-func proxyMessagesServer(src, dest *websocket.Conn, name string, wg *sync.WaitGroup, initialUserMessage []byte) {
+// proxyMessagesServer meneruskan jawaban Vertex AI ke client sebagai frame
+// protocol versi version dan menjalankan toolCall.
+func proxyMessagesServer(src, dest *wsConn, sess *session.Session, version int, name string, wg *sync.WaitGroup) {
 	defer wg.Done()
 	defer src.Close()
-	log.Printf("Closing server connection for %s", name)
-	activeConnections.Delete(name) // Hapus koneksi dari map saat selesai
-	src.Close()
+	// Tutup juga koneksi client supaya proxyMessagesClient ikut selesai dan
+	// client bisa reconnect
+	defer dest.Close()
 
-	partMessage := ""
+	// Tool handler menerima session ini lewat context (lokasi, bahasa, dst.)
+	ctx := session.NewContext(context.Background(), sess)
+	// Jawaban pertama yang sedang dimintakan perbaikan formatnya, kosong jika
+	// tidak ada
+	unrepairedAnswer := ""
 
 	for {
 		_, message, err := src.ReadMessage()
 		if err != nil {
 			log.Printf("%s connection closed: %v", name, err)
-			responseMessage := fmt.Sprintf(`{"status": "fail", "code": 500, "message": "Connection to AI service lost: %v"}`, err)
-			if err := dest.WriteMessage(websocket.TextMessage, []byte(responseMessage)); err != nil {
+			if err := dest.WriteFrame(protocol.ErrorFrame(sess.RequestID(), &protocol.Error{
+				Code:    protocol.CodeUpstreamUnavailable,
+				Message: fmt.Sprintf("Connection to AI service lost: %v", err),
+			})); err != nil {
 				log.Printf("%s error sending message: %v", name, err)
 			}
 			// Hapus koneksi dari map saat ditutup
@@ -584,47 +569,45 @@ func proxyMessagesServer(src, dest *websocket.Conn, name string, wg *sync.WaitGr
 		log.Printf("Received from Vertex AI: %s", string(message))
 
 		var vertexMsg VertexAIMessage // Gunakan struct baru
-		err = json.Unmarshal(message, &vertexMsg)
+		// UseNumber menjaga digit asli argumen toolCall, misal jumlah uang
+		dec := json.NewDecoder(bytes.NewReader(message))
+		dec.UseNumber()
+		err = dec.Decode(&vertexMsg)
 		if err != nil {
 			log.Printf("Failed to decode JSON: %v", err)
 			log.Printf("Raw message: %s", string(message))
-			errorMsg := fmt.Sprintf(`{"status": "fail", "code": 500, "message": "Error processing AI response"}`)
-			dest.WriteMessage(websocket.TextMessage, []byte(errorMsg))
+			if err := dest.WriteFrame(protocol.ErrorFrame(sess.RequestID(), &protocol.Error{
+				Code:    protocol.CodeUpstreamError,
+				Message: "Error processing AI response",
+			})); err != nil {
+				log.Printf("%s error sending message: %v", name, err)
+			}
 			continue
 		}
 
-		responseMessage := ""
+		var responseFrame protocol.ServerFrame
 
 		// --- Penanganan Tool Call ---
 		if vertexMsg.ToolCall != nil && len(vertexMsg.ToolCall.FunctionCalls) > 0 {
 			log.Printf("Received tool call: %+v", vertexMsg.ToolCall)
-			// Asumsikan hanya ada satu function call per pesan untuk saat ini
-			call := vertexMsg.ToolCall.FunctionCalls[0]
 
-			// Panggil handleFunctionCall
-			funcResponse, err := handleFunctionCall(call.Name, call.Args)
-			if err != nil {
-				log.Printf("Error handling function call %s: %v", call.Name, err)
-				// Kirim pesan error kembali ke Vertex AI (opsional, tergantung kebutuhan)
-				// Atau kirim error ke client
-				errorMsg := fmt.Sprintf(`{"status": "fail", "code": 500, "message": "Error executing function %s: %v"}`, call.Name, err)
-				dest.WriteMessage(websocket.TextMessage, []byte(errorMsg))
-				continue
-			}
-
-			// Buat pesan functionResponse untuk dikirim kembali ke Vertex AI
-			functionResponsePayload := map[string]interface{}{
-				"functionResponse": map[string]interface{}{
-					"responses": []interface{}{funcResponse},
-				},
-			}
-			responseBytes, err := json.Marshal(functionResponsePayload)
+			// Jalankan semua function call secara paralel; hasilnya berurutan sesuai
+			// functionCalls dan membawa ID masing-masing call. Handler yang
+			// melewati toolCallTimeout menghasilkan error response.
+			callCtx, cancel := context.WithTimeout(ctx, toolCallTimeout)
+			funcResponses := toolRegistry.CallAll(callCtx, vertexMsg.ToolCall.FunctionCalls, tools.DefaultWorkers)
+			cancel()
+
+			// Kirim semua functionResponse ke Vertex AI dalam satu toolResponse
+			responseBytes, err := schema.LiveMessage(schema.LiveClientMessage{
+				ToolResponse: &schema.LiveToolResponse{FunctionResponses: funcResponses},
+			})
 			if err != nil {
 				log.Printf("Error marshaling function response: %v", err)
 				continue
 			}
 
-			// Kirim functionResponse ke Vertex AI (src connection)
+			// Kirim toolResponse ke Vertex AI (src connection)
 			log.Printf("Sending function response to Vertex AI: %s", string(responseBytes))
 			if err := src.WriteMessage(websocket.TextMessage, responseBytes); err != nil {
 				log.Printf("%s error sending function response: %v", name, err)
@@ -634,85 +617,102 @@ func proxyMessagesServer(src, dest *websocket.Conn, name string, wg *sync.WaitGr
 		}
 		// --- Akhir Penanganan Tool Call ---
 
-		if isSetupComplete(string(message)) { // Cek setupComplete dari raw message mungkin masih diperlukan jika parsing gagal
-			responseMessage = `{"status": "connected to Vertex AI", "code": 200, "message": "AI Assistant Ready"}`
+		if vertexMsg.SetupComplete != nil {
+			responseFrame = &protocol.Ready{Version: version, SessionID: sess.ID}
 		} else if vertexMsg.ServerContent.TurnComplete {
 			// Generate speech from AI response
 			// Pastikan partMessage tidak kosong sebelum TTS
-			if partMessage != "" {
-				audioContent, err := textToSpeech(partMessage, "en-US") // Asumsi bahasa Inggris, sesuaikan jika perlu
+			partMessage := sess.TakePartial()
+			// Giliran perbaikan yang berakhir tanpa teks tetap harus dijawab
+			// dengan jawaban pertama
+			if partMessage != "" || unrepairedAnswer != "" {
+				// Jawaban harus sesuai format JSON answer.Envelope. Jika tidak,
+				// minta model memperbaiki sekali; jika masih gagal atau kosong
+				// kirim jawaban pertama sebagai teks biasa.
+				env, err := answer.Parse(partMessage)
+				if err != nil && unrepairedAnswer == "" {
+					log.Printf("Jawaban tidak sesuai format, meminta perbaikan: %v", err)
+					repairMsg, rerr := userTurnMessage(true, answer.RepairPrompt(err))
+					if rerr == nil {
+						rerr = src.WriteMessage(websocket.TextMessage, repairMsg)
+					}
+					if rerr == nil {
+						unrepairedAnswer = partMessage
+						// Jawaban yang sudah di-stream akan diganti jawaban
+						// perbaikan
+						if err := dest.WriteFrame(&protocol.Replace{Header: protocol.Header{ID: sess.RequestID()}}); err != nil {
+							log.Printf("%s error sending replace message to client: %v", name, err)
+						}
+						continue
+					}
+					log.Printf("%s gagal mengirim permintaan perbaikan: %v", name, rerr)
+				}
+				if err != nil {
+					fallbackText := partMessage
+					if unrepairedAnswer != "" {
+						fallbackText = unrepairedAnswer
+					}
+					log.Printf("Jawaban tetap tidak sesuai format, fallback ke teks biasa: %v", err)
+					env = answer.Fallback(fallbackText)
+				}
+				unrepairedAnswer = ""
+
+				response := &protocol.Response{
+					Header:     protocol.Header{ID: sess.RequestID()},
+					Response:   env.Response,
+					Transcript: env.Transcript,
+					Locations:  env.Locations,
+					Weather:    env.Weather,
+				}
 
+				audioContent, err := textToSpeech(env.Response, sess.Language())
 				if err != nil {
 					log.Printf("Error generating speech: %v", err)
-					responseObj := map[string]interface{}{
-						"status":   "success",
-						"code":     200,
-						"response": partMessage,
-					}
-					jsonData, _ := json.Marshal(responseObj) // Abaikan error marshal untuk kesederhanaan
-					responseMessage = string(jsonData)
 				} else {
-					responseObj := map[string]interface{}{
-						"status":   "success",
-						"code":     200,
-						"response": partMessage,
-						"audio":    audioContent,
-					}
-					jsonData, _ := json.Marshal(responseObj) // Abaikan error marshal untuk kesederhanaan
-					responseMessage = string(jsonData)
+					response.Audio = audioContent
 				}
-				partMessage = "" // Reset partMessage setelah turn complete
+				responseFrame = response
 			} else {
 				// Jika partMessage kosong saat turn complete (misalnya setelah function call tanpa teks tambahan)
 				// Kirim pesan status sukses tanpa response/audio jika perlu, atau tidak kirim apa-apa
 				log.Printf("Turn complete but no text message parts received.")
-				// responseMessage = `{"status": "success", "code": 200, "message": "Action completed"}` // Contoh
 			}
 
 		} else if len(vertexMsg.ServerContent.ModelTurn.Parts) > 0 {
 			// Process all parts in the response
+			partMessage := ""
 			for _, part := range vertexMsg.ServerContent.ModelTurn.Parts {
 				if part.Text != "" {
-					partMessage += part.Text
+					partMessage = sess.AppendPartial(part.Text)
 					log.Printf("Received text part: %s", part.Text)
 				}
 			}
 
 			// Kirim update streaming ke client (dest connection)
-			streamingObj := map[string]interface{}{
-				"status":  "streaming",
-				"code":    200,
-				"partial": partMessage,
-			}
-			jsonData, err := json.Marshal(streamingObj)
-			if err != nil {
-				log.Printf("Error marshaling streaming JSON: %v", err)
-				continue
-			}
-
-			streamingResponse := string(jsonData)
-			log.Printf("Sending streaming update to client: %s", streamingResponse)
-			if err := dest.WriteMessage(websocket.TextMessage, []byte(streamingResponse)); err != nil {
+			log.Printf("Sending streaming update to client: %s", partMessage)
+			if err := dest.WriteFrame(&protocol.Streaming{
+				Header:  protocol.Header{ID: sess.RequestID()},
+				Partial: partMessage,
+			}); err != nil {
 				log.Printf("%s error sending streaming message to client: %v", name, err)
 				// Pertimbangkan untuk return atau break jika koneksi client gagal
 			}
-			// Jangan set responseMessage di sini karena sudah dikirim
+			// Jangan set responseFrame di sini karena sudah dikirim
 			continue
 
-		} else if vertexMsg.GenerationComplete { // Gunakan field yang sudah diparsing
+		} else if vertexMsg.ServerContent.GenerationComplete { // Gunakan field yang sudah diparsing
 			// Handle generationComplete message - just log it
 			log.Printf("Generation complete received from Vertex AI")
 			// Tidak perlu mengirim apa pun ke client untuk pesan ini
 		} else {
 			// Untuk tipe pesan lain yang tidak dikenal/ditangani secara eksplisit
 			log.Printf("Unhandled Vertex AI message structure: %s", string(message))
-			// responseMessage = string(message) // Hindari mengirim pesan mentah jika tidak yakin formatnya
 		}
 
-		// Kirim responseMessage ke client (dest connection) jika ada
-		if responseMessage != "" {
-			log.Printf("Sending final message to client: %s", responseMessage)
-			if err := dest.WriteMessage(websocket.TextMessage, []byte(responseMessage)); err != nil {
+		// Kirim responseFrame ke client (dest connection) jika ada
+		if responseFrame != nil {
+			log.Printf("Sending final message to client: %+v", responseFrame)
+			if err := dest.WriteFrame(responseFrame); err != nil {
 				log.Printf("%s error sending final message to client: %v", name, err)
 				// Pertimbangkan untuk return atau break jika koneksi client gagal
 			}
@@ -720,158 +720,7 @@ func proxyMessagesServer(src, dest *websocket.Conn, name string, wg *sync.WaitGr
 	}
 }
 
-func get_weather(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	// Ambil parameter kota dari query string
-	city := r.URL.Query().Get("city")
-	if city == "" {
-		http.Error(w, "Missing city parameter", http.StatusBadRequest)
-		return
-	}
-	apiKey := os.Getenv("OPENWEATHERMAP_API_KEY")
-	if apiKey == "" {
-		apiKey = "b37372f2e51c91de829a24330f394857" // Fallback API key
-		log.Println("Warning: OPENWEATHERMAP_API_KEY environment variable not set. Using hardcoded key.")
-	}
-	url := fmt.Sprintf("https://api.openweathermap.org/data/2.5/weather?q=%s&units=metric&appid=%s", city, apiKey)
-
-	// Kirim GET request ke OpenWeatherMap
-	resp, err := http.Get(url)
-	if err != nil {
-		http.Error(w, "Failed to fetch weather data", http.StatusInternalServerError)
-		return
-	}
-	defer resp.Body.Close()
-
-	// Cek jika API tidak merespon sukses
-	if resp.StatusCode != http.StatusOK {
-		http.Error(w, "OpenWeatherMap API error: "+resp.Status, http.StatusBadGateway)
-		return
-	}
-
-	// Decode JSON response
-	var weatherResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&weatherResp); err != nil {
-		http.Error(w, "Failed to decode weather data", http.StatusInternalServerError)
-		return
-	}
-
-	// Tulis hasil response ke client
-	if err := json.NewEncoder(w).Encode(weatherResp); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-	}
-}
-
-func handleFunctionCall(toolName string, params map[string]any) (*genai.FunctionResponse, error) {
-	log.Printf("Handling function call: %s with params: %+v", toolName, params) // Tambahkan log
-	switch toolName {
-	case "get_weather":
-		// Ambil parameter latitude dan longitude dari args
-		latitude, latOK := params["latitude"].(string)
-		longitude, lonOK := params["longitude"].(string)
-
-		// Coba ambil city jika latitude/longitude tidak ada (sebagai fallback)
-		city, cityOK := params["city"].(string)
-
-		if !latOK || !lonOK {
-			// Jika lat/lon tidak ada, coba gunakan city
-			if !cityOK {
-				return nil, fmt.Errorf("invalid or missing 'latitude'/'longitude' or 'city' parameter in get_weather call")
-			}
-			log.Printf("Latitude/Longitude not provided, using city: %s", city)
-			// Lakukan pencarian berdasarkan kota
-			apiKey := os.Getenv("OPENWEATHERMAP_API_KEY") // Pastikan env var diset
-			if apiKey == "" {
-				apiKey = "b37372f2e51c91de829a24330f394857" // Fallback jika env var tidak ada (tidak disarankan di produksi)
-				log.Println("Warning: OPENWEATHERMAP_API_KEY environment variable not set. Using hardcoded key.")
-				// return nil, fmt.Errorf("API key OPENWEATHERMAP_API_KEY is not set in environment variable")
-			}
-			result, err := GetWeatherByCity(city, apiKey) // Gunakan fungsi yang mengambil berdasarkan kota
-			if err != nil {
-				log.Printf("Error getting weather by city %s: %v", city, err)
-				return nil, fmt.Errorf("failed to get weather for city %s: %v", city, err)
-			}
-			log.Printf("Weather result for city %s: %s", city, result)
-			// Kembalikan hasil dalam format yang diharapkan Gemini
-			return &genai.FunctionResponse{
-				Name: "get_weather",
-				Response: map[string]any{
-					"content": result, // Pastikan result adalah string JSON
-				},
-			}, nil
-
-		} else {
-			// Jika lat/lon ada, gunakan itu
-			log.Printf("Using latitude: %s, longitude: %s", latitude, longitude)
-			apiKey := os.Getenv("OPENWEATHERMAP_API_KEY") // Pastikan env var diset
-			if apiKey == "" {
-				apiKey = "b37372f2e51c91de829a24330f394857" // Fallback jika env var tidak ada (tidak disarankan di produksi)
-				log.Println("Warning: OPENWEATHERMAP_API_KEY environment variable not set. Using hardcoded key.")
-				// return nil, fmt.Errorf("API key OPENWEATHERMAP_API_KEY is not set in environment variable")
-			}
-			// Panggil fungsi yang mengambil data cuaca berdasarkan lat/lon
-			result, err := GetWeatherData(latitude, longitude) // Asumsi Anda punya fungsi ini
-			if err != nil {
-				log.Printf("Error getting weather data for lat %s, lon %s: %v", latitude, longitude, err)
-				return nil, fmt.Errorf("failed to get weather data: %v", err)
-			}
-			log.Printf("Weather result for lat %s, lon %s: %s", latitude, longitude, result)
-			// Kembalikan hasil dalam format yang diharapkan Gemini
-			return &genai.FunctionResponse{
-				Name: "get_weather",
-				Response: map[string]any{
-					"content": result, // Pastikan result adalah string JSON
-				},
-			}, nil
-		}
-
-	default:
-		log.Printf("Unknown function call received: %s", toolName)
-		return nil, fmt.Errorf("unknown function: %s", toolName)
-	}
-}
-
-// Contoh implementasi GetWeatherData (sesuaikan dengan kode Anda)
-func GetWeatherData(lat string, lon string) (string, error) {
-	apiKey := os.Getenv("OPENWEATHERMAP_API_KEY")
-	if apiKey == "" {
-		apiKey = "b37372f2e51c91de829a24330f394857" // Fallback
-		log.Println("Warning: OPENWEATHERMAP_API_KEY environment variable not set. Using hardcoded key.")
-	}
-	url := fmt.Sprintf("https://api.openweathermap.org/data/2.5/weather?lat=%s&lon=%s&units=metric&appid=%s", lat, lon, apiKey)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch weather data: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("OpenWeatherMap API error (%d): %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read weather response body: %v", err)
-	}
-
-	// Kembalikan hasil sebagai string JSON
-	return string(bodyBytes), nil
-}
-
-// Fungsi HTTP handler get_weather mungkin tidak lagi digunakan jika Anda hanya
-// mengandalkan function calling via WebSocket. Anda bisa menghapusnya jika tidak diperlukan.
-/*
-func get_weather(w http.ResponseWriter, r *http.Request) {
-	// ... (kode handler HTTP Anda) ...
-	// Jika tidak digunakan, Anda bisa mengomentari atau menghapusnya
-	// untuk menghilangkan error unused function.
-}
-*/
-
-func setupVertexAI() (*websocket.Conn, error) {
+func setupVertexAI(sess *session.Session) (*websocket.Conn, error) {
 	token, err := getAccessToken()
 	if err != nil {
 		return nil, fmt.Errorf("Error getting access token: %v", err)
@@ -881,16 +730,15 @@ func setupVertexAI() (*websocket.Conn, error) {
 	headers := http.Header{}
 	headers.Set("Authorization", "Bearer "+token)
 
-	serverConn, _, err := dialer.Dial(SERVICE_URL, headers)
+	serverConn, _, err := dialer.Dial(serviceURL, headers)
 	if err != nil {
 		return nil, fmt.Errorf("Error connecting to Vertex AI WebSocket: %v", err)
 	}
 
-	// Gunakan koordinat lokasi terbaru
-	locationMutex.RLock()
-	latitude := userLatitude
-	longitude := userLatitude
-	locationMutex.RUnlock()
+	// Gunakan koordinat lokasi terbaru milik session ini
+	location := sess.Location()
+	latitude := strconv.FormatFloat(location.Latitude, 'f', -1, 64)
+	longitude := strconv.FormatFloat(location.Longitude, 'f', -1, 64)
 
 	systemInstruction := fmt.Sprintf(
 		`As Travel Buddy AI from Telkomsel, I answer travel-related questions based on provided videos or location, prioritizing accuracy and conciseness. I will retrieve data from Google Maps for location name, address, reviews, ratings, distance, the Google Maps link, and the profile picture of the reviewer.
@@ -904,11 +752,14 @@ func setupVertexAI() (*websocket.Conn, error) {
 	- Provide suggestions for locations or venues (e.g., food, drinks, coffee, hangout spots, malls, etc.) if the user mentions them.
 	- Suggest locations based on ratings, reviews, historical significance, and proximity.
 	- Detect the source language (audio or text) and respond to the user in the same language.
-	- Display the distance in kilometers (km) from the user's location to each suggested location.
-	- Offer currency conversion to assist foreign tourists.
-	- Provide current weather information or a weather forecast if user asks. I have access to real-time weather data from OpenWeatherMap API. If the user doesn't specify a location, I'll use their current location coordinates.
+	- Display the distance in kilometers (km) from the user's location to each suggested location, using the distance_km returned by getPlaceRecommendation; never estimate it.
+	- For nearby questions (e.g. "terdekat", "di sekitar sini") call getNearbyPlaces with the place type; use getPlaceRecommendation filters (type, min_rating, open_now, max_price) when the user asks for them.
+	- When the user names a city, landmark or address (e.g. "Malioboro"), pass it as near (places) or city (weather) instead of guessing coordinates; use geocodeLocation when you need its coordinates or the name of a location.
+	- Offer currency conversion to assist foreign tourists. For an amount (e.g. "50 EUR berapa Rupiah?") call convertCurrency and quote its converted_amount; never multiply rates yourself.
+	- For past exchange rates (e.g. "kurs SAR ke IDR minggu lalu berapa?") call getExchangeRate with date, days or start_date/end_date, and use its min, max, average and change_pct to tell travellers whether now is a good time to exchange.
+	- Provide current weather information or a weather forecast if user asks. I have access to real-time weather data from OpenWeatherMap API. If the user doesn't specify a location, I'll use their current location coordinates. For later today, tomorrow or the coming days (e.g. "besok hujan gak?") call getWeatherForecast instead of getCurrentWeather.
 	- For each location suggestion, include its corresponding Google Maps link.
-	- For the provided review of each location, include the URL of the profile picture of the user who wrote that review (if available).
+	- For the review of each location, call getPlaceDetails with the place id and quote one returned review as "review" and its author_photo_uri as "pp_reviewer". Leave both empty if getPlaceDetails returned no review; never invent them.
 	
 	For inquiries regarding Halal status, the analysis will be based on visual cues (halal logos, ingredients visible in the menu/food images). Information regarding the presence of non-halal ingredients in the menu will be provided if detected.
 	
@@ -938,87 +789,79 @@ func setupVertexAI() (*websocket.Conn, error) {
 	}
 	`, latitude, longitude)
 
-	setupPayloadVertex := fmt.Sprintf(`{
-		"setup": {
-			"model": "projects/our-service-454404-j3/locations/us-central1/publishers/google/models/gemini-2.0-flash-exp",
-			"generationConfig": {
-				"responseModalities": ["TEXT"],
-				"temperature": 0.7,
-				"topP": 0.95,
-				"topK": 40
-			},
-			"tools": [
-				{
-					"functionDeclarations": [
-						{
-							"name": "get_weather",
-							"description": "Get weather information based on latitude and longitude.",
-							"parameters": {
-								"type": "object",
-								"properties": {
-									"latitude": {
-										"type": "string",
-										"description": "Latitude lokasi user"
-									},
-									"longitude": {
-										"type": "string",
-										"description": "Longitude lokasi user"
-									}
-								},
-								"required": ["latitude", "longitude"]
-							}
-						}
-					]
-				}
-			],
-			"system_instruction": {
-				"role": "system",
-				"parts": [{
-					"text": %q
-				}]
-			}
-		}
-	}`, systemInstruction)
+	setupPayloadVertex, err := schema.LiveSetupMessage(schema.LiveSetup{
+		Model: "projects/our-service-454404-j3/locations/us-central1/publishers/google/models/gemini-2.0-flash-exp",
+		GenerationConfig: &schema.LiveGenerationConfig{
+			ResponseModalities: []string{"TEXT"},
+			Temperature:        0.7,
+			TopP:               0.95,
+			TopK:               40,
+		},
+		Tools: toolRegistry.Tools(),
+		SystemInstruction: &genai.Content{
+			Role:  "system",
+			Parts: []*genai.Part{{Text: systemInstruction}},
+		},
+	})
+	if err != nil {
+		serverConn.Close()
+		return nil, err
+	}
 
 	serverConn.WriteMessage(websocket.TextMessage, []byte(setupPayloadVertex))
 
 	return serverConn, nil
 }
 
-func isSetupComplete(jsonStr string) bool {
-	return strings.Contains(jsonStr, `"setupComplete": {}`)
+// wsConn membungkus websocket.Conn supaya WriteMessage aman dipanggil dari
+// beberapa goroutine; gorilla/websocket hanya mengizinkan satu writer.
+type wsConn struct {
+	*websocket.Conn
+	writeMu sync.Mutex
 }
 
-func GetWeatherByCity(city, apiKey string) (string, error) {
-	url := fmt.Sprintf("https://api.openweathermap.org/data/2.5/weather?lat=%s&lon=%s&units=metric&appid=%s", userLatitude, userLongitude, apiKey)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("failed to call weather API: %w", err)
-	}
-	defer resp.Body.Close()
+func (c *wsConn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.Conn.WriteMessage(messageType, data)
+}
 
-	body, err := io.ReadAll(resp.Body)
+// WriteFrame mengirim frame protocol ke client.
+func (c *wsConn) WriteFrame(frame protocol.ServerFrame) error {
+	data, err := protocol.Encode(frame)
 	if err != nil {
-		return "", fmt.Errorf("failed to read weather API response: %w", err)
+		return fmt.Errorf("error marshal frame: %v", err)
 	}
-
-	return string(body), nil
+	return c.WriteMessage(websocket.TextMessage, data)
 }
 
-func handleClient(clientConn *websocket.Conn) {
-	log.Println("New client connected")
+// handleClient menghubungkan satu client yang memakai protocol versi version
+// ke Vertex AI.
+func handleClient(conn *websocket.Conn, version int) {
+	clientConn := &wsConn{Conn: conn}
 
-	serverConn, err := setupVertexAI()
+	// Setiap koneksi punya Session sendiri; lokasi dan preferensi satu client
+	// tidak memengaruhi client lain
+	sess := session.New(fmt.Sprintf("session-%d", sessionSeq.Add(1)))
+	log.Println("New client connected:", sess.ID)
+
+	vertexConn, err := setupVertexAI(sess)
 	if err != nil {
 		log.Println("Failed to setup Vertex AI connection:", err)
+		if err := clientConn.WriteFrame(protocol.ErrorFrame("", &protocol.Error{
+			Code:    protocol.CodeUpstreamUnavailable,
+			Message: "Failed to connect to AI service",
+		})); err != nil {
+			log.Println("Failed to send setup error:", err)
+		}
 		clientConn.Close()
 		return
 	}
 
+	serverConn := &wsConn{Conn: vertexConn}
 	defer serverConn.Close()
 
-	activeConnections.Store(clientConn, true)
+	activeConnections.Store(clientConn, sess)
 
 	var count int
 	activeConnections.Range(func(key, value interface{}) bool {
@@ -1029,8 +872,8 @@ func handleClient(clientConn *websocket.Conn) {
 
 	var wg sync.WaitGroup
 	wg.Add(2)
-	go proxyMessagesClient(clientConn, serverConn, "Client->Server", &wg)
-	go proxyMessagesServer(serverConn, clientConn, "Server->Client", &wg)
+	go proxyMessagesClient(clientConn, serverConn, sess, "Client->Server", &wg)
+	go proxyMessagesServer(serverConn, clientConn, sess, version, "Server->Client", &wg)
 	wg.Wait()
 
 	activeConnections.Delete(clientConn)
@@ -1048,7 +891,7 @@ func cleanupConnections() {
 		log.Println("Checking active connections:", count)
 
 		activeConnections.Range(func(key, value interface{}) bool {
-			conn := key.(*websocket.Conn)
+			conn := key.(*wsConn)
 			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				log.Println("Found stale connection, closing...")
 				conn.Close()
@@ -1059,78 +902,157 @@ func cleanupConnections() {
 	}
 }
 
-func main() {
-	log.Println("Starting WebSocket proxy server on port", PORT)
-
-	// Serve static files for the web interface
-	http.Handle("/", http.FileServer(http.Dir("templates")))
-
-	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		conn, err := upgrader.Upgrade(w, r, nil)
-		if err != nil {
-			log.Println("Failed to upgrade connection:", err)
-			return
-		}
-		handleClient(conn)
-	})
-
-	go cleanupConnections()
-
-	if err := http.ListenAndServe("127.0.0.1:"+PORT, nil); err != nil {
-		log.Fatal("Server failed to start:", err)
+// locationContextMessage membuat pesan client_content tanpa turn_complete yang
+// memberi tahu model lokasi pengguna terbaru. System instruction hanya berisi
+// lokasi saat connect, jadi pesan ini menjaga pertanyaan seperti "terdekat"
+// tetap memakai posisi sekarang. placeName (hasil reverse geocoding) boleh
+// kosong.
+func locationContextMessage(coords providers.Coordinates, placeName string) ([]byte, error) {
+	where := ""
+	if placeName != "" {
+		where = fmt.Sprintf(" (%s)", placeName)
 	}
+	text := fmt.Sprintf(
+		"[Context update] The user's current location is now latitude: %s and longitude: %s%s. Use this location instead of any earlier one for nearby places, distances and weather. Do not reply to this message.",
+		strconv.FormatFloat(coords.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(coords.Longitude, 'f', -1, 64),
+		where,
+	)
+	return userTurnMessage(false, text)
 }
 
-// Struct untuk response dari OpenWeatherMap
-type WeatherAPIResponse struct {
-	Main struct {
-		Temp     float64 `json:"temp"`
-		Humidity int     `json:"humidity"`
-	} `json:"main"`
-	Wind struct {
-		Speed float64 `json:"speed"`
-		Deg   int     `json:"deg"`
-	} `json:"wind"`
-	Weather []struct {
-		Description string `json:"description"`
-	} `json:"weather"`
+// sendLocationName mencari nama tempat coords lalu mengirim context turn
+// susulan yang memuatnya. Tidak dikirim jika nama tidak ditemukan atau
+// lokasi pengguna sudah berubah lagi. Penulisan ke dest diserialisasi wsConn.
+func sendLocationName(dest *wsConn, sess *session.Session, coords providers.Coordinates, name string) {
+	placeName := lookupPlaceName(coords)
+	if placeName == "" || !sess.SetLocationName(coords, placeName) {
+		return
+	}
+	contextMsg, err := locationContextMessage(coords, placeName)
+	if err != nil {
+		log.Printf("%s error building location context: %v", name, err)
+		return
+	}
+	if err := dest.WriteMessage(websocket.TextMessage, contextMsg); err != nil {
+		log.Printf("%s error sending location name: %v", name, err)
+	}
 }
 
-// Ambil data mentah dari OpenWeatherMap
-func getWeatherFromAPI(lat, lon, apiKey string) ([]byte, error) {
-	url := fmt.Sprintf("https://api.openweathermap.org/data/2.5/weather?lat=%s&lon=%s&units=metric&appid=%s", lat, lon, apiKey)
-
-	resp, err := http.Get(url)
+// lookupPlaceName mencari nama tempat untuk coords lewat reverse geocoding.
+// Kegagalan hanya dicatat karena nama tempat sekadar pelengkap koordinat.
+func lookupPlaceName(coords providers.Coordinates) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	name, err := providers.PlaceName(ctx, providerSet.Geocoding, coords)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call weather API: %w", err)
+		log.Printf("Reverse geocoding %v gagal: %v", coords, err)
 	}
-	defer resp.Body.Close()
+	return name
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("weather API returned non-200 status: %d", resp.StatusCode)
+// prefetchTurnMessage membuat pesan client_content berisi text pengguna dan
+// hasil tool yang sudah diambil router intent, supaya model bisa langsung
+// menjawab tanpa memanggil tool yang sama.
+func prefetchTurnMessage(text string, prefetch *intent.Prefetch) ([]byte, error) {
+	data, err := json.Marshal(prefetch.Data)
+	if err != nil {
+		return nil, fmt.Errorf("error marshal prefetch: %v", err)
 	}
+	note := fmt.Sprintf(
+		"[Prefetched %s result for the user's location] %s\nUse this data to answer; call %s again only if the user needs a different location or range.",
+		prefetch.Tool, data, prefetch.Tool,
+	)
+	return userTurnMessage(true, text, note)
+}
 
-	return io.ReadAll(resp.Body)
+// intentEmbedder membuat Embedder untuk classifier embedding router intent
+// jika INTENT_EMBEDDING_MODEL diisi (misal "text-embedding-005"), atau nil
+// jika tidak.
+func intentEmbedder() intent.Embedder {
+	model := os.Getenv("INTENT_EMBEDDING_MODEL")
+	if model == "" {
+		return nil
+	}
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		HTTPOptions: genai.HTTPOptions{
+			BaseURL: os.Getenv("GENAI_BASE_URL"),
+		},
+	})
+	if err != nil {
+		log.Println("WARNING: embedding intent classifier dinonaktifkan:", err)
+		return nil
+	}
+	return &intent.ModelsEmbedder{Models: client.Models, Model: model}
 }
 
-func getFormattedWeather(lat, lon, apiKey string) ([]byte, error) {
-	raw, err := getWeatherFromAPI(lat, lon, apiKey)
+func main() {
+	log.Println("Starting WebSocket proxy server on port", PORT)
+
+	_ = godotenv.Load() // Ignore error if .env doesn't exist
+
+	var err error
+	providerSet, err = providers.New(providers.ConfigFromEnv())
 	if err != nil {
-		return nil, err
+		log.Fatal("Failed to create providers:", err)
+	}
+	toolRegistry = tools.NewRegistry()
+	traveltools.Register(toolRegistry, providerSet)
+	intentRouter = intent.NewTravelRouter(toolRegistry, intentEmbedder())
+
+	tokenSource, err = oauth.FromEnv(context.Background())
+	if err != nil {
+		log.Println("WARNING: failed to create token source:", err)
+	} else {
+		go tokenSource.Run(context.Background())
 	}
 
-	var data WeatherAPIResponse
-	if err := json.Unmarshal(raw, &data); err != nil {
-		return nil, fmt.Errorf("failed to parse weather response: %w", err)
+	if u := os.Getenv("LIVE_SERVICE_URL"); u != "" {
+		serviceURL = u
 	}
+	if base := os.Getenv("TTS_BASE_URL"); base != "" {
+		ttsURL = strings.TrimRight(base, "/") + "/v1/text:synthesize"
+	}
+
+	registerRoutes(http.DefaultServeMux)
 
-	formatted := map[string]any{
-		"current":     data.Weather[0].Description,
-		"temperature": fmt.Sprintf("%.1f°C", data.Main.Temp),
-		"humidity":    fmt.Sprintf("%d%%", data.Main.Humidity),
-		"wind":        fmt.Sprintf("%.1f km/h from %d°", data.Wind.Speed, data.Wind.Deg),
-		"description": data.Weather[0].Description,
+	go cleanupConnections()
+
+	if err := http.ListenAndServe("127.0.0.1:"+PORT, nil); err != nil {
+		log.Fatal("Server failed to start:", err)
 	}
+}
 
-	return json.Marshal(formatted)
+// registerRoutes memasang file statis, /weather dan WebSocket /ws ke mux.
+func registerRoutes(mux *http.ServeMux) {
+	// Serve static files for the web interface
+	mux.Handle("/", http.FileServer(http.Dir("templates")))
+
+	mux.HandleFunc("/weather", weatherHandler)
+
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		// Versi protocol disepakati sebelum upgrade. Versi yang tidak didukung
+		// ditolak dengan HTTP 400 berisi frame error: jika subprotocol tidak
+		// di-echo, browser menggagalkan handshake sehingga frame error setelah
+		// upgrade tidak akan pernah sampai
+		version, versionErr := protocol.Negotiate(r)
+		if versionErr != nil {
+			log.Println("Rejecting client:", versionErr)
+			data, err := protocol.Encode(protocol.ErrorFrame("", versionErr))
+			if err != nil {
+				http.Error(w, versionErr.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(data)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Println("Failed to upgrade connection:", err)
+			return
+		}
+		handleClient(conn, version)
+	})
 }
@@ -0,0 +1,217 @@
+// Package schema membangun genai.FunctionDeclaration dari struct argumen Go.
+//
+// Nama properti diambil dari tag `json`, sedangkan metadata schema dibaca dari
+// tag berikut:
+//
+//	description:"teks"   deskripsi properti untuk model
+//	enum:"a,b,c"         daftar nilai yang diperbolehkan (hanya string)
+//	required:"true"      properti wajib diisi
+//	pattern:"^[A-Z]{3}$" regex yang harus dipenuhi nilai string
+//	minimum:"0"          nilai minimum untuk angka
+//	maximum:"5"          nilai maksimum untuk angka
+//
+// Deklarasi yang sama dipakai untuk GenerateContentConfig.Tools (REST) dan
+// untuk pesan setup Live API (WebSocket) lewat LiveSetupMessage, jadi kedua jalur
+// tidak bisa berbeda schema.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	genai "google.golang.org/genai"
+)
+
+// FunctionDeclaration membuat deklarasi fungsi dengan parameter yang
+// diturunkan dari struct T. Panic jika T bukan struct atau berisi tipe yang
+// tidak bisa dipetakan ke schema; deklarasi dibuat saat startup.
+func FunctionDeclaration[T any](name, description string) *genai.FunctionDeclaration {
+	params, err := Of(reflect.TypeFor[T]())
+	if err != nil {
+		panic(fmt.Sprintf("schema: %s: %v", name, err))
+	}
+	if params.Type != genai.TypeObject {
+		panic(fmt.Sprintf("schema: %s: argumen harus berupa struct, bukan %s", name, reflect.TypeFor[T]()))
+	}
+	return &genai.FunctionDeclaration{
+		Name:        name,
+		Description: description,
+		Parameters:  params,
+	}
+}
+
+// Of memetakan tipe Go ke genai.Schema.
+func Of(t reflect.Type) (*genai.Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// json.Number menyimpan angka sebagai teks desimal aslinya
+	if t == reflect.TypeFor[json.Number]() {
+		return &genai.Schema{Type: genai.TypeNumber}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &genai.Schema{Type: genai.TypeString}, nil
+	case reflect.Bool:
+		return &genai.Schema{Type: genai.TypeBoolean}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &genai.Schema{Type: genai.TypeInteger}, nil
+	case reflect.Float32, reflect.Float64:
+		return &genai.Schema{Type: genai.TypeNumber}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := Of(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &genai.Schema{Type: genai.TypeArray, Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("key map harus string, bukan %s", t.Key())
+		}
+		return &genai.Schema{Type: genai.TypeObject}, nil
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return nil, fmt.Errorf("tipe %s tidak didukung", t)
+}
+
+func structSchema(t reflect.Type) (*genai.Schema, error) {
+	s := &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: map[string]*genai.Schema{},
+		Required:   []string{},
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+
+		prop, err := Of(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		prop.Description = field.Tag.Get("description")
+		prop.Pattern = field.Tag.Get("pattern")
+		if enum := field.Tag.Get("enum"); enum != "" {
+			if prop.Type != genai.TypeString {
+				return nil, fmt.Errorf("field %s: tag enum hanya untuk string", field.Name)
+			}
+			for _, v := range strings.Split(enum, ",") {
+				prop.Enum = append(prop.Enum, strings.TrimSpace(v))
+			}
+		}
+		for tag, dst := range map[string]**float64{"minimum": &prop.Minimum, "maximum": &prop.Maximum} {
+			v := field.Tag.Get(tag)
+			if v == "" {
+				continue
+			}
+			if prop.Type != genai.TypeNumber && prop.Type != genai.TypeInteger {
+				return nil, fmt.Errorf("field %s: tag %s hanya untuk angka", field.Name, tag)
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: tag %s tidak valid: %v", field.Name, tag, err)
+			}
+			*dst = &f
+		}
+		if field.Tag.Get("required") == "true" {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+	return s, nil
+}
+
+// jsonName mengembalikan nama properti sesuai tag json; false jika field
+// dilewati (tag "-").
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// LiveGenerationConfig adalah "generationConfig" pada pesan setup Live API.
+type LiveGenerationConfig struct {
+	ResponseModalities []string `json:"responseModalities,omitempty"`
+	Temperature        float32  `json:"temperature"`
+	TopP               float32  `json:"topP,omitempty"`
+	TopK               float32  `json:"topK,omitempty"`
+}
+
+// LiveSetup adalah isi pesan setup BidiGenerateContent. Tools memakai
+// genai.Tool yang sama dengan GenerateContentConfig.Tools.
+type LiveSetup struct {
+	Model             string                `json:"model"`
+	GenerationConfig  *LiveGenerationConfig `json:"generationConfig,omitempty"`
+	Tools             []*genai.Tool         `json:"tools,omitempty"`
+	SystemInstruction *genai.Content        `json:"system_instruction,omitempty"`
+}
+
+// LiveSetupMessage membungkus setup menjadi pesan {"setup": ...} yang dikirim
+// pertama kali setelah WebSocket Live API tersambung.
+func LiveSetupMessage(setup LiveSetup) ([]byte, error) {
+	data, err := json.Marshal(struct {
+		Setup LiveSetup `json:"setup"`
+	}{setup})
+	if err != nil {
+		return nil, fmt.Errorf("error marshal setup message: %w", err)
+	}
+	return data, nil
+}
+
+// LiveClientContent adalah "client_content": giliran percakapan yang
+// ditambahkan ke sesi. TurnComplete false hanya menambah konteks tanpa
+// memicu jawaban model.
+type LiveClientContent struct {
+	Turns        []*genai.Content `json:"turns"`
+	TurnComplete bool             `json:"turn_complete"`
+}
+
+// LiveRealtimeInput adalah "realtimeInput": potongan audio atau gambar yang
+// di-stream, atau tanda akhir stream.
+type LiveRealtimeInput struct {
+	MediaChunks []*genai.Blob `json:"mediaChunks,omitempty"`
+	EndOfStream bool          `json:"endOfStream,omitempty"`
+}
+
+// LiveToolResponse adalah "toolResponse": hasil semua function call dari satu
+// toolCall.
+type LiveToolResponse struct {
+	FunctionResponses []*genai.FunctionResponse `json:"functionResponses"`
+}
+
+// LiveClientMessage adalah satu pesan client ke Live API setelah setup; isi
+// tepat satu field.
+type LiveClientMessage struct {
+	ClientContent *LiveClientContent `json:"client_content,omitempty"`
+	RealtimeInput *LiveRealtimeInput `json:"realtimeInput,omitempty"`
+	ToolResponse  *LiveToolResponse  `json:"toolResponse,omitempty"`
+}
+
+// LiveMessage menulis msg sebagai JSON. Semua teks dan data biner di-escape
+// oleh encoding/json, jadi isi pesan pengguna tidak bisa mengubah struktur
+// pesan.
+func LiveMessage(msg LiveClientMessage) ([]byte, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("error marshal live message: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	genai "google.golang.org/genai"
+)
+
+type weatherArgs struct {
+	City     string   `json:"city" description:"Nama kota" required:"true"`
+	Units    string   `json:"units,omitempty" enum:"metric, imperial"`
+	Currency string   `json:"currency" pattern:"^[A-Z]{3}$"`
+	Days     int      `json:"days" minimum:"1" maximum:"7"`
+	Radius   *float64 `json:"radius" minimum:"0.5"`
+	Tags     []string `json:"tags"`
+	Internal string   `json:"-"`
+	Untagged bool
+	hidden   string
+}
+
+func TestFunctionDeclaration(t *testing.T) {
+	decl := FunctionDeclaration[weatherArgs]("getWeather", "Cuaca kota")
+	if decl.Name != "getWeather" || decl.Description != "Cuaca kota" {
+		t.Errorf("Name, Description = %q, %q", decl.Name, decl.Description)
+	}
+	params := decl.Parameters
+	if params.Type != genai.TypeObject {
+		t.Fatalf("Type = %s, ingin OBJECT", params.Type)
+	}
+	if !reflect.DeepEqual(params.Required, []string{"city"}) {
+		t.Errorf("Required = %v, ingin [city]", params.Required)
+	}
+
+	var names []string
+	for name := range params.Properties {
+		names = append(names, name)
+	}
+	for _, name := range []string{"city", "units", "currency", "days", "radius", "tags", "Untagged"} {
+		if params.Properties[name] == nil {
+			t.Errorf("properti %s tidak ada; properti = %v", name, names)
+		}
+	}
+	for _, name := range []string{"-", "Internal", "hidden"} {
+		if params.Properties[name] != nil {
+			t.Errorf("properti %s seharusnya dilewati", name)
+		}
+	}
+	if len(params.Properties) != 7 {
+		t.Errorf("properti = %v, ingin 7", names)
+	}
+
+	city := params.Properties["city"]
+	if city.Type != genai.TypeString || city.Description != "Nama kota" {
+		t.Errorf("city = %+v", city)
+	}
+	if units := params.Properties["units"]; !reflect.DeepEqual(units.Enum, []string{"metric", "imperial"}) {
+		t.Errorf("units.Enum = %q", units.Enum)
+	}
+	if currency := params.Properties["currency"]; currency.Pattern != "^[A-Z]{3}$" {
+		t.Errorf("currency.Pattern = %q", currency.Pattern)
+	}
+	days := params.Properties["days"]
+	if days.Type != genai.TypeInteger || days.Minimum == nil || *days.Minimum != 1 || days.Maximum == nil || *days.Maximum != 7 {
+		t.Errorf("days = %+v", days)
+	}
+	radius := params.Properties["radius"]
+	if radius.Type != genai.TypeNumber || radius.Minimum == nil || *radius.Minimum != 0.5 || radius.Maximum != nil {
+		t.Errorf("radius = %+v", radius)
+	}
+	if tags := params.Properties["tags"]; tags.Type != genai.TypeArray || tags.Items == nil || tags.Items.Type != genai.TypeString {
+		t.Errorf("tags = %+v", tags)
+	}
+	if untagged := params.Properties["Untagged"]; untagged.Type != genai.TypeBoolean {
+		t.Errorf("Untagged = %+v", untagged)
+	}
+}
+
+func TestFunctionDeclarationPanics(t *testing.T) {
+	type enumOnInt struct {
+		Days int `json:"days" enum:"1,2"`
+	}
+	type minimumOnString struct {
+		City string `json:"city" minimum:"1"`
+	}
+	tests := []struct {
+		name string
+		fn   func()
+		want string
+	}{
+		{name: "bukan struct", fn: func() { FunctionDeclaration[string]("f", "") }, want: "harus berupa struct"},
+		{name: "enum pada int", fn: func() { FunctionDeclaration[enumOnInt]("f", "") }, want: "tag enum hanya untuk string"},
+		{name: "minimum pada string", fn: func() { FunctionDeclaration[minimumOnString]("f", "") }, want: "tag minimum hanya untuk angka"},
+		{name: "tipe tidak didukung", fn: func() { FunctionDeclaration[struct{ C chan int }]("f", "") }, want: "tidak didukung"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				msg, _ := r.(string)
+				if !strings.Contains(msg, tt.want) {
+					t.Errorf("panic = %v, ingin memuat %q", r, tt.want)
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
+
+func TestLiveSetupMessage(t *testing.T) {
+	type args struct {
+		Days int `json:"days" required:"true" minimum:"1"`
+	}
+	data, err := LiveSetupMessage(LiveSetup{
+		Model:            "projects/p/locations/l/publishers/google/models/m",
+		GenerationConfig: &LiveGenerationConfig{ResponseModalities: []string{"TEXT"}},
+		Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{
+			FunctionDeclaration[args]("getForecast", "Prakiraan"),
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("LiveSetupMessage: %v", err)
+	}
+	want := `{"setup":{"model":"projects/p/locations/l/publishers/google/models/m",` +
+		`"generationConfig":{"responseModalities":["TEXT"],"temperature":0},` +
+		`"tools":[{"functionDeclarations":[{"description":"Prakiraan","name":"getForecast",` +
+		`"parameters":{"properties":{"days":{"minimum":1,"type":"INTEGER"}},"required":["days"],"type":"OBJECT"}}]}]}}`
+	if string(data) != want {
+		t.Errorf("LiveSetupMessage =\n%s\ningin\n%s", data, want)
+	}
+}
+
+func TestLiveMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  LiveClientMessage
+		want string
+	}{
+		{
+			name: "client_content",
+			msg: LiveClientMessage{ClientContent: &LiveClientContent{
+				Turns:        []*genai.Content{genai.NewContentFromText(`"}, {"x": 1`, genai.RoleUser)},
+				TurnComplete: true,
+			}},
+			want: `{"client_content":{"turns":[{"parts":[{"text":"\"}, {\"x\": 1"}],"role":"user"}],"turn_complete":true}}`,
+		},
+		{
+			name: "realtimeInput",
+			msg: LiveClientMessage{RealtimeInput: &LiveRealtimeInput{
+				MediaChunks: []*genai.Blob{{MIMEType: "audio/pcm;rate=16000", Data: []byte{1, 2}}},
+			}},
+			want: `{"realtimeInput":{"mediaChunks":[{"data":"AQI=","mimeType":"audio/pcm;rate=16000"}]}}`,
+		},
+		{
+			name: "toolResponse",
+			msg: LiveClientMessage{ToolResponse: &LiveToolResponse{
+				FunctionResponses: []*genai.FunctionResponse{{ID: "c1", Name: "getForecast", Response: map[string]any{"ok": true}}},
+			}},
+			want: `{"toolResponse":{"functionResponses":[{"id":"c1","name":"getForecast","response":{"ok":true}}]}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := LiveMessage(tt.msg)
+			if err != nil {
+				t.Fatalf("LiveMessage: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("LiveMessage =\n%s\ningin\n%s", data, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Package session menyimpan state per koneksi WebSocket: lokasi (beserta nama
+// tempatnya), bahasa dan preferensi pengguna. Riwayat percakapan tidak
+// disimpan di sini karena sudah dipegang sesi Live API. Session dibawa lewat
+// context.Context sehingga tool handler bisa membaca state milik pemanggilnya
+// tanpa variabel global.
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"proxy/providers"
+)
+
+// DefaultLanguage adalah bahasa TTS sebelum client mengirim bahasanya.
+const DefaultLanguage = "en-US"
+
+// DefaultLocation adalah lokasi awal session sebelum client mengirim GPS.
+// Lokasi ini hanya perkiraan untuk instruksi sistem; HasLocation tetap false
+// sampai SetLocation dipanggil.
+var DefaultLocation = providers.Coordinates{Latitude: -7.3305, Longitude: 110.5084}
+
+// Session adalah state satu pengguna yang terhubung. Semua method aman
+// dipanggil dari beberapa goroutine.
+type Session struct {
+	ID        string
+	CreatedAt time.Time
+
+	mu          sync.RWMutex
+	location    providers.Coordinates
+	locationSet bool
+	placeName   string
+	language    string
+	preferences map[string]string
+	partial     string
+	requestID   string
+}
+
+// New membuat Session dengan DefaultLocation dan DefaultLanguage.
+func New(id string) *Session {
+	return &Session{
+		ID:          id,
+		CreatedAt:   time.Now(),
+		location:    DefaultLocation,
+		language:    DefaultLanguage,
+		preferences: make(map[string]string),
+	}
+}
+
+// Location mengembalikan lokasi terakhir pengguna, atau DefaultLocation jika
+// client belum mengirim lokasi.
+func (s *Session) Location() providers.Coordinates {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.location
+}
+
+// SetLocation mengganti lokasi pengguna dan melaporkan apakah lokasinya
+// berubah. Lokasi pertama dari client selalu dianggap berubah, meski sama
+// dengan DefaultLocation.
+func (s *Session) SetLocation(c providers.Coordinates) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := !s.locationSet || s.location != c
+	s.location = c
+	s.locationSet = true
+	if changed {
+		s.placeName = ""
+	}
+	return changed
+}
+
+// LocationName mengembalikan nama tempat lokasi pengguna hasil reverse
+// geocoding, atau "" jika belum diketahui.
+func (s *Session) LocationName() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.placeName
+}
+
+// SetLocationName menyimpan name sebagai nama tempat untuk lokasi c dan
+// melaporkan apakah name disimpan. Diabaikan jika lokasi pengguna sudah
+// berubah dari c.
+func (s *Session) SetLocationName(c providers.Coordinates, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.location != c {
+		return false
+	}
+	s.placeName = name
+	return true
+}
+
+// HasLocation melaporkan apakah client sudah mengirim lokasinya lewat
+// SetLocation; DefaultLocation tidak dihitung.
+func (s *Session) HasLocation() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.locationSet
+}
+
+// Language mengembalikan kode bahasa BCP-47 pengguna, misal "id-ID".
+func (s *Session) Language() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.language
+}
+
+// SetLanguage mengganti bahasa pengguna; string kosong diabaikan.
+func (s *Session) SetLanguage(lang string) {
+	if lang == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.language = lang
+}
+
+// Preference mengembalikan preferensi pengguna untuk key.
+func (s *Session) Preference(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.preferences[key]
+	return v, ok
+}
+
+// SetPreferences menggabungkan prefs ke preferensi pengguna. Nilai kosong
+// menghapus key tersebut.
+func (s *Session) SetPreferences(prefs map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range prefs {
+		if v == "" {
+			delete(s.preferences, k)
+			continue
+		}
+		s.preferences[k] = v
+	}
+}
+
+// Preferences mengembalikan salinan semua preferensi pengguna.
+func (s *Session) Preferences() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	prefs := make(map[string]string, len(s.preferences))
+	for k, v := range s.preferences {
+		prefs[k] = v
+	}
+	return prefs
+}
+
+// AppendPartial menambahkan potongan teks jawaban model yang sedang di-stream
+// dan mengembalikan teks sejauh ini.
+func (s *Session) AppendPartial(text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.partial += text
+	return s.partial
+}
+
+// TakePartial mengembalikan jawaban model yang sudah terkumpul lalu
+// mengosongkannya; dipanggil saat turnComplete.
+func (s *Session) TakePartial() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	text := s.partial
+	s.partial = ""
+	return text
+}
+
+// RequestID mengembalikan ID request klien yang sedang dijawab model.
+func (s *Session) RequestID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.requestID
+}
+
+// SetRequestID mencatat ID request klien yang memulai giliran model, supaya
+// jawaban model bisa membawa ID yang sama.
+func (s *Session) SetRequestID(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestID = id
+}
+
+type contextKey struct{}
+
+// NewContext mengembalikan ctx yang membawa s.
+func NewContext(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext mengembalikan Session yang dibawa ctx, jika ada.
+func FromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(contextKey{}).(*Session)
+	return s, ok
+}
@@ -0,0 +1,35 @@
+package session
+
+import (
+	"testing"
+
+	"proxy/providers"
+)
+
+func TestSetLocation(t *testing.T) {
+	other := providers.Coordinates{Latitude: -6.2, Longitude: 106.8}
+	tests := []struct {
+		name string
+		set  []providers.Coordinates
+		// changed adalah hasil SetLocation untuk setiap set.
+		changed []bool
+		want    bool
+	}{
+		{name: "belum ada lokasi", want: false},
+		{name: "lokasi sama dengan default", set: []providers.Coordinates{DefaultLocation}, changed: []bool{true}, want: true},
+		{name: "lokasi diulang", set: []providers.Coordinates{other, other}, changed: []bool{true, false}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New("s")
+			for i, c := range tt.set {
+				if got := s.SetLocation(c); got != tt.changed[i] {
+					t.Errorf("SetLocation #%d = %v, ingin %v", i, got, tt.changed[i])
+				}
+			}
+			if got := s.HasLocation(); got != tt.want {
+				t.Errorf("HasLocation = %v, ingin %v", got, tt.want)
+			}
+		})
+	}
+}
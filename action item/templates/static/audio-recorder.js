@@ -209,7 +209,7 @@ class AudioRecorder {
       const view = new DataView(buffer);
       pcm.forEach((value, index) => view.setInt16(index * 2, value, true));
       
-      const blob = new Blob([buffer], { type: 'audio/webm' });
+      const blob = new Blob([buffer], { type: 'audio/pcm;rate=16000' });
       const reader = new FileReader();
       
       reader.onloadend = () => {
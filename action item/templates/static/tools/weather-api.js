@@ -14,29 +14,13 @@
  * limitations under the License.
  */
 
-const OPENWEATHER_API_KEY = '<YOUR_OPENWEATHER_API_KEY>';
+// Cuaca diambil lewat endpoint /weather di proxy, yang memakai WeatherProvider
+// yang sama dengan tool getCurrentWeather di sisi Go.
+const PROXY_URL = 'http://localhost:8081';
 
 export async function getWeather(city) {
   try {
-    // First get coordinates for the city
-    const geoUrl = `https://api.openweathermap.org/geo/1.0/direct?q=${encodeURIComponent(city)}&limit=1&appid=${OPENWEATHER_API_KEY}`;
-    console.log('Fetching geo data from:', geoUrl);
-    const geoResponse = await fetch(geoUrl);
-    if (!geoResponse.ok) {
-      throw new Error(`Geo API failed with status: ${geoResponse.status}`);
-    }
-    const geoData = await geoResponse.json();
-
-    if (!geoData.length) {
-      return {
-        error: `Could not find location: ${city}`
-      };
-    }
-
-    const { lat, lon } = geoData[0];
-
-    // Then get weather data
-    const weatherUrl = `https://api.openweathermap.org/data/2.5/weather?lat=${lat}&lon=${lon}&units=metric&appid=${OPENWEATHER_API_KEY}`;
+    const weatherUrl = `${PROXY_URL}/weather?city=${encodeURIComponent(city)}`;
     console.log('Fetching weather data from:', weatherUrl);
     const weatherResponse = await fetch(weatherUrl);
     if (!weatherResponse.ok) {
@@ -45,12 +29,11 @@ export async function getWeather(city) {
     const weatherData = await weatherResponse.json();
 
     return {
-      temperature: weatherData.main.temp,
-      description: weatherData.weather[0].description,
-      humidity: weatherData.main.humidity,
-      windSpeed: weatherData.wind.speed,
-      city: weatherData.name,
-      country: weatherData.sys.country
+      temperature: weatherData.temperature_c,
+      description: weatherData.condition,
+      humidity: weatherData.humidity_pct,
+      windSpeed: weatherData.wind_speed_kph,
+      city: weatherData.location
     };
   } catch (error) {
     console.error('Detailed error:', {
@@ -62,4 +45,4 @@ export async function getWeather(city) {
       error: `Error fetching weather for ${city}: ${error.message}`
     };
   }
-} 
\ No newline at end of file
+}
\ No newline at end of file
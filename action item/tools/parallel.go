@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	genai "google.golang.org/genai"
+)
+
+// DefaultWorkers adalah jumlah maksimal tool yang dijalankan bersamaan oleh
+// CallAll jika workers <= 0.
+const DefaultWorkers = 4
+
+// CallAll menjalankan semua function call dalam satu giliran model secara
+// paralel dengan paling banyak workers goroutine. Hasilnya dikembalikan dalam
+// urutan yang sama dengan calls dan setiap response membawa ID dari call-nya,
+// sehingga semuanya bisa dikirim balik ke model dalam satu giliran. Call yang
+// gagal (termasuk handler yang panic) menjadi ErrorResponse, begitu juga call
+// yang belum dimulai saat ctx berakhir.
+func (r *Registry) CallAll(ctx context.Context, calls []*genai.FunctionCall, workers int) []*genai.FunctionResponse {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	responses := make([]*genai.FunctionResponse, len(calls))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for i, call := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				responses[i] = ErrorResponse(call, ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+
+			// ctx bisa berakhir saat menunggu slot; handler tidak dimulai lagi
+			if err := ctx.Err(); err != nil {
+				responses[i] = ErrorResponse(call, err)
+				return
+			}
+			responses[i] = r.safeCall(ctx, call)
+		}()
+	}
+	wg.Wait()
+
+	return responses
+}
+
+// safeCall memanggil Call dan mengubah error maupun panic menjadi ErrorResponse.
+func (r *Registry) safeCall(ctx context.Context, call *genai.FunctionCall) (resp *genai.FunctionResponse) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("Panic saat menjalankan function %s: %v", call.Name, p)
+			resp = ErrorResponse(call, fmt.Errorf("panic: %v", p))
+		}
+	}()
+
+	resp, err := r.Call(ctx, call)
+	if err != nil {
+		log.Printf("Error calling function %s: %v", call.Name, err)
+		return ErrorResponse(call, err)
+	}
+	return resp
+}
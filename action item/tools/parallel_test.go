@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	genai "google.golang.org/genai"
+)
+
+func TestCallAllContextDone(t *testing.T) {
+	var started atomic.Int32
+	r := NewRegistry()
+	Register(r, &genai.FunctionDeclaration{Name: "tunggu"}, func(ctx context.Context, _ struct{}) (map[string]any, error) {
+		started.Add(1)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	calls := make([]*genai.FunctionCall, 2*DefaultWorkers+1)
+	for i := range calls {
+		calls[i] = &genai.FunctionCall{ID: string(rune('a' + i)), Name: "tunggu"}
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expiring, cancelExpiring := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelExpiring()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		// started adalah jumlah handler yang boleh dimulai.
+		started int32
+	}{
+		{name: "sudah dibatalkan", ctx: cancelled, started: 0},
+		// Hanya call yang mendapat slot sebelum deadline yang dimulai
+		{name: "berakhir saat menunggu slot", ctx: expiring, started: DefaultWorkers},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started.Store(0)
+			responses := r.CallAll(tt.ctx, calls, 0)
+			if got := started.Load(); got != tt.started {
+				t.Errorf("handler dimulai %d kali, ingin %d", got, tt.started)
+			}
+			for i, resp := range responses {
+				if resp == nil || resp.ID != calls[i].ID {
+					t.Fatalf("responses[%d] = %+v, ingin ID %s", i, resp, calls[i].ID)
+				}
+				if msg, _ := resp.Response["error"].(string); !strings.Contains(msg, "context") {
+					t.Errorf("responses[%d] = %v, ingin error context", i, resp.Response)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Package tools berisi registry untuk function calling Gemini.
+//
+// Setiap tool didaftarkan sekali: deklarasi, decoder argumen bertipe dan
+// handler. Daftar deklarasi untuk GenerateContentConfig.Tools maupun dispatch
+// FunctionCall diturunkan dari registry yang sama, jadi tool yang dideklarasikan
+// tanpa handler (atau sebaliknya) tidak mungkin terjadi.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	genai "google.golang.org/genai"
+)
+
+// ErrUnknownTool dikembalikan ketika model memanggil fungsi yang tidak terdaftar.
+var ErrUnknownTool = errors.New("unknown function")
+
+// Handler menjalankan sebuah tool dengan argumen yang sudah didecode ke T.
+// Nilai map yang dikembalikan dikirim ke model sebagai FunctionResponse.Response.
+type Handler[T any] func(ctx context.Context, args T) (map[string]any, error)
+
+type entry struct {
+	decl *genai.FunctionDeclaration
+	call func(ctx context.Context, args map[string]any) (map[string]any, error)
+}
+
+// Registry menyimpan semua tool yang bisa dipanggil oleh model.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*entry
+	order   []string
+}
+
+// NewRegistry membuat registry kosong.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Register mendaftarkan tool dengan deklarasi decl dan handler h. Args dari
+// model didecode ke T lewat encoding/json, jadi field T memakai tag `json`
+// yang sama dengan nama properti di decl.Parameters.
+//
+// Register panic jika decl tidak punya nama atau nama tersebut sudah terdaftar,
+// sama seperti http.HandleFunc; pendaftaran tool terjadi saat startup.
+func Register[T any](r *Registry, decl *genai.FunctionDeclaration, h Handler[T]) {
+	if decl == nil || decl.Name == "" {
+		panic("tools: declaration tanpa nama")
+	}
+	if h == nil {
+		panic("tools: handler nil untuk " + decl.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.entries[decl.Name]; exists {
+		panic("tools: tool " + decl.Name + " sudah terdaftar")
+	}
+	r.entries[decl.Name] = &entry{
+		decl: decl,
+		call: func(ctx context.Context, raw map[string]any) (map[string]any, error) {
+			args, err := Decode[T](raw)
+			if err != nil {
+				return nil, err
+			}
+			return h(ctx, args)
+		},
+	}
+	r.order = append(r.order, decl.Name)
+}
+
+// Decode mengubah Args dari FunctionCall menjadi nilai bertipe T.
+func Decode[T any](raw map[string]any) (T, error) {
+	var args T
+	if raw == nil {
+		raw = map[string]any{}
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return args, fmt.Errorf("error encode argumen: %w", err)
+	}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return args, fmt.Errorf("argumen tidak valid: %w", err)
+	}
+	return args, nil
+}
+
+// AsMap mengubah struct hasil handler menjadi map untuk
+// FunctionResponse.Response memakai tag json struct tersebut.
+func AsMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error encode hasil: %w", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("hasil harus berupa object: %w", err)
+	}
+	return out, nil
+}
+
+// Declarations mengembalikan deklarasi semua tool sesuai urutan pendaftaran.
+func (r *Registry) Declarations() []*genai.FunctionDeclaration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	decls := make([]*genai.FunctionDeclaration, 0, len(r.order))
+	for _, name := range r.order {
+		decls = append(decls, r.entries[name].decl)
+	}
+	return decls
+}
+
+// Tools membungkus Declarations untuk GenerateContentConfig.Tools.
+func (r *Registry) Tools() []*genai.Tool {
+	return []*genai.Tool{{FunctionDeclarations: r.Declarations()}}
+}
+
+// Has melaporkan apakah tool dengan nama tersebut terdaftar.
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.entries[name]
+	return ok
+}
+
+// Call memvalidasi Args terhadap schema deklarasi (lihat Validate), mendecode
+// hasilnya lalu menjalankan handler untuk call. Args yang tidak lolos schema
+// menghasilkan *ValidationError. Error dikembalikan apa adanya; gunakan
+// ErrorResponse untuk meneruskannya ke model.
+func (r *Registry) Call(ctx context.Context, call *genai.FunctionCall) (*genai.FunctionResponse, error) {
+	r.mu.RLock()
+	e, ok := r.entries[call.Name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTool, call.Name)
+	}
+
+	args, fieldErrs := Validate(e.decl.Parameters, call.Args)
+	if len(fieldErrs) > 0 {
+		return nil, &ValidationError{Function: call.Name, Fields: fieldErrs}
+	}
+
+	result, err := e.call(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return &genai.FunctionResponse{
+		ID:       call.ID,
+		Name:     call.Name,
+		Response: result,
+	}, nil
+}
+
+// ErrorResponse membuat FunctionResponse berisi error supaya model tahu
+// pemanggilan gagal dan bisa mencoba lagi atau menjelaskannya ke pengguna.
+// Untuk *ValidationError, daftar field yang salah ikut dikirim di
+// "invalid_arguments".
+func ErrorResponse(call *genai.FunctionCall, err error) *genai.FunctionResponse {
+	response := map[string]any{
+		"error": fmt.Sprintf("Failed to execute function %s: %v", call.Name, err),
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		response["invalid_arguments"] = validationErr.Fields
+	}
+	return &genai.FunctionResponse{
+		ID:       call.ID,
+		Name:     call.Name,
+		Response: response,
+	}
+}
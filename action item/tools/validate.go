@@ -0,0 +1,245 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	genai "google.golang.org/genai"
+)
+
+// FieldError menjelaskan satu argumen yang tidak sesuai dengan schema.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError dikembalikan Call ketika Args dari model tidak lolos schema
+// deklarasinya. ErrorResponse menyertakan Fields apa adanya supaya model bisa
+// memperbaiki argumen lalu mencoba lagi.
+type ValidationError struct {
+	Function string
+	Fields   []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Reason)
+	}
+	return fmt.Sprintf("argumen %s tidak valid: %s", e.Function, strings.Join(parts, "; "))
+}
+
+// Validate memeriksa args terhadap schema dan mengembalikan salinan args yang
+// sudah dikoersi: angka yang dikirim sebagai string menjadi json.Number (atau
+// sebaliknya), boolean dalam bentuk "true"/"false", dan seterusnya. Field wajib, enum, pattern serta
+// minimum/maximum juga diperiksa. Schema nil berarti tidak ada validasi.
+func Validate(schema *genai.Schema, args map[string]any) (map[string]any, []FieldError) {
+	if schema == nil {
+		return args, nil
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+
+	var errs []FieldError
+	out := validateObject("", schema, args, &errs)
+	return out, errs
+}
+
+func validateObject(path string, schema *genai.Schema, obj map[string]any, errs *[]FieldError) map[string]any {
+	out := make(map[string]any, len(obj))
+	for k, v := range obj {
+		out[k] = v
+	}
+
+	for _, name := range schema.Required {
+		if v, ok := obj[name]; !ok || v == nil || v == "" {
+			*errs = append(*errs, FieldError{Field: joinPath(path, name), Reason: "wajib diisi"})
+		}
+	}
+
+	// Urutkan supaya daftar error stabil
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v, ok := obj[name]
+		if !ok || v == nil {
+			continue
+		}
+		out[name] = validateValue(joinPath(path, name), schema.Properties[name], v, errs)
+	}
+	return out
+}
+
+func validateValue(path string, schema *genai.Schema, v any, errs *[]FieldError) any {
+	if schema == nil {
+		return v
+	}
+	fail := func(format string, a ...any) any {
+		*errs = append(*errs, FieldError{Field: path, Reason: fmt.Sprintf(format, a...)})
+		return v
+	}
+
+	switch normalizeType(schema.Type) {
+	case genai.TypeString:
+		s, ok := coerceString(v)
+		if !ok {
+			return fail("harus berupa string, diterima %T", v)
+		}
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, s) {
+			return fail("harus salah satu dari %s, diterima %q", strings.Join(schema.Enum, ", "), s)
+		}
+		if schema.Pattern != "" {
+			re, err := regexp.Compile(schema.Pattern)
+			if err == nil && !re.MatchString(s) {
+				return fail("harus cocok dengan pola %s, diterima %q", schema.Pattern, s)
+			}
+		}
+		return s
+
+	case genai.TypeNumber, genai.TypeInteger:
+		n, ok := coerceNumber(v)
+		if !ok {
+			return fail("harus berupa angka, diterima %v", v)
+		}
+		if normalizeType(schema.Type) == genai.TypeInteger {
+			if n != math.Trunc(n) {
+				return fail("harus berupa bilangan bulat, diterima %v", n)
+			}
+			// Konversi float64 di luar rentang int64 tidak terdefinisi, jadi
+			// ditolak sebelum ditulis ulang atau di-decode ke field int
+			if n < math.MinInt64 || n >= math.MaxInt64 {
+				return fail("di luar rentang bilangan bulat 64-bit, diterima %v", v)
+			}
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			return fail("minimal %v, diterima %v", *schema.Minimum, n)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			return fail("maksimal %v, diterima %v", *schema.Maximum, n)
+		}
+		// Angka dalam bentuk teks diteruskan sebagai json.Number supaya field
+		// json.Number (misal jumlah uang) menerima digit aslinya, bukan hasil
+		// pembulatan float64. Bilangan bulat yang ditulis dengan pecahan atau
+		// eksponen ("7.0", "7e0") ditulis ulang tanpa keduanya supaya bisa
+		// di-decode ke field int.
+		if text, ok := numberText(v); ok {
+			if normalizeType(schema.Type) == genai.TypeInteger && strings.ContainsAny(string(text), ".eE") {
+				return json.Number(strconv.FormatInt(int64(n), 10))
+			}
+			return text
+		}
+		return n
+
+	case genai.TypeBoolean:
+		switch b := v.(type) {
+		case bool:
+			return b
+		case string:
+			if parsed, err := strconv.ParseBool(strings.TrimSpace(b)); err == nil {
+				return parsed
+			}
+		}
+		return fail("harus berupa boolean, diterima %v", v)
+
+	case genai.TypeArray:
+		items, ok := v.([]any)
+		if !ok {
+			return fail("harus berupa array, diterima %T", v)
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			out[i] = validateValue(fmt.Sprintf("%s[%d]", path, i), schema.Items, item, errs)
+		}
+		return out
+
+	case genai.TypeObject:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fail("harus berupa object, diterima %T", v)
+		}
+		return validateObject(path, schema, obj, errs)
+	}
+	return v
+}
+
+// normalizeType menyamakan "object" dan genai.TypeObject; deklarasi lama
+// menulis tipe dengan huruf kecil.
+func normalizeType(t genai.Type) genai.Type {
+	return genai.Type(strings.ToUpper(string(t)))
+}
+
+func coerceString(v any) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(s), true
+	case json.Number:
+		return s.String(), true
+	case bool:
+		return strconv.FormatBool(s), true
+	}
+	return "", false
+}
+
+// coerceNumber menerima angka atau string angka yang berhingga. NaN dan Inf
+// ditolak karena NaN lolos semua pengecekan minimum/maximum.
+func coerceNumber(v any) (float64, bool) {
+	var f float64
+	switch n := v.(type) {
+	case float64:
+		f = n
+	case int:
+		f = float64(n)
+	case string, json.Number:
+		var err error
+		f, err = strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(n)), 64)
+		if err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// numberText mengembalikan v sebagai json.Number jika v berupa string atau
+// json.Number yang ditulis sebagai literal angka JSON, misal "12.50".
+func numberText(v any) (json.Number, bool) {
+	var text string
+	switch n := v.(type) {
+	case string:
+		text = strings.TrimSpace(n)
+	case json.Number:
+		text = strings.TrimSpace(n.String())
+	default:
+		return "", false
+	}
+	if text == "" || text[0] == '"' || !json.Valid([]byte(text)) {
+		return "", false
+	}
+	return json.Number(text), true
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	genai "google.golang.org/genai"
+)
+
+func TestValidateNumber(t *testing.T) {
+	minimum, maximum := 1.0, 30.0
+	schema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"days": {Type: genai.TypeInteger, Minimum: &minimum, Maximum: &maximum},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		value   any
+		want    any
+		wantErr bool
+	}{
+		{name: "angka", value: 7.0, want: 7.0},
+		{name: "string angka", value: " 7 ", want: json.Number("7")},
+		{name: "json.Number", value: json.Number("12"), want: json.Number("12")},
+		{name: "string dengan pecahan nol", value: "7.0", want: json.Number("7")},
+		{name: "string dengan eksponen", value: "7e0", want: json.Number("7")},
+		{name: "json.Number dengan pecahan nol", value: json.Number("12.00"), want: json.Number("12")},
+		{name: "string bukan literal JSON", value: "+7", want: 7.0},
+		{name: "di bawah minimum", value: 0.0, wantErr: true},
+		{name: "di atas maximum", value: "31", wantErr: true},
+		{name: "pecahan", value: 1.5, wantErr: true},
+		{name: "NaN", value: "NaN", wantErr: true},
+		{name: "Inf", value: "Inf", wantErr: true},
+		{name: "-Inf", value: "-Inf", wantErr: true},
+		{name: "bukan angka", value: "tujuh", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errs := Validate(schema, map[string]any{"days": tt.value})
+			if tt.wantErr {
+				if len(errs) != 1 || errs[0].Field != "days" {
+					t.Fatalf("errs = %v, ingin satu error untuk days", errs)
+				}
+				return
+			}
+			if len(errs) != 0 {
+				t.Fatalf("errs = %v", errs)
+			}
+			if out["days"] != tt.want {
+				t.Errorf("days = %v (%T), ingin %v", out["days"], out["days"], tt.want)
+			}
+			if _, err := Decode[struct {
+				Days int `json:"days"`
+			}](out); err != nil {
+				t.Errorf("Decode ke int: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateIntegerRange(t *testing.T) {
+	// Tanpa Minimum dan Maximum, hanya rentang int64 yang membatasi nilai
+	schema := &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: map[string]*genai.Schema{"count": {Type: genai.TypeInteger}},
+	}
+	for _, value := range []any{"1e30", "9.3e18", "-9.3e18", json.Number("1e19"), 1e30} {
+		_, errs := Validate(schema, map[string]any{"count": value})
+		if len(errs) != 1 || errs[0].Field != "count" {
+			t.Errorf("Validate(%v) errs = %v, ingin satu error untuk count", value, errs)
+		}
+	}
+	out, errs := Validate(schema, map[string]any{"count": "-9.2e18"})
+	if len(errs) != 0 || out["count"] != json.Number("-9200000000000000000") {
+		t.Errorf("Validate(-9.2e18) = %v, %v", out["count"], errs)
+	}
+}
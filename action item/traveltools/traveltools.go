@@ -0,0 +1,549 @@
+// Package traveltools mendaftarkan tool cuaca, tempat (pencarian dan detail),
+// geocoding dan kurs mata uang ke tools.Registry memakai providers.Set. CLI
+// agent dan proxy WebSocket sama-sama memanggil Register, jadi model melihat
+// deklarasi yang sama di kedua jalur. Handler membaca session.Session dari
+// context (jika ada) untuk state milik pengguna yang memanggil, misalnya
+// lokasinya.
+package traveltools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"proxy/providers"
+	"proxy/schema"
+	"proxy/session"
+	"proxy/tools"
+)
+
+// Nama tool yang didaftarkan oleh Register.
+const (
+	WeatherTool      = "getCurrentWeather"
+	ForecastTool     = "getWeatherForecast"
+	PlacesTool       = "getPlaceRecommendation"
+	NearbyPlacesTool = "getNearbyPlaces"
+	PlaceDetailsTool = "getPlaceDetails"
+	GeocodeTool      = "geocodeLocation"
+	ExchangeRateTool = "getExchangeRate"
+	ConvertTool      = "convertCurrency"
+)
+
+// WeatherArgs adalah argumen getCurrentWeather.
+type WeatherArgs struct {
+	Latitude  *float64 `json:"latitude" description:"Latitude lokasi dalam derajat desimal."`
+	Longitude *float64 `json:"longitude" description:"Longitude lokasi dalam derajat desimal."`
+	City      string   `json:"city" description:"Nama kota, tempat atau alamat, misal 'Sleman' atau 'Malioboro'; dipakai jika latitude/longitude tidak ada. Jika keduanya kosong dipakai lokasi pengguna."`
+}
+
+// ForecastArgs adalah argumen getWeatherForecast.
+type ForecastArgs struct {
+	Latitude  *float64 `json:"latitude" description:"Latitude lokasi dalam derajat desimal."`
+	Longitude *float64 `json:"longitude" description:"Longitude lokasi dalam derajat desimal."`
+	City      string   `json:"city" description:"Nama kota, tempat atau alamat, misal 'Sleman' atau 'Malioboro'; dipakai jika latitude/longitude tidak ada. Jika keduanya kosong dipakai lokasi pengguna."`
+	Days      *int     `json:"days" minimum:"1" maximum:"5" description:"Jumlah hari prakiraan harian termasuk hari ini, default 3. Untuk 'besok' pakai minimal 2."`
+	Hours     *int     `json:"hours" minimum:"0" maximum:"72" description:"Jumlah jam ke depan untuk prakiraan per jam, default 12. Isi 0 jika hanya butuh ringkasan harian."`
+}
+
+// Default getWeatherForecast.
+const (
+	defaultForecastDays  = 3
+	defaultForecastHours = 12
+)
+
+// PlaceArgs adalah argumen getPlaceRecommendation.
+type PlaceArgs struct {
+	Query     string   `json:"query" required:"true" description:"Apa yang dicari beserta lokasinya, misal 'warung enak di Sleman'."`
+	Near      string   `json:"near" description:"Nama tempat atau alamat titik asal, misal 'Malioboro'; menggantikan latitude/longitude dan lokasi pengguna."`
+	Latitude  *float64 `json:"latitude" description:"Latitude titik asal untuk menghitung jarak. Jika kosong dipakai lokasi pengguna."`
+	Longitude *float64 `json:"longitude" description:"Longitude titik asal untuk menghitung jarak. Jika kosong dipakai lokasi pengguna."`
+	RadiusKm  *float64 `json:"radius_km" minimum:"0" description:"Hanya kembalikan tempat dalam radius ini (km) dari titik asal."`
+	SortBy    string   `json:"sort_by" enum:"relevance,distance,rating" description:"Urutan hasil: relevance (default), distance (terdekat dulu) atau rating (tertinggi dulu)."`
+	Type      string   `json:"type" description:"Tipe tempat Google Places, misal restaurant, cafe, hotel, tourist_attraction."`
+	MinRating *float64 `json:"min_rating" minimum:"0" maximum:"5" description:"Rating Google Maps minimum."`
+	OpenNow   *bool    `json:"open_now" description:"Hanya tempat yang sedang buka."`
+	MaxPrice  string   `json:"max_price" enum:"inexpensive,moderate,expensive,very_expensive" description:"Tingkat harga tertinggi yang diterima."`
+}
+
+// NearbyArgs adalah argumen getNearbyPlaces.
+type NearbyArgs struct {
+	Type       string   `json:"type" required:"true" description:"Tipe tempat Google Places, misal restaurant, cafe, atm, hospital, tourist_attraction."`
+	Near       string   `json:"near" description:"Nama tempat atau alamat pusat pencarian, misal 'Malioboro'; menggantikan latitude/longitude dan lokasi pengguna."`
+	Latitude   *float64 `json:"latitude" description:"Latitude pusat pencarian. Jika kosong dipakai lokasi pengguna."`
+	Longitude  *float64 `json:"longitude" description:"Longitude pusat pencarian. Jika kosong dipakai lokasi pengguna."`
+	RadiusKm   *float64 `json:"radius_km" minimum:"0.1" maximum:"50" description:"Radius pencarian dalam km, default 1.5."`
+	MaxResults *int     `json:"max_results" minimum:"1" maximum:"20" description:"Jumlah hasil maksimum, default 10."`
+	MinRating  *float64 `json:"min_rating" minimum:"0" maximum:"5" description:"Rating Google Maps minimum."`
+	OpenNow    *bool    `json:"open_now" description:"Hanya tempat yang sedang buka."`
+}
+
+// Default getNearbyPlaces.
+const (
+	defaultNearbyRadiusKm   = 1.5
+	defaultNearbyMaxResults = 10
+)
+
+// PlaceDetailsArgs adalah argumen getPlaceDetails.
+type PlaceDetailsArgs struct {
+	PlaceID    string `json:"place_id" required:"true" description:"ID tempat dari field id hasil getPlaceRecommendation atau getNearbyPlaces."`
+	MaxReviews *int   `json:"max_reviews" minimum:"0" maximum:"5" description:"Jumlah ulasan teratas yang dikembalikan, default 3."`
+}
+
+// defaultMaxReviews adalah jumlah ulasan default getPlaceDetails.
+const defaultMaxReviews = 3
+
+// GeocodeArgs adalah argumen geocodeLocation.
+type GeocodeArgs struct {
+	Query     string   `json:"query" description:"Nama kota, tempat atau alamat yang dicari koordinatnya, misal 'Malioboro, Yogyakarta'."`
+	Latitude  *float64 `json:"latitude" description:"Latitude untuk reverse geocoding jika query kosong."`
+	Longitude *float64 `json:"longitude" description:"Longitude untuk reverse geocoding jika query kosong."`
+}
+
+// maxGeocodeResults adalah jumlah hasil maksimum geocodeLocation.
+const maxGeocodeResults = 5
+
+// Origin adalah titik asal jarak beserta nama tempatnya jika diketahui.
+type Origin struct {
+	providers.Coordinates
+	Name string `json:"name,omitempty"`
+}
+
+// ExchangeRateArgs adalah argumen getExchangeRate. Tanpa Date, StartDate,
+// EndDate dan Days yang dikembalikan kurs terbaru.
+type ExchangeRateArgs struct {
+	From      string `json:"from" required:"true" pattern:"^[A-Z]{3}$" description:"The currency code to convert from (ISO 4217)."`
+	To        string `json:"to" required:"true" pattern:"^[A-Z]{3}$" description:"The currency code to convert to (ISO 4217)."`
+	Date      string `json:"date" pattern:"^\\d{4}-\\d{2}-\\d{2}$" description:"Past date (YYYY-MM-DD, UTC) for a single historical rate, e.g. the same weekday last week."`
+	StartDate string `json:"start_date" pattern:"^\\d{4}-\\d{2}-\\d{2}$" description:"First date (YYYY-MM-DD) of a daily rate history with a min/max/average summary."`
+	EndDate   string `json:"end_date" pattern:"^\\d{4}-\\d{2}-\\d{2}$" description:"Last date (YYYY-MM-DD) of the history, default today. Requires start_date."`
+	Days      *int   `json:"days" minimum:"1" maximum:"365" description:"Daily rate history for the last N days including today, e.g. 7 for 'minggu lalu' or 30 for 'sebulan terakhir'; alternative to start_date/end_date."`
+}
+
+// ConvertArgs adalah argumen convertCurrency. Amount berupa json.Number
+// supaya jumlah dihitung dari digit yang dikirim model, tanpa pembulatan
+// float64.
+type ConvertArgs struct {
+	Amount json.Number `json:"amount" required:"true" minimum:"0" description:"The amount of money to convert, in the from currency, e.g. 50."`
+	From   string      `json:"from" required:"true" pattern:"^[A-Z]{3}$" description:"The currency code to convert from (ISO 4217)."`
+	To     string      `json:"to" required:"true" pattern:"^[A-Z]{3}$" description:"The currency code to convert to (ISO 4217)."`
+}
+
+// Register mendaftarkan getCurrentWeather, getWeatherForecast,
+// getPlaceRecommendation, getNearbyPlaces, getPlaceDetails, geocodeLocation,
+// getExchangeRate dan convertCurrency ke r.
+func Register(r *tools.Registry, p *providers.Set) {
+	tools.Register(r, schema.FunctionDeclaration[WeatherArgs](
+		WeatherTool,
+		"Returns the current weather base on longitude latitude and base on city.",
+	), func(ctx context.Context, args WeatherArgs) (map[string]any, error) {
+		q, name, err := weatherQuery(ctx, p.Geocoding, WeatherTool, args.Latitude, args.Longitude, args.City)
+		if err != nil {
+			return nil, err
+		}
+		weather, err := p.Weather.CurrentWeather(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			weather.Location = name
+		}
+		return tools.AsMap(weather)
+	})
+
+	tools.Register(r, schema.FunctionDeclaration[ForecastArgs](
+		ForecastTool,
+		"Returns the weather forecast for the coming days (daily min/max temperature, precipitation probability, condition, wind) "+
+			"and, if hours > 0, per-hour entries with local time. Use it for questions about later today, tomorrow or the next days, e.g. 'besok hujan gak?'.",
+	), func(ctx context.Context, args ForecastArgs) (map[string]any, error) {
+		wq, name, err := weatherQuery(ctx, p.Geocoding, ForecastTool, args.Latitude, args.Longitude, args.City)
+		if err != nil {
+			return nil, err
+		}
+		q := providers.ForecastQuery{WeatherQuery: wq, Days: defaultForecastDays, Hours: defaultForecastHours}
+		if args.Days != nil {
+			q.Days = *args.Days
+		}
+		if args.Hours != nil {
+			q.Hours = *args.Hours
+		}
+		forecast, err := p.Weather.Forecast(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			forecast.Location = name
+		}
+		return tools.AsMap(forecast)
+	})
+
+	tools.Register(r, schema.FunctionDeclaration[PlaceArgs](
+		PlacesTool,
+		"Returns the recommendation place in a location, ex: restaurant, hotel, etc. "+
+			"Each place has rating, user_rating_count, google_maps_uri and, when the origin is known, distance_km from the user; quote these values verbatim instead of estimating them.",
+	), func(ctx context.Context, args PlaceArgs) (map[string]any, error) {
+		origin, err := resolveOrigin(ctx, p.Geocoding, args.Near, args.Latitude, args.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		if args.RadiusKm != nil && origin == nil {
+			return nil, fmt.Errorf("radius_km membutuhkan lokasi asal (near, latitude/longitude atau lokasi pengguna)")
+		}
+
+		q := providers.PlaceQuery{
+			Text:        args.Query,
+			Bias:        origin.coordinates(),
+			Type:        args.Type,
+			PriceLevels: priceLevelsUpTo(args.MaxPrice),
+		}
+		if args.RadiusKm != nil {
+			q.BiasRadiusM = *args.RadiusKm * 1000
+		}
+		if args.MinRating != nil {
+			q.MinRating = *args.MinRating
+		}
+		if args.OpenNow != nil {
+			q.OpenNow = *args.OpenNow
+		}
+
+		places, err := p.Places.SearchText(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		places = rankPlaces(places, origin.coordinates(), args.RadiusKm, args.SortBy)
+
+		result := map[string]any{"places": places}
+		if origin != nil {
+			result["origin"] = origin
+		}
+		return result, nil
+	})
+
+	tools.Register(r, schema.FunctionDeclaration[NearbyArgs](
+		NearbyPlacesTool,
+		"Returns places of a given type around the user (or the given coordinates), nearest first. "+
+			"Each place has distance_km, rating, user_rating_count and google_maps_uri; quote these values verbatim.",
+	), func(ctx context.Context, args NearbyArgs) (map[string]any, error) {
+		origin, err := resolveOrigin(ctx, p.Geocoding, args.Near, args.Latitude, args.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		if origin == nil {
+			return nil, fmt.Errorf("invalid function call arguments for %s: requires near, (latitude, longitude) or the user's location", NearbyPlacesTool)
+		}
+
+		q := providers.NearbyQuery{
+			Center:         origin.Coordinates,
+			RadiusM:        defaultNearbyRadiusKm * 1000,
+			Type:           args.Type,
+			MaxResults:     defaultNearbyMaxResults,
+			RankByDistance: true,
+		}
+		if args.RadiusKm != nil {
+			q.RadiusM = *args.RadiusKm * 1000
+		}
+		if args.MaxResults != nil {
+			q.MaxResults = *args.MaxResults
+		}
+		if args.MinRating != nil {
+			q.MinRating = *args.MinRating
+		}
+		if args.OpenNow != nil {
+			q.OpenNow = *args.OpenNow
+		}
+
+		places, err := p.Places.SearchNearby(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		radiusKm := q.RadiusM / 1000
+		return map[string]any{
+			"places": rankPlaces(places, &origin.Coordinates, &radiusKm, "distance"),
+			"origin": origin,
+		}, nil
+	})
+
+	tools.Register(r, schema.FunctionDeclaration[PlaceDetailsArgs](
+		PlaceDetailsTool,
+		"Returns details of one place by its id: opening hours, phone, website, google_maps_uri and the top Google Maps reviews. "+
+			"Each review has author_name, author_photo_uri (the reviewer's profile picture), rating and text; quote them verbatim.",
+	), func(ctx context.Context, args PlaceDetailsArgs) (map[string]any, error) {
+		details, err := p.Places.Details(ctx, args.PlaceID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Salin sebelum memotong ulasan karena details bisa berasal dari cache
+		trimmed := *details
+		maxReviews := defaultMaxReviews
+		if args.MaxReviews != nil {
+			maxReviews = *args.MaxReviews
+		}
+		if len(trimmed.Reviews) > maxReviews {
+			trimmed.Reviews = trimmed.Reviews[:maxReviews]
+		}
+		return tools.AsMap(trimmed)
+	})
+
+	tools.Register(r, schema.FunctionDeclaration[GeocodeArgs](
+		GeocodeTool,
+		"Resolves a city, landmark or street address to coordinates (forward geocoding), or coordinates to a place name and address (reverse geocoding). "+
+			"Without query or coordinates it names the user's current location.",
+	), func(ctx context.Context, args GeocodeArgs) (map[string]any, error) {
+		if p.Geocoding == nil {
+			return nil, fmt.Errorf("geocoding provider tidak tersedia")
+		}
+		var results []providers.GeocodeResult
+		var err error
+		if args.Query != "" {
+			results, err = p.Geocoding.Geocode(ctx, args.Query)
+		} else if c := originFrom(ctx, args.Latitude, args.Longitude); c != nil {
+			results, err = p.Geocoding.ReverseGeocode(ctx, *c)
+		} else {
+			return nil, fmt.Errorf("invalid function call arguments for %s: requires query, (latitude, longitude) or the user's location", GeocodeTool)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(results) > maxGeocodeResults {
+			results = results[:maxGeocodeResults]
+		}
+		return map[string]any{"results": results}, nil
+	})
+
+	tools.Register(r, schema.FunctionDeclaration[ExchangeRateArgs](
+		ExchangeRateTool,
+		"Returns the exchange rate from one currency to another. Use ISO 4217 currency codes (e.g., USD, IDR, EUR). "+
+			"Without dates it returns the current rate; with date it returns the historical rate on that day; "+
+			"with days or start_date/end_date it returns daily rates plus min, max, average and change_pct so the user can judge whether to exchange now. "+
+			"Every response includes today (UTC) for resolving relative dates.",
+	), func(ctx context.Context, args ExchangeRateArgs) (map[string]any, error) {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		date, start, end, err := rateDates(args, today)
+		if err != nil {
+			return nil, err
+		}
+
+		var result any
+		switch {
+		case !start.IsZero():
+			result, err = p.FX.RateSeries(ctx, args.From, args.To, start, end)
+		case !date.IsZero():
+			result, err = p.FX.RateOn(ctx, args.From, args.To, date)
+		default:
+			result, err = p.FX.Rate(ctx, args.From, args.To)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m, err := tools.AsMap(result)
+		if err != nil {
+			return nil, err
+		}
+		m["today"] = today.Format(time.DateOnly)
+		return m, nil
+	})
+
+	tools.Register(r, schema.FunctionDeclaration[ConvertArgs](
+		ConvertTool,
+		"Converts an amount of money from one currency to another, e.g. '50 EUR berapa Rupiah?'. "+
+			"Returns converted_amount already rounded to the target currency's decimals, plus the rate, its timestamp and the provider; quote converted_amount verbatim instead of multiplying yourself.",
+	), func(ctx context.Context, args ConvertArgs) (map[string]any, error) {
+		rate, err := p.FX.Rate(ctx, args.From, args.To)
+		if err != nil {
+			return nil, err
+		}
+		conversion, err := providers.Convert(args.Amount.String(), rate)
+		if err != nil {
+			return nil, err
+		}
+		return tools.AsMap(conversion)
+	})
+}
+
+// rateDates membaca tanggal getExchangeRate relatif terhadap today (UTC, jam
+// 00.00). date diisi untuk satu kurs historis; start dan end untuk riwayat
+// harian. Semuanya nol jika yang diminta kurs terbaru.
+func rateDates(args ExchangeRateArgs, today time.Time) (date, start, end time.Time, err error) {
+	invalid := func(format string, a ...any) error {
+		return fmt.Errorf("invalid function call arguments for %s: %s", ExchangeRateTool, fmt.Sprintf(format, a...))
+	}
+	parse := func(field, value string) (time.Time, error) {
+		t, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return time.Time{}, invalid("%s %q bukan tanggal YYYY-MM-DD", field, value)
+		}
+		if t.After(today) {
+			return time.Time{}, invalid("%s %s masih di masa depan (hari ini %s)", field, value, today.Format(time.DateOnly))
+		}
+		return t, nil
+	}
+
+	isRange := args.StartDate != "" || args.EndDate != "" || args.Days != nil
+	switch {
+	case args.Date != "" && isRange:
+		return date, start, end, invalid("date tidak bisa digabung dengan start_date, end_date atau days")
+	case args.Date != "":
+		date, err = parse("date", args.Date)
+		return date, start, end, err
+	case args.Days != nil && (args.StartDate != "" || args.EndDate != ""):
+		return date, start, end, invalid("days tidak bisa digabung dengan start_date atau end_date")
+	case args.Days != nil:
+		// days termasuk hari ini, jadi days=7 menghasilkan 7 titik harian
+		return date, today.AddDate(0, 0, -(*args.Days - 1)), today, nil
+	case args.EndDate != "" && args.StartDate == "":
+		return date, start, end, invalid("end_date membutuhkan start_date")
+	case args.StartDate == "":
+		return date, start, end, nil
+	}
+
+	if start, err = parse("start_date", args.StartDate); err != nil {
+		return date, start, end, err
+	}
+	end = today
+	if args.EndDate != "" {
+		if end, err = parse("end_date", args.EndDate); err != nil {
+			return date, start, end, err
+		}
+	}
+	if end.Before(start) {
+		return date, time.Time{}, time.Time{}, invalid("end_date %s sebelum start_date %s", args.EndDate, args.StartDate)
+	}
+	return date, start, end, nil
+}
+
+// weatherQuery memilih lokasi cuaca dari argumen tool: lat/lon jika lengkap,
+// lalu city, lalu lokasi session pemanggil. City di-geocode dulu supaya nama
+// tempat atau alamat seperti "Malioboro" juga bisa dipakai; jika gagal, city
+// diteruskan apa adanya ke provider cuaca. name adalah nama lokasi hasil
+// geocoding, atau "" jika tidak ada.
+func weatherQuery(ctx context.Context, g providers.GeocodingProvider, tool string, lat, lon *float64, city string) (q providers.WeatherQuery, name string, err error) {
+	switch {
+	case lat != nil && lon != nil:
+		q.Coordinates = &providers.Coordinates{Latitude: *lat, Longitude: *lon}
+	case city != "":
+		if g == nil {
+			q.City = city
+			break
+		}
+		result, err := providers.Locate(ctx, g, city)
+		if err != nil {
+			log.Printf("Geocoding %q gagal, memakai nama kota apa adanya: %v", city, err)
+			q.City = city
+			break
+		}
+		q.Coordinates = &result.Coordinates
+		name = result.Name
+	default:
+		// Tanpa argumen lokasi, pakai lokasi session pemanggil jika ada
+		if q.Coordinates = originFrom(ctx, nil, nil); q.Coordinates == nil {
+			return q, "", fmt.Errorf("invalid function call arguments for %s: requires either (latitude, longitude) or city", tool)
+		}
+	}
+	return q, name, nil
+}
+
+// priceLevelsUpTo mengembalikan providers.PriceLevels dari yang termurah sampai
+// maxPrice (misal "moderate"), atau nil jika maxPrice kosong.
+func priceLevelsUpTo(maxPrice string) []string {
+	if maxPrice == "" {
+		return nil
+	}
+	want := "PRICE_LEVEL_" + strings.ToUpper(maxPrice)
+	for i, level := range providers.PriceLevels {
+		if level == want {
+			return providers.PriceLevels[:i+1]
+		}
+	}
+	return nil
+}
+
+// originFrom mengembalikan titik asal jarak: lat/lon dari argumen jika
+// lengkap, selain itu lokasi session pemanggil, atau nil jika tidak ada.
+func originFrom(ctx context.Context, lat, lon *float64) *providers.Coordinates {
+	if lat != nil && lon != nil {
+		return &providers.Coordinates{Latitude: *lat, Longitude: *lon}
+	}
+	if sess, ok := session.FromContext(ctx); ok && sess.HasLocation() {
+		loc := sess.Location()
+		return &loc
+	}
+	return nil
+}
+
+// resolveOrigin mengembalikan titik asal tool tempat: hasil geocoding near
+// jika diisi, lalu lat/lon, lalu lokasi session pemanggil beserta nama
+// tempatnya (di-reverse-geocode sekali per lokasi dan disimpan di session).
+// Hasil nil berarti titik asal tidak diketahui.
+func resolveOrigin(ctx context.Context, g providers.GeocodingProvider, near string, lat, lon *float64) (*Origin, error) {
+	if near != "" {
+		result, err := providers.Locate(ctx, g, near)
+		if err != nil {
+			return nil, err
+		}
+		return &Origin{Coordinates: result.Coordinates, Name: result.Name}, nil
+	}
+	if lat != nil && lon != nil {
+		return &Origin{Coordinates: providers.Coordinates{Latitude: *lat, Longitude: *lon}}, nil
+	}
+	sess, ok := session.FromContext(ctx)
+	if !ok || !sess.HasLocation() {
+		return nil, nil
+	}
+	origin := &Origin{Coordinates: sess.Location(), Name: sess.LocationName()}
+	if origin.Name == "" {
+		name, err := providers.PlaceName(ctx, g, origin.Coordinates)
+		if err != nil {
+			log.Printf("Reverse geocoding lokasi pengguna gagal: %v", err)
+		}
+		origin.Name = name
+		sess.SetLocationName(origin.Coordinates, name)
+	}
+	return origin, nil
+}
+
+// coordinates mengembalikan koordinat o, atau nil jika o nil.
+func (o *Origin) coordinates() *providers.Coordinates {
+	if o == nil {
+		return nil
+	}
+	return &o.Coordinates
+}
+
+// rankPlaces mengisi DistanceKm dari origin, membuang tempat di luar radiusKm
+// dan mengurutkan sesuai sortBy ("distance", "rating", selain itu urutan asli
+// dari provider). Tempat tanpa koordinat tidak punya jarak dan dibuang jika
+// radius dipakai.
+func rankPlaces(places []providers.Place, origin *providers.Coordinates, radiusKm *float64, sortBy string) []providers.Place {
+	ranked := make([]providers.Place, 0, len(places))
+	for _, place := range places {
+		if origin != nil && place.Location != nil {
+			d := math.Round(providers.DistanceKm(*origin, *place.Location)*100) / 100
+			place.DistanceKm = &d
+		}
+		if radiusKm != nil && (place.DistanceKm == nil || *place.DistanceKm > *radiusKm) {
+			continue
+		}
+		ranked = append(ranked, place)
+	}
+
+	switch sortBy {
+	case "distance":
+		sort.SliceStable(ranked, func(i, j int) bool {
+			a, b := ranked[i].DistanceKm, ranked[j].DistanceKm
+			if a == nil || b == nil {
+				return a != nil
+			}
+			return *a < *b
+		})
+	case "rating":
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return ranked[i].Rating > ranked[j].Rating
+		})
+	}
+	return ranked
+}
@@ -0,0 +1,75 @@
+package traveltools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"proxy/fakes"
+	"proxy/providers"
+	"proxy/tools"
+)
+
+func TestRateDatesDays(t *testing.T) {
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		days  int
+		start string
+	}{
+		{days: 1, start: "2025-03-10"},
+		{days: 7, start: "2025-03-04"},
+		{days: 30, start: "2025-02-09"},
+	}
+	for _, tt := range tests {
+		days := tt.days
+		_, start, end, err := rateDates(ExchangeRateArgs{Days: &days}, today)
+		if err != nil {
+			t.Fatalf("rateDates(days=%d): %v", tt.days, err)
+		}
+		if got := start.Format(time.DateOnly); got != tt.start || !end.Equal(today) {
+			t.Errorf("days=%d: %s sampai %s, ingin %s sampai %s", tt.days, got, end.Format(time.DateOnly), tt.start, today.Format(time.DateOnly))
+		}
+		// Jumlah titik harian termasuk start dan end
+		if points := int(end.Sub(start).Hours()/24) + 1; points != tt.days {
+			t.Errorf("days=%d menghasilkan %d titik", tt.days, points)
+		}
+	}
+}
+
+func TestConvertAmountPrecision(t *testing.T) {
+	api := fakes.NewServer()
+	defer api.Close()
+	set, err := providers.New(api.ProvidersConfig())
+	if err != nil {
+		t.Fatalf("providers.New: %v", err)
+	}
+	reg := tools.NewRegistry()
+	Register(reg, set)
+
+	const amount = "12345678901234567.89"
+	for _, value := range []any{json.Number(amount), amount} {
+		resp, err := reg.Call(context.Background(), &genai.FunctionCall{
+			Name: ConvertTool,
+			Args: map[string]any{"amount": value, "from": "USD", "to": "IDR"},
+		})
+		if err != nil {
+			t.Fatalf("Call(%T): %v", value, err)
+		}
+		// Hasil dihitung dari digit amount apa adanya; float64 membulatkannya
+		// menjadi 12345678901234568
+		rate, _ := new(big.Rat).SetString(fmt.Sprint(resp.Response["rate"]))
+		want, _ := new(big.Rat).SetString(amount)
+		want.Mul(want, rate)
+		if got := resp.Response["converted_amount"]; got != want.FloatString(providers.MinorUnits("IDR")) {
+			t.Errorf("amount %T: converted_amount = %v, ingin %s", value, got, want.FloatString(providers.MinorUnits("IDR")))
+		}
+		if got := resp.Response["amount"]; got != amount {
+			t.Errorf("amount %T: amount = %v, ingin %s", value, got, amount)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	genai "google.golang.org/genai"
+
+	"proxy/fakes"
+	"proxy/providers"
+	"proxy/tools"
+)
+
+// panicArgs dan failArgs adalah argumen tool tambahan yang hanya ada di test.
+type panicArgs struct{}
+
+type failArgs struct{}
+
+// newTestRegistry mendaftarkan tool CLI yang backend-nya diarahkan ke api,
+// ditambah tool panicTool dan failTool.
+func newTestRegistry(t *testing.T, api *fakes.Server) *tools.Registry {
+	t.Helper()
+	set, err := providers.New(api.ProvidersConfig())
+	if err != nil {
+		t.Fatalf("providers.New: %v", err)
+	}
+	registry := newToolRegistry(set, &providers.GoogleSearch{
+		APIKey:     fakes.APIKey,
+		CX:         "fake-cx",
+		BaseURL:    api.URL,
+		HTTPClient: api.Client(),
+	})
+	tools.Register(registry, &genai.FunctionDeclaration{Name: "panicTool"},
+		func(context.Context, panicArgs) (map[string]any, error) { panic("boom") })
+	tools.Register(registry, &genai.FunctionDeclaration{Name: "failTool"},
+		func(context.Context, failArgs) (map[string]any, error) { return nil, errors.New("backend mati") })
+	return registry
+}
+
+// generateRequests mengembalikan isi setiap request generateContent ke api.
+func generateRequests(t *testing.T, api *fakes.Server) [][]*genai.Content {
+	t.Helper()
+	var out [][]*genai.Content
+	for _, r := range api.Requests() {
+		if !strings.HasSuffix(r.Path, ":generateContent") {
+			continue
+		}
+		var body struct {
+			Contents []*genai.Content `json:"contents"`
+		}
+		if err := json.Unmarshal(r.Body, &body); err != nil {
+			t.Fatalf("body generateContent: %v", err)
+		}
+		out = append(out, body.Contents)
+	}
+	return out
+}
+
+// functionResponses mengembalikan FunctionResponse yang dikirim ke model,
+// dengan key nama fungsi.
+func functionResponses(contents [][]*genai.Content) map[string]map[string]any {
+	out := map[string]map[string]any{}
+	for _, req := range contents {
+		for _, c := range req {
+			for _, p := range c.Parts {
+				if p.FunctionResponse != nil {
+					out[p.FunctionResponse.Name] = p.FunctionResponse.Response
+				}
+			}
+		}
+	}
+	return out
+}
+
+func TestAnswerWithTools(t *testing.T) {
+	tests := []struct {
+		name string
+		// turns adalah response generateContent berurutan. Dua terakhir
+		// adalah jawaban teks loop agent dan jawaban ulang dengan
+		// ResponseSchema dari answer.Structured.
+		turns []*genai.GenerateContentResponse
+		// fail membuat path backend ini gagal dengan status 500.
+		fail string
+		// paths adalah path backend yang harus dipanggil tool.
+		paths []string
+		// errors adalah fungsi yang FunctionResponse-nya harus berisi error
+		// yang memuat teks ini.
+		errors map[string]string
+		// invalid adalah field yang harus ada di invalid_arguments.
+		invalid map[string]string
+		want    string
+	}{
+		{
+			name: "multi langkah",
+			turns: []*genai.GenerateContentResponse{
+				fakes.FunctionCallResponse(
+					&genai.FunctionCall{Name: "getCurrentWeather", Args: map[string]any{"city": "Sleman"}},
+					&genai.FunctionCall{Name: "getPlaceRecommendation", Args: map[string]any{"query": "warung enak di Sleman"}},
+				),
+				fakes.FunctionCallResponse(&genai.FunctionCall{Name: "convertCurrency", Args: map[string]any{"amount": 50, "from": "EUR", "to": "IDR"}}),
+				fakes.TextResponse("Cerah, coba Warung Kopi Klotok."),
+				fakes.TextResponse(`{"response": "Cerah, coba Warung Kopi Klotok."}`),
+			},
+			paths: []string{"/data/2.5/weather", "/v1/places:searchText", "/convert"},
+			want:  "Cerah, coba Warung Kopi Klotok.",
+		},
+		{
+			name: "fungsi tidak dikenal",
+			turns: []*genai.GenerateContentResponse{
+				fakes.FunctionCallResponse(&genai.FunctionCall{Name: "bookFlight", Args: map[string]any{"to": "DPS"}}),
+				fakes.TextResponse("Maaf, saya tidak bisa memesan tiket."),
+				fakes.TextResponse(`{"response": "Maaf, saya tidak bisa memesan tiket."}`),
+			},
+			errors: map[string]string{"bookFlight": tools.ErrUnknownTool.Error()},
+			want:   "Maaf, saya tidak bisa memesan tiket.",
+		},
+		{
+			name: "argumen tidak valid",
+			turns: []*genai.GenerateContentResponse{
+				fakes.FunctionCallResponse(&genai.FunctionCall{Name: "getExchangeRate", Args: map[string]any{"from": "rupiah"}}),
+				fakes.FunctionCallResponse(&genai.FunctionCall{Name: "getExchangeRate", Args: map[string]any{"from": "USD", "to": "IDR"}}),
+				fakes.TextResponse("1 USD = 16500 IDR"),
+				fakes.TextResponse(`{"response": "1 USD = 16500 IDR"}`),
+			},
+			paths:   []string{"/convert"},
+			invalid: map[string]string{"from": "pola", "to": "wajib diisi"},
+			want:    "1 USD = 16500 IDR",
+		},
+		{
+			name: "handler error dan panic",
+			turns: []*genai.GenerateContentResponse{
+				fakes.FunctionCallResponse(
+					&genai.FunctionCall{Name: "failTool"},
+					&genai.FunctionCall{Name: "panicTool"},
+					&genai.FunctionCall{Name: "getCurrentWeather", Args: map[string]any{"city": "Sleman"}},
+				),
+				fakes.TextResponse("Layanan sedang gangguan."),
+				fakes.TextResponse(`{"response": "Layanan sedang gangguan."}`),
+			},
+			fail: "/data/2.5/weather",
+			errors: map[string]string{
+				"failTool":          "backend mati",
+				"panicTool":         "panic: boom",
+				"getCurrentWeather": "500",
+			},
+			want: "Layanan sedang gangguan.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := fakes.NewServer()
+			defer api.Close()
+			api.QueueGenerateContent(tt.turns...)
+			if tt.fail != "" {
+				api.Fail(tt.fail, 500)
+			}
+			client, err := genai.NewClient(context.Background(), api.GenAIConfig())
+			if err != nil {
+				t.Fatalf("genai.NewClient: %v", err)
+			}
+
+			env, err := answerWithTools(context.Background(), client.Models, newTestRegistry(t, api), "pertanyaan")
+			if err != nil {
+				t.Fatalf("answerWithTools: %v", err)
+			}
+			if env == nil || env.Response != tt.want {
+				t.Fatalf("jawaban = %+v, ingin %q", env, tt.want)
+			}
+
+			requests := generateRequests(t, api)
+			if len(requests) != len(tt.turns) {
+				t.Errorf("generateContent dipanggil %d kali, ingin %d", len(requests), len(tt.turns))
+			}
+			// Jawaban akhir diminta tanpa tools dengan ResponseSchema
+			var final struct {
+				Tools            []any `json:"tools"`
+				GenerationConfig struct {
+					ResponseMIMEType string `json:"responseMimeType"`
+					ResponseSchema   any    `json:"responseSchema"`
+				} `json:"generationConfig"`
+			}
+			bodies := api.Requests()
+			if err := json.Unmarshal(bodies[len(bodies)-1].Body, &final); err != nil {
+				t.Fatalf("body generateContent terakhir: %v", err)
+			}
+			if final.Tools != nil || final.GenerationConfig.ResponseMIMEType != "application/json" || final.GenerationConfig.ResponseSchema == nil {
+				t.Errorf("generateContent terakhir = %+v, ingin JSON dengan ResponseSchema tanpa tools", final)
+			}
+			called := map[string]bool{}
+			for _, r := range api.Requests() {
+				called[r.Path] = true
+			}
+			for _, path := range tt.paths {
+				if !called[path] {
+					t.Errorf("backend %s tidak dipanggil", path)
+				}
+			}
+
+			responses := functionResponses(requests)
+			for name, resp := range responses {
+				msg, hasErr := resp["error"].(string)
+				want, wantErr := tt.errors[name]
+				switch {
+				case wantErr && !strings.Contains(msg, want):
+					t.Errorf("error %s = %q, ingin memuat %q", name, msg, want)
+				case !wantErr && hasErr && tt.invalid == nil:
+					t.Errorf("%s gagal: %s", name, msg)
+				}
+			}
+			for name := range tt.errors {
+				if _, ok := responses[name]; !ok {
+					t.Errorf("tidak ada FunctionResponse untuk %s", name)
+				}
+			}
+
+			if tt.invalid != nil {
+				// FunctionResponse pertama getExchangeRate berisi field yang salah
+				first := requests[1]
+				resp := first[len(first)-1].Parts[0].FunctionResponse
+				fields, _ := resp.Response["invalid_arguments"].([]any)
+				got := map[string]string{}
+				for _, f := range fields {
+					f := f.(map[string]any)
+					got[f["field"].(string)] = f["reason"].(string)
+				}
+				for field, reason := range tt.invalid {
+					if !strings.Contains(got[field], reason) {
+						t.Errorf("invalid_arguments[%s] = %q, ingin memuat %q (semua: %v)", field, got[field], reason, got)
+					}
+				}
+			}
+		})
+	}
+}
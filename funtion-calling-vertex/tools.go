@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"proxy/providers"
+	"proxy/schema"
+	"proxy/tools"
+	"proxy/traveltools"
+)
+
+// Argumen untuk tool googleSearch. Tool cuaca, tempat dan kurs didaftarkan
+// oleh traveltools.Register.
+type googleSearchArgs struct {
+	Query string `json:"query" required:"true" description:"The search query to look up on Google."`
+}
+
+// newToolRegistry mendaftarkan semua tool yang bisa dipanggil model. Menambah
+// tool baru cukup dengan satu pemanggilan tools.Register di sini.
+func newToolRegistry(set *providers.Set, search *providers.GoogleSearch) *tools.Registry {
+	registry := tools.NewRegistry()
+	traveltools.Register(registry, set)
+
+	tools.Register(registry, schema.FunctionDeclaration[googleSearchArgs](
+		"googleSearch",
+		"Search for information on the web using Google Search API.",
+	), func(ctx context.Context, args googleSearchArgs) (map[string]any, error) {
+		results, err := search.Search(ctx, args.Query)
+		if err != nil {
+			return nil, err
+		}
+		return tools.AsMap(results)
+	})
+
+	return registry
+}
+
+// newGoogleSearch membaca GOOGLE_SEARCH_API_KEY, GOOGLE_SEARCH_CX dan
+// GOOGLE_SEARCH_BASE_URL (opsional) dari environment.
+func newGoogleSearch() *providers.GoogleSearch {
+	s := &providers.GoogleSearch{
+		APIKey:  os.Getenv("GOOGLE_SEARCH_API_KEY"),
+		CX:      os.Getenv("GOOGLE_SEARCH_CX"),
+		BaseURL: os.Getenv("GOOGLE_SEARCH_BASE_URL"),
+	}
+	if s.APIKey == "" {
+		log.Println("WARNING: GOOGLE_SEARCH_API_KEY tidak ditemukan di environment variables")
+	}
+	if s.CX == "" {
+		log.Println("WARNING: GOOGLE_SEARCH_CX tidak ditemukan di environment variables")
+	}
+	return s
+}